@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/spf13/cobra"
+)
+
+// chainCmd represents the 'chain' command
+var chainCmd = &cobra.Command{
+	Use:   "chain",
+	Short: "Manage the Weave blockchain",
+	Long:  `Manage the Weave blockchain such as exporting it to or importing it from an archive.`,
+	// Run: func(cmd *cobra.Command, args []string) {},
+}
+
+// chain_exportCmd represents the 'chain export' command
+var chain_exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the blockchain to an archive file",
+	Long: `Export the blocks of the blockchain to an archive file in height order.
+Command expects the path of the archive file. The chain must not be pruned.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check if args has elements
+		if len(args) == 0 {
+			fmt.Println("[error] archive file path not provided.")
+			return
+		}
+
+		// Check if the database exists
+		if !persistence.CheckDatabase() {
+			fmt.Println("[error] database does not exist.")
+			return
+		}
+
+		// Create the archive file
+		file, err := os.Create(args[0])
+		if err != nil {
+			fmt.Printf("[error] failed to create archive file. %v\n", err)
+			return
+		}
+		defer file.Close()
+
+		// Open the blockchain
+		chain := core.NewBlockChain()
+		defer chain.CloseBuckets()
+
+		// Export the blockchain to the archive
+		count, err := chain.ExportChain(file)
+		if err != nil {
+			fmt.Printf("[error] failed to export chain. %v\n", err)
+			return
+		}
+
+		fmt.Printf("exported %v blocks to %v\n", count, args[0])
+	},
+}
+
+// chain_importCmd represents the 'chain import' command
+var chain_importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the blockchain from an archive file",
+	Long: `Import the blocks of the blockchain from an archive file.
+Command expects the path of the archive file. Every block is validated
+and the utxo set is rebuilt as the blocks are connected. A new database
+is created from the genesis block of the archive, while an existing
+database must have the same genesis block and is extended.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check if args has elements
+		if len(args) == 0 {
+			fmt.Println("[error] archive file path not provided.")
+			return
+		}
+
+		// Open the archive file
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("[error] failed to open archive file. %v\n", err)
+			return
+		}
+		defer file.Close()
+
+		// Create an archive reader for the file
+		archive, err := core.NewArchiveReader(file)
+		if err != nil {
+			fmt.Printf("[error] failed to read archive. %v\n", err)
+			return
+		}
+
+		// Declare the blockchain
+		var chain *core.BlockChain
+
+		// Check if the database exists
+		if persistence.CheckDatabase() {
+			// Open the existing blockchain
+			chain = core.NewBlockChain()
+		} else {
+			// Read the genesis block from the archive
+			genesis, err := archive.Next()
+			if err != nil {
+				fmt.Printf("[error] failed to read genesis block from archive. %v\n", err)
+				return
+			}
+
+			// Create the blockchain with the genesis block
+			chain = core.NewBlockChainFromGenesis(genesis)
+		}
+
+		// Defer the closing of the blockchain
+		defer chain.CloseBuckets()
+
+		// Import the blocks from the archive
+		count, err := chain.ImportChain(archive)
+		if err != nil {
+			fmt.Printf("[error] failed to import chain after %v blocks. %v\n", count, err)
+			return
+		}
+
+		fmt.Printf("imported %v blocks. chain height is %v\n", count, chain.ChainHeight)
+	},
+}
+
+// chain_snapshotCmd represents the 'chain snapshot' command
+var chain_snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Create a utxo snapshot of the blockchain",
+	Long: `Create a snapshot of the utxo set of the blockchain at the chain head.
+Command expects the path of the snapshot file. The hash of the snapshot
+is printed and should be shared with the snapshot to verify it on load.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check if args has elements
+		if len(args) == 0 {
+			fmt.Println("[error] snapshot file path not provided.")
+			return
+		}
+
+		// Check if the database exists
+		if !persistence.CheckDatabase() {
+			fmt.Println("[error] database does not exist.")
+			return
+		}
+
+		// Open the blockchain
+		chain := core.NewBlockChain()
+		defer chain.CloseBuckets()
+
+		// Create the snapshot of the blockchain
+		snapshot, err := chain.CreateSnapshot()
+		if err != nil {
+			fmt.Printf("[error] failed to create snapshot. %v\n", err)
+			return
+		}
+
+		// Create the snapshot file
+		file, err := os.Create(args[0])
+		if err != nil {
+			fmt.Printf("[error] failed to create snapshot file. %v\n", err)
+			return
+		}
+		defer file.Close()
+
+		// Write the snapshot to the file
+		if err := snapshot.Write(file); err != nil {
+			fmt.Printf("[error] failed to write snapshot. %v\n", err)
+			return
+		}
+
+		fmt.Printf("created snapshot at height %v with %v utxo entries.\n", snapshot.BlockHeight, len(snapshot.UTXOS))
+		fmt.Printf("snapshot hash: %x\n", snapshot.Hash)
+	},
+}
+
+// chain_loadCmd represents the 'chain load' command
+var chain_loadCmd = &cobra.Command{
+	Use:   "load",
+	Short: "Start the blockchain from a utxo snapshot",
+	Long: `Start a new blockchain from a utxo snapshot file.
+Command expects the path of the snapshot file and the expected hash of the
+snapshot. The headers of the snapshot are verified and blocks after the
+snapshot are validated forward when imported. The database must not exist.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check if args has elements
+		if len(args) < 2 {
+			fmt.Println("[error] snapshot file path and hash not provided.")
+			return
+		}
+
+		// Decode the expected snapshot hash
+		expected, err := hex.DecodeString(args[1])
+		if err != nil {
+			fmt.Println("[error] invalid snapshot hash.")
+			return
+		}
+
+		// Check that the database does not exist
+		if persistence.CheckDatabase() {
+			fmt.Println("[error] database already exists. purge it to load a snapshot.")
+			return
+		}
+
+		// Open the snapshot file
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("[error] failed to open snapshot file. %v\n", err)
+			return
+		}
+		defer file.Close()
+
+		// Read the snapshot from the file
+		snapshot, err := core.ReadSnapshot(file)
+		if err != nil {
+			fmt.Printf("[error] %v\n", err)
+			return
+		}
+
+		// Check that the snapshot has the expected hash
+		if !bytes.Equal(snapshot.Hash, expected) {
+			fmt.Printf("[error] snapshot hash %x does not match the expected hash.\n", snapshot.Hash)
+			return
+		}
+
+		// Create the blockchain from the snapshot
+		chain := core.NewBlockChainFromSnapshot(snapshot)
+		defer chain.CloseBuckets()
+
+		fmt.Printf("loaded snapshot at height %v. chain height is %v\n", snapshot.BlockHeight, chain.ChainHeight)
+	},
+}
+
+func init() {
+	// Add chain command to root
+	rootCmd.AddCommand(chainCmd)
+	// Add export command to chain
+	chainCmd.AddCommand(chain_exportCmd)
+	// Add import command to chain
+	chainCmd.AddCommand(chain_importCmd)
+	// Add snapshot command to chain
+	chainCmd.AddCommand(chain_snapshotCmd)
+	// Add load command to chain
+	chainCmd.AddCommand(chain_loadCmd)
+}
@@ -27,7 +27,7 @@ var config_showCmd = &cobra.Command{
 	Short: "Show values from the configuration file",
 	Long: `Show values from the configuration file. 
 Commands expects a value that represents the config type. 
-Valid values are 'all', 'jbok', 'db', 'blocks' and 'state'.`,
+Valid values are 'all', 'jbok', 'db', 'blocks', 'state', 'headers', 'index', 'pool', 'miner', 'net' and 'light'.`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		// Read the configuration file into an object
@@ -62,6 +62,13 @@ Valid values are 'all', 'jbok', 'db', 'blocks' and 'state'.`,
 			fmt.Printf("DB State Directory: %v\n", config.DB.State.Directory)
 			fmt.Printf("DB Blocks File: %v\n", config.DB.Blocks.File)
 			fmt.Printf("DB Blocks Directory: %v\n", config.DB.Blocks.Directory)
+			fmt.Printf("DB Headers File: %v\n", config.DB.Headers.File)
+			fmt.Printf("DB Headers Directory: %v\n", config.DB.Headers.Directory)
+			fmt.Printf("DB Index File: %v\n", config.DB.Index.File)
+			fmt.Printf("DB Index Directory: %v\n", config.DB.Index.Directory)
+			fmt.Printf("DB Prune Depth: %v\n", config.DB.PruneDepth)
+			fmt.Printf("DB GC Ratio: %v\n", config.DB.GCRatio)
+			fmt.Printf("DB GC Interval: %v minutes\n", config.DB.GCInterval)
 			fmt.Println()
 
 		case "blocks":
@@ -70,6 +77,7 @@ Valid values are 'all', 'jbok', 'db', 'blocks' and 'state'.`,
 			fmt.Println("----Database-Blocks-Configuration----")
 			fmt.Printf("DB Blocks File: %v\n", config.DB.Blocks.File)
 			fmt.Printf("DB Blocks Directory: %v\n", config.DB.Blocks.Directory)
+			fmt.Printf("DB Prune Depth: %v\n", config.DB.PruneDepth)
 			fmt.Println()
 
 		case "state":
@@ -80,11 +88,54 @@ Valid values are 'all', 'jbok', 'db', 'blocks' and 'state'.`,
 			fmt.Printf("DB State Directory: %v\n", config.DB.State.Directory)
 			fmt.Println()
 
+		case "headers":
+			// Print the Database Headers configuration file values
+			fmt.Println()
+			fmt.Println("----Database-Headers-Configuration----")
+			fmt.Printf("DB Headers File: %v\n", config.DB.Headers.File)
+			fmt.Printf("DB Headers Directory: %v\n", config.DB.Headers.Directory)
+			fmt.Println()
+
+		case "index":
+			// Print the Database Index configuration file values
+			fmt.Println()
+			fmt.Println("----Database-Index-Configuration----")
+			fmt.Printf("DB Index File: %v\n", config.DB.Index.File)
+			fmt.Printf("DB Index Directory: %v\n", config.DB.Index.Directory)
+			fmt.Println()
+
+		case "pool":
+			// Print the Transaction Pool configuration file values
+			fmt.Println()
+			fmt.Println("----Pool-Configuration----")
+			fmt.Printf("Pool File: %v\n", config.Pool.File)
+			fmt.Printf("Pool Size: %v\n", config.Pool.Size)
+			fmt.Printf("Pool Save Interval: %v minutes\n", config.Pool.SaveInterval)
+			fmt.Println()
+
+		case "miner":
+			// Print the Miner configuration file values
+			fmt.Println()
+			fmt.Println("----Miner-Configuration----")
+			fmt.Printf("Miner Pool Size: %v\n", config.Miner.PoolSize)
+			fmt.Printf("Miner Block Size: %v bytes\n", config.Miner.BlockSize)
+			fmt.Printf("Miner Work Address: %v\n", config.Miner.WorkAddress)
+			fmt.Println()
+
 		case "net":
 			// Print the Network configuration file values
 			fmt.Println()
-			// fmt.Println("----Network-Configuration----")
-			// fmt.Println()
+			fmt.Println("----Network-Configuration----")
+			fmt.Printf("Network Listen Address: %v\n", config.Network.ListenAddr)
+			fmt.Println()
+
+		case "light":
+			// Print the Light node configuration file values
+			fmt.Println()
+			fmt.Println("----Light-Configuration----")
+			fmt.Printf("Light Peers: %v\n", config.Light.Peers)
+			fmt.Printf("Light Sync Interval: %v seconds\n", config.Light.SyncInterval)
+			fmt.Println()
 
 		default:
 			fmt.Println("[error] invalid config value provided.")
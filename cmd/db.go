@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/spf13/cobra"
+)
+
+// dbCmd represents the 'db' command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Maintain the Weave database",
+	Long:  `Maintain the Weave database buckets such as viewing their disk usage or compacting them.`,
+	// Run: func(cmd *cobra.Command, args []string) {},
+}
+
+// db_statsCmd represents the 'db stats' command
+var db_statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the disk usage of the database buckets",
+	Long:  `Show the disk usage of the LSM tree and value log of each database bucket`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Open the database buckets
+		buckets := openbuckets()
+		if buckets == nil {
+			return
+		}
+
+		for _, bucket := range buckets {
+			// Print the stats of the bucket
+			printstats(bucket.Stats())
+			// Close the bucket
+			bucket.Close()
+		}
+	},
+}
+
+// db_compactCmd represents the 'db compact' command
+var db_compactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Compact the database buckets",
+	Long: `Compact the database buckets by flattening their LSM trees and
+garbage collecting their value logs with the configured GC ratio.
+The node must not be running while the database is compacted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the configuration file into an object
+		config := utils.ReadConfigFile()
+		// Determine the GC ratio
+		ratio := config.DB.GCRatio
+		if ratio <= 0 || ratio >= 1 {
+			ratio = persistence.DefaultGCRatio
+		}
+
+		// Open the database buckets
+		buckets := openbuckets()
+		if buckets == nil {
+			return
+		}
+
+		for _, bucket := range buckets {
+			// Print the stats of the bucket before the compaction
+			fmt.Printf("compacting %v bucket...\n", bucket.Bucket)
+			printstats(bucket.Stats())
+
+			// Compact the bucket
+			rewrites, err := bucket.Compact(ratio)
+			if err != nil {
+				fmt.Printf("[error] failed to compact %v bucket. %v\n", bucket.Bucket, err)
+			} else {
+				// Print the stats of the bucket after the compaction
+				fmt.Printf("rewrote %v value log files.\n", rewrites)
+				printstats(bucket.Stats())
+			}
+
+			// Close the bucket
+			bucket.Close()
+		}
+	},
+}
+
+// db_verifyCmd represents the 'db verify' command
+var db_verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the integrity of the database",
+	Long: `Verify the integrity of the database by checking the hashes, proof of work,
+linkage and heights of all blocks and comparing the stored chain state, header
+history, utxo set and filters against the state recomputed from the blocks.
+With the --repair flag, the derived state is rebuilt from the blocks.
+The node must not be running while the database is verified.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get the repair flag
+		repair, _ := cmd.Flags().GetBool("repair")
+
+		// Verify the blockchain
+		report, err := core.VerifyBlockChain(repair)
+		if err != nil {
+			fmt.Printf("[error] %v\n", err)
+			return
+		}
+
+		// Print the report
+		fmt.Println()
+		fmt.Println("----Database-Integrity----")
+		fmt.Printf("Chain Head: %x\n", report.ChainHead)
+		fmt.Printf("Chain Height: %v\n", report.ChainHeight)
+		fmt.Printf("Blocks Verified: %v\n", report.Blocks)
+		fmt.Printf("Repaired: %v\n", report.Repaired)
+		for _, problem := range report.Problems {
+			fmt.Printf("[problem] %v\n", problem)
+		}
+		fmt.Println()
+
+		// Print the outcome of the verification
+		switch {
+		case report.OK():
+			fmt.Println("database is intact.")
+		case report.Recoverable:
+			fmt.Println("database has problems. run 'weave db verify --repair' to rebuild the chain state.")
+		default:
+			fmt.Println("database has problems that cannot be repaired from its blocks.")
+		}
+	},
+}
+
+// A function that opens the state, blocks and index database buckets.
+// Returns nil if the database does not exist.
+func openbuckets() []*persistence.DatabaseBucket {
+	// Check if the database exists
+	if !persistence.CheckDatabase() {
+		fmt.Println("[error] database does not exist.")
+		return nil
+	}
+
+	// Open the database buckets
+	return []*persistence.DatabaseBucket{
+		persistence.NewDatabaseBucket(persistence.STATE),
+		persistence.NewDatabaseBucket(persistence.BLOCKS),
+		persistence.NewDatabaseBucket(persistence.INDEX),
+	}
+}
+
+// A function that prints the disk usage stats of a database bucket
+func printstats(stats persistence.BucketStats) {
+	fmt.Println()
+	fmt.Printf("----%v-Bucket----\n", stats.Bucket)
+	fmt.Printf("LSM Size: %v bytes\n", stats.LSMSize)
+	fmt.Printf("Value Log Size: %v bytes\n", stats.VlogSize)
+	fmt.Println()
+}
+
+func init() {
+	// Add db command to root
+	rootCmd.AddCommand(dbCmd)
+	// Add stats command to db
+	dbCmd.AddCommand(db_statsCmd)
+	// Add compact command to db
+	dbCmd.AddCommand(db_compactCmd)
+	// Add verify command to db
+	dbCmd.AddCommand(db_verifyCmd)
+
+	// Add the repair flag to the verify command
+	db_verifyCmd.Flags().Bool("repair", false, "rebuild the derived chain state from the blocks")
+}
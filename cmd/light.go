@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/network"
+	"github.com/manishmeganathan/weave/node"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/spf13/cobra"
+)
+
+// lightCmd represents the 'light' command
+var lightCmd = &cobra.Command{
+	Use:   "light",
+	Short: "Run a Weave light node on the network",
+	Long: `Run a Weave light node on the network until the process is interrupted.
+A light node stores only the headers of the chain in the headers database. The
+headers are synced from the configured light peers (and the peers of the weave
+service) at the configured sync interval and their proof of work and linkage is
+validated. Light nodes do not serve the weave protocol to other peers.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the configuration file into an object
+		config := utils.ReadConfigFile()
+
+		// Open the header chain
+		headers := core.NewHeaderChain()
+
+		// Create the light client
+		client, err := lightclient(config, headers, time.Duration(config.Light.SyncInterval)*time.Second)
+		if err != nil {
+			fmt.Printf("[error] failed to start light client. %v\n", err)
+			headers.Close()
+			return
+		}
+
+		// Create the node and register the services in order
+		weavenode := node.NewNode()
+		weavenode.Register(node.NewService("headers", nil, func(ctx context.Context) error {
+			// Close the headers database bucket
+			headers.Close()
+			return nil
+		}))
+		weavenode.Register(client)
+
+		// Run the node until the process is interrupted
+		ctx, cancel := node.SignalContext(context.Background())
+		defer cancel()
+
+		fmt.Println("light node at header height", headers.ChainHeight, "as", client.Host.Host.ID())
+		if err := weavenode.Run(ctx, node.DefaultStopTimeout); err != nil {
+			fmt.Printf("[error] light node failed. %v\n", err)
+			return
+		}
+	},
+}
+
+// light_proveCmd represents the 'light prove' command
+var light_proveCmd = &cobra.Command{
+	Use:   "prove",
+	Short: "Verify that a transaction is on the chain",
+	Long: `Verify that a transaction is on the chain with a proof from a full node.
+Command expects the hex encoded ID of a transaction. The headers of the chain are
+synced from the configured light peers and the merkle proof of the transaction is
+requested from them and verified against the header of its block.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check if args has elements
+		if len(args) == 0 {
+			fmt.Println("[error] transaction ID not provided.")
+			return
+		}
+
+		// Decode the transaction ID
+		txid, err := hex.DecodeString(args[0])
+		if err != nil {
+			fmt.Println("[error] invalid transaction ID.")
+			return
+		}
+
+		// Read the configuration file into an object
+		config := utils.ReadConfigFile()
+
+		// Open the header chain
+		headers := core.NewHeaderChain()
+		defer headers.Close()
+
+		// Create the light client without periodic syncs
+		client, err := lightclient(config, headers, 0)
+		if err != nil {
+			fmt.Printf("[error] failed to start light client. %v\n", err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		// Start the light client and connect to its peers
+		if err := client.Start(ctx); err != nil {
+			fmt.Printf("[error] failed to start light client. %v\n", err)
+			return
+		}
+		defer client.Stop(context.Background())
+
+		// Sync the headers from the peers
+		if _, err := client.Sync(ctx); err != nil {
+			fmt.Printf("[error] failed to sync headers. %v\n", err)
+			return
+		}
+
+		// Request and verify the proof of the transaction from the peers
+		for _, peerid := range client.Peers() {
+			_, blockhash, err := client.ProveTxn(ctx, peerid, txid)
+			if err != nil {
+				fmt.Printf("[error] peer %v did not prove the transaction. %v\n", peerid, err)
+				continue
+			}
+
+			fmt.Printf("transaction %x is included in block %x\n", txid, blockhash)
+			return
+		}
+
+		fmt.Println("[error] transaction could not be verified.")
+	},
+}
+
+// A function that returns a light client for the header chain from the config file
+func lightclient(config *utils.Config, headers *core.HeaderChain, interval time.Duration) (*network.LightClient, error) {
+	// Parse the addresses of the light peers
+	peers := make([]peer.AddrInfo, 0, len(config.Light.Peers))
+	for _, addr := range config.Light.Peers {
+		peerinfo, err := peer.AddrInfoFromString(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid light peer address %v. %v", addr, err)
+		}
+
+		peers = append(peers, *peerinfo)
+	}
+
+	// Light nodes are not pruned and do not advertise the pruned service
+	hostconfig := hostconfig(config)
+	hostconfig.Pruned = false
+
+	return network.NewLightClient(headers, hostconfig, peers, interval)
+}
+
+func init() {
+	// Add light command to root
+	rootCmd.AddCommand(lightCmd)
+	// Add prove command to light
+	lightCmd.AddCommand(light_proveCmd)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/miner"
+	"github.com/manishmeganathan/weave/network"
+	"github.com/manishmeganathan/weave/node"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/manishmeganathan/weave/wallet"
+	"github.com/manishmeganathan/weave/wire"
+	"github.com/spf13/cobra"
+)
+
+// mineCmd represents the 'mine' command
+var mineCmd = &cobra.Command{
+	Use:   "mine",
+	Short: "Mine blocks on the Weave blockchain",
+	Long: `Mine blocks on the Weave blockchain until the process is interrupted.
+Blocks are built from the transactions of the pool and their rewards are paid
+to the default wallet address. Mining restarts whenever the chain head changes.
+The pool is loaded from and saved to the configured pool file. Work is also served
+to external workers ('weave mine worker') on the configured miner work address.
+
+A network host is started on the configured listen address, so that mined blocks are
+broadcast to peers and the blocks and transactions of peers reach the chain and pool.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the configuration file into an object
+		config := utils.ReadConfigFile()
+
+		// Check if a default wallet address has been set
+		if config.JBOK.Default == "" {
+			fmt.Println("[error] default wallet address not set. use 'weave wallet set'.")
+			return
+		}
+
+		// Create the coinbase address from the default wallet address
+		coinbase, err := wallet.NewAddress(config.JBOK.Default)
+		if err != nil {
+			fmt.Printf("[error] invalid default wallet address. %v\n", err)
+			return
+		}
+
+		// Check if only external workers should mine and that work can be served to them
+		external, _ := cmd.Flags().GetBool("external")
+		if external && config.Miner.WorkAddress == "" {
+			fmt.Println("[error] miner work address not set in the config.")
+			return
+		}
+
+		// Open the blockchain
+		chain := core.NewBlockChain()
+
+		// Create the transaction pool
+		poolsize := config.Pool.Size
+		if poolsize == 0 {
+			poolsize = core.DefaultTxPoolSize
+		}
+		txpool := core.NewTxPool(chain, poolsize)
+
+		// Create the block template options
+		options := core.DefaultTemplateOptions()
+		if config.Miner.PoolSize > 0 {
+			options.MaxTxns = config.Miner.PoolSize
+		}
+		if config.Miner.BlockSize > 0 {
+			options.MaxSize = config.Miner.BlockSize
+		}
+
+		// Create the miner
+		blockminer := miner.NewMiner(chain, txpool, *coinbase, options)
+
+		// Create the network host, which shares the lock on the chain with the miner
+		host, err := network.NewNodeHost(wire.NewHandler(chain, txpool, blockminer.Locker()), hostconfig(config))
+		if err != nil {
+			fmt.Printf("[error] failed to start network host. %v\n", err)
+			chain.CloseBuckets()
+			return
+		}
+
+		// Broadcast the mined blocks to peers
+		blockminer.Broadcast = host.BroadcastBlock
+
+		// Create the node and register the services in order
+		weavenode := node.NewNode()
+		weavenode.Register(node.NewChainService(chain))
+		weavenode.Register(node.NewTxPoolService(txpool, config.Pool.File, time.Duration(config.Pool.SaveInterval)*time.Minute))
+		weavenode.Register(host)
+		// Serve work to external miners if a work address is set
+		if config.Miner.WorkAddress != "" {
+			weavenode.Register(miner.NewWorkService(blockminer, config.Miner.WorkAddress))
+		}
+		// Mine locally unless only external miners should mine
+		if !external {
+			weavenode.Register(blockminer)
+		}
+
+		// Run the node until the process is interrupted
+		ctx, cancel := node.SignalContext(context.Background())
+		defer cancel()
+
+		fmt.Println("mining to", config.JBOK.Default)
+		fmt.Println("node listening on", host.Host.Addrs(), "as", host.Host.ID())
+		if err := weavenode.Run(ctx, node.DefaultStopTimeout); err != nil {
+			fmt.Printf("[error] node failed. %v\n", err)
+			return
+		}
+	},
+}
+
+// mine_workerCmd represents the 'mine worker' command
+var mine_workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Mine for a running weave miner as an external worker",
+	Long: `Mine for a running weave miner as an external worker until the process is interrupted.
+Work units are fetched from the work address of the miner and solved nonces are
+submitted back to it. Command accepts the work address of the miner and defaults
+to the work address in the config. Several workers can mine for one miner.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the configuration file into an object
+		config := utils.ReadConfigFile()
+
+		// Retrieve the work address from the first argument or the config
+		address := config.Miner.WorkAddress
+		if len(args) > 0 {
+			address = args[0]
+		}
+
+		// Check if a work address is available
+		if address == "" {
+			fmt.Println("[error] work address not provided.")
+			return
+		}
+
+		// Mine until the process is interrupted
+		ctx, cancel := node.SignalContext(context.Background())
+		defer cancel()
+
+		fmt.Println("mining for", address)
+		miner.NewWorkClient(address).Mine(ctx)
+	},
+}
+
+func init() {
+	// Add mine command to root
+	rootCmd.AddCommand(mineCmd)
+	// Add worker command to mine
+	mineCmd.AddCommand(mine_workerCmd)
+
+	// Add the external flag to the mine command
+	mineCmd.Flags().Bool("external", false, "only serve work to external workers without mining locally")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/network"
+	"github.com/manishmeganathan/weave/node"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/manishmeganathan/weave/wire"
+	"github.com/spf13/cobra"
+)
+
+// nodeCmd represents the 'node' command
+var nodeCmd = &cobra.Command{
+	Use:   "node",
+	Short: "Run a Weave node on the network",
+	Long: `Run a Weave node on the network until the process is interrupted.
+The node starts a network host on the configured listen address and connects
+to the peers of the weave service. Blocks and transactions from peers are
+validated and added to the chain and the pool, and the queries of peers are
+answered from them. The pool is loaded from and saved to the configured pool file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the configuration file into an object
+		config := utils.ReadConfigFile()
+
+		// Open the blockchain
+		chain := core.NewBlockChain()
+
+		// Create the transaction pool
+		poolsize := config.Pool.Size
+		if poolsize == 0 {
+			poolsize = core.DefaultTxPoolSize
+		}
+		txpool := core.NewTxPool(chain, poolsize)
+
+		// Create the network host
+		host, err := network.NewNodeHost(wire.NewHandler(chain, txpool, nil), hostconfig(config))
+		if err != nil {
+			fmt.Printf("[error] failed to start network host. %v\n", err)
+			chain.CloseBuckets()
+			return
+		}
+
+		// Create the node and register the services in order
+		weavenode := node.NewNode()
+		weavenode.Register(node.NewChainService(chain))
+		weavenode.Register(node.NewTxPoolService(txpool, config.Pool.File, time.Duration(config.Pool.SaveInterval)*time.Minute))
+		weavenode.Register(host)
+
+		// Run the node until the process is interrupted
+		ctx, cancel := node.SignalContext(context.Background())
+		defer cancel()
+
+		fmt.Println("node listening on", host.Host.Addrs(), "as", host.Host.ID())
+		if err := weavenode.Run(ctx, node.DefaultStopTimeout); err != nil {
+			fmt.Printf("[error] node failed. %v\n", err)
+			return
+		}
+	},
+}
+
+// A function that returns the configuration of the network host from the config file
+func hostconfig(config *utils.Config) network.HostConfig {
+	hostconfig := network.DefaultHostConfig()
+	if config.Network.ListenAddr != "" {
+		hostconfig.ListenAddr = config.Network.ListenAddr
+	}
+
+	// Advertise the pruned service if the node prunes its block bodies
+	hostconfig.Pruned = config.DB.PruneDepth > 0
+
+	return hostconfig
+}
+
+func init() {
+	// Add node command to root
+	rootCmd.AddCommand(nodeCmd)
+}
@@ -41,17 +41,30 @@ func NewPOW() *POW {
 	return &pow
 }
 
+// A constructor function that generates and return a POW
+// with its target value set for a given work difficulty.
+func NewDifficultyPOW(difficulty uint8) *POW {
+	// Create a new POW with the difficulty target and return it
+	return &POW{Nonce: 0, Target: DifficultyTarget(difficulty)}
+}
+
 // A method of POW that generates the target value
 // for the POW algorithm to mint the block.
 func (pow *POW) GenerateTarget() {
+	// Assign the target for the work difficulty to the POW
+	pow.Target = DifficultyTarget(WorkDifficulty)
+}
+
+// A function that returns the target value of the POW algorithm for a given work difficulty
+func DifficultyTarget(difficulty uint8) *big.Int {
 	// Generate new big integer with value 1
 	target := big.NewInt(1)
 	// Left Shift the big integer by the difference between the max hash
 	// size and the block's work difficulty. target = 2^(256-difficulty)
-	target.Lsh(target, 256-uint(WorkDifficulty))
+	target.Lsh(target, 256-uint(difficulty))
 
-	// Assign the target to the POW
-	pow.Target = target
+	// Return the target
+	return target
 }
 
 // A method of POW that runs the Proof Of Work Algorithm
@@ -109,3 +122,27 @@ func (pow *POW) Validate(blockheader utils.GobEncodable) bool {
 	// less than the proof target, the block signature is valid.
 	return inthash.Cmp(pow.Target) == -1
 }
+
+// A method of POW that runs the Proof Of Work Algorithm over a range of nonces without
+// printing. Returns the hash and true if a nonce in the range satisfies the target, with
+// the nonce set on the POW. Used by miners that check for new work between ranges.
+func (pow *POW) MintRange(blockheader utils.GobEncodable, start, count int) (utils.Hash, bool) {
+	// Declare a big Int version of the hash
+	var inthash big.Int
+
+	// Iterate over the nonces of the range
+	for pow.Nonce = start; pow.Nonce-start < count && pow.Nonce >= 0; pow.Nonce++ {
+		// Compose the block data and generate its hash256
+		hash := utils.Hash256(blockheader.Serialize())
+		// Set the inthash with the hash
+		inthash.SetBytes(hash)
+
+		// Check if the inthash is lesser than the proof target
+		if inthash.Cmp(pow.Target) == -1 {
+			return hash, true
+		}
+	}
+
+	// Return a nil hash if no nonce was found
+	return nil, false
+}
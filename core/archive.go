@@ -0,0 +1,215 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Represents the magic bytes at the start of a chain archive
+var ArchiveMagic = []byte("WEAVEARC")
+
+// Represents the version of the chain archive format
+const ArchiveVersion uint32 = 1
+
+// Represents the maximum size of a single block in a chain archive
+const MaxArchiveBlockSize = 32 << 20
+
+// A structure that writes blocks to a chain archive. An archive begins with the
+// magic bytes and format version, followed by each block as a big-endian uint32
+// length prefix and the gob encoded block, in height order from the genesis.
+type ArchiveWriter struct {
+	writer *bufio.Writer
+}
+
+// A constructor function that generates and returns an ArchiveWriter
+// for a given writer after writing the archive header to it.
+func NewArchiveWriter(w io.Writer) (*ArchiveWriter, error) {
+	// Create a buffered writer
+	writer := bufio.NewWriter(w)
+
+	// Write the magic bytes and the format version
+	if _, err := writer.Write(ArchiveMagic); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(writer, binary.BigEndian, ArchiveVersion); err != nil {
+		return nil, err
+	}
+
+	// Return the archive writer
+	return &ArchiveWriter{writer: writer}, nil
+}
+
+// A method of ArchiveWriter that writes a Block to the archive
+func (archive *ArchiveWriter) WriteBlock(block *Block) error {
+	// Serialize the block
+	data := block.Serialize()
+
+	// Write the length prefix and the block data
+	if err := binary.Write(archive.writer, binary.BigEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	_, err := archive.writer.Write(data)
+	return err
+}
+
+// A method of ArchiveWriter that flushes any buffered blocks to the underlying writer
+func (archive *ArchiveWriter) Flush() error {
+	return archive.writer.Flush()
+}
+
+// A structure that reads blocks from a chain archive
+type ArchiveReader struct {
+	reader *bufio.Reader
+}
+
+// A constructor function that generates and returns an ArchiveReader for a given
+// reader. Returns an error if the reader does not begin with a valid archive header.
+func NewArchiveReader(r io.Reader) (*ArchiveReader, error) {
+	// Create a buffered reader
+	reader := bufio.NewReader(r)
+
+	// Read and check the magic bytes
+	magic := make([]byte, len(ArchiveMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || !bytes.Equal(magic, ArchiveMagic) {
+		return nil, fmt.Errorf("not a chain archive")
+	}
+
+	// Read and check the format version
+	var version uint32
+	if err := binary.Read(reader, binary.BigEndian, &version); err != nil {
+		return nil, fmt.Errorf("failed to read archive version! error - %v", err)
+	}
+	if version != ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %v", version)
+	}
+
+	// Return the archive reader
+	return &ArchiveReader{reader: reader}, nil
+}
+
+// A method of ArchiveReader that reads the next Block from the archive.
+// Returns io.EOF once all the blocks in the archive have been read.
+func (archive *ArchiveReader) Next() (*Block, error) {
+	// Read the length prefix of the block
+	var size uint32
+	if err := binary.Read(archive.reader, binary.BigEndian, &size); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("failed to read archive block size! error - %v", err)
+	}
+
+	// Check that the block size is within bounds
+	if size == 0 || size > MaxArchiveBlockSize {
+		return nil, fmt.Errorf("archive block size %v is out of bounds", size)
+	}
+
+	// Read the block data
+	data := make([]byte, size)
+	if _, err := io.ReadFull(archive.reader, data); err != nil {
+		return nil, fmt.Errorf("archive is truncated! error - %v", err)
+	}
+
+	// Deserialize the block
+	block := NullBlock()
+	block.Deserialize(data)
+	return block, nil
+}
+
+// A method of BlockChain that exports all the blocks of the chain to a writer as a
+// chain archive, in height order from the genesis. The chain must not be pruned.
+// Returns the number of blocks that were exported.
+func (chain *BlockChain) ExportChain(w io.Writer) (int, error) {
+	// Check that the chain has all its block bodies
+	if err := chain.RequireFullChain("export chain"); err != nil {
+		return 0, err
+	}
+
+	// Collect the block hashes from the chain head to the genesis
+	hashes := make([][]byte, 0, chain.ChainHeight)
+	iter := NewIterator(chain)
+	for len(iter.Cursor) != 0 {
+		hashes = append(hashes, iter.Cursor)
+		iter.NextHeader()
+	}
+
+	// Create an archive writer
+	archive, err := NewArchiveWriter(w)
+	if err != nil {
+		return 0, err
+	}
+
+	// Iterate over the block hashes from the genesis
+	for index := len(hashes) - 1; index >= 0; index-- {
+		// Retrieve the block
+		block, err := chain.GetBlock(hashes[index])
+		if err != nil {
+			return 0, err
+		}
+
+		// Write the block to the archive
+		if err := archive.WriteBlock(block); err != nil {
+			return 0, fmt.Errorf("failed to write block %v to archive! error - %v", block.BlockHeight, err)
+		}
+	}
+
+	// Flush the archive
+	if err := archive.Flush(); err != nil {
+		return 0, err
+	}
+
+	// Return the number of exported blocks
+	return len(hashes), nil
+}
+
+// A method of BlockChain that imports the blocks from a chain archive. Blocks that
+// are already on the chain are skipped and every other block is fully validated and
+// connected, which rebuilds the utxo layer as the chain is extended. Returns the
+// number of blocks that were connected and an error if a block is invalid or does
+// not belong to the chain.
+func (chain *BlockChain) ImportChain(archive *ArchiveReader) (int, error) {
+	// Declare a counter for the connected blocks
+	connected := 0
+
+	for {
+		// Read the next block from the archive
+		block, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return connected, err
+		}
+
+		// Check if the block is below the chain height
+		if block.BlockHeight < chain.ChainHeight {
+			// Check that the block is already on the chain
+			entry, err := chain.GetHeader(block.BlockHash)
+			if err != nil || entry.BlockHeight != block.BlockHeight {
+				return connected, fmt.Errorf("archive block at height %v conflicts with the chain", block.BlockHeight)
+			}
+
+			continue
+		}
+
+		// Validate the block against the chain
+		if err := chain.ValidateBlock(block); err != nil {
+			return connected, fmt.Errorf("archive block at height %v is invalid! error - %v", block.BlockHeight, err)
+		}
+
+		// Connect the block to the chain
+		if err := chain.ConnectBlock(block); err != nil {
+			return connected, err
+		}
+
+		// Increment the counter
+		connected++
+	}
+
+	// Return the number of connected blocks
+	return connected, nil
+}
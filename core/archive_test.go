@@ -0,0 +1,79 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_ChainArchive(t *testing.T) {
+	t.Parallel()
+	source := testchain(t)
+
+	address := testaddress()
+	for i := 0; i < 3; i++ {
+		source.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+
+	var buffer bytes.Buffer
+	count, err := source.ExportChain(&buffer)
+	if err != nil || count != 4 {
+		t.Fatalf("ExportChain() failed! expected: 4, got: %v (%v)", count, err)
+	}
+
+	// Bootstrap a new chain from the genesis of the archive
+	archive, err := NewArchiveReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatalf("NewArchiveReader() failed! %v", err)
+	}
+	genesis, err := archive.Next()
+	if err != nil {
+		t.Fatalf("ArchiveReader.Next() failed! %v", err)
+	}
+
+	target, err := NewBlockChainWithOptions(ChainOptions{InMemory: true, Params: testparams, Genesis: genesis})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+	defer target.CloseBuckets()
+
+	if connected, err := target.ImportChain(archive); err != nil || connected != 3 {
+		t.Fatalf("ImportChain() failed! expected: 3, got: %v (%v)", connected, err)
+	}
+	if !bytes.Equal(target.ChainHead, source.ChainHead) || target.CountUTXOS() != source.CountUTXOS() {
+		t.Fatalf("ImportChain() failed! imported chain does not match the source chain")
+	}
+
+	// Importing the archive again skips the blocks already on the chain
+	archive, _ = NewArchiveReader(bytes.NewReader(buffer.Bytes()))
+	if connected, err := target.ImportChain(archive); err != nil || connected != 0 {
+		t.Fatalf("ImportChain() repeat failed! expected: 0, got: %v (%v)", connected, err)
+	}
+
+	// An archive from another chain conflicts with the genesis
+	archive, _ = NewArchiveReader(bytes.NewReader(buffer.Bytes()))
+	if _, err := testchain(t).ImportChain(archive); err == nil {
+		t.Fatalf("ImportChain() failed! expected an error for a conflicting archive")
+	}
+}
+
+func Test_ValidateBlock(t *testing.T) {
+	t.Parallel()
+	chain := testchain(t)
+
+	address := testaddress()
+	block := chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+
+	// A block that was already connected no longer extends the chain
+	if err := chain.ValidateBlock(block); err == nil {
+		t.Fatalf("ValidateBlock() failed! expected an error for a stale block")
+	}
+
+	// A block with a tampered transaction list fails validation
+	other := testchain(t)
+	tampered := *block
+	tampered.Priori, tampered.BlockHeight = other.ChainHead, other.ChainHeight
+	tampered.TXList = []*Transaction{NewCoinbaseTransaction(address, testparams.Reward*2)}
+	if err := other.ValidateBlock(&tampered); err == nil {
+		t.Fatalf("ValidateBlock() failed! expected an error for a tampered block")
+	}
+}
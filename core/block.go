@@ -32,8 +32,8 @@ type Block struct {
 
 // A constructor function that generates and returns a new Block
 // that has been minted for a given merkle builder, previous block
-// hash, block height and a coinbase address.
-func NewBlock(merkletree *merkle.MerkleTree, priori utils.Hash, height int, origin wallet.Address) *Block {
+// hash, header history root, block height, coinbase address and work difficulty.
+func NewBlock(merkletree *merkle.MerkleTree, priori, history utils.Hash, height int, origin wallet.Address, difficulty uint8) *Block {
 
 	// Create and empty Block
 	block := Block{}
@@ -59,9 +59,9 @@ func NewBlock(merkletree *merkle.MerkleTree, priori utils.Hash, height int, orig
 	block.TXCount = merkletree.Count
 
 	// Create and assign the block header
-	block.BlockHeader = *NewBlockHeader(priori, merkletree.MerkleRoot)
-	// Set the Consensus Header to Proof Of Work
-	block.BlockHeader.ConsensusHeader = consensus.NewPOW()
+	block.BlockHeader = *NewBlockHeader(priori, merkletree.MerkleRoot, history)
+	// Set the Consensus Header to Proof Of Work with the work difficulty
+	block.BlockHeader.ConsensusHeader = consensus.NewDifficultyPOW(difficulty)
 	// Mint the block (sign)
 	block.BlockHash = block.Mint(&block.BlockHeader)
 
@@ -80,6 +80,15 @@ func NullBlock() *Block {
 	return block
 }
 
+// A method of Block that generates and returns the HeaderEntry of the Block
+func (block *Block) GenerateHeaderEntry() *HeaderEntry {
+	return &HeaderEntry{
+		BlockHeader: block.BlockHeader,
+		BlockHash:   block.BlockHash,
+		BlockHeight: block.BlockHeight,
+	}
+}
+
 // A method that returns the gob encoded data of the Block
 func (block *Block) Serialize() utils.Gob {
 	// Register the gob library with the Consensus Header type
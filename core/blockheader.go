@@ -1,7 +1,9 @@
 package core
 
 import (
+	"bytes"
 	"encoding/gob"
+	"fmt"
 	"time"
 
 	"github.com/manishmeganathan/weave/consensus"
@@ -22,13 +24,16 @@ type BlockHeader struct {
 	// Represent the merkle root of transactions on the Block
 	MerkleRoot utils.Hash
 
+	// Represents the root of the header history MMR of all previous Blocks
+	HistoryRoot utils.Hash
+
 	// Represents the network version of Block
 	Version []byte
 }
 
 // A constructor function that generates and returns a BlockHeader
-// for a given priori hash, merkle root and weave net address.
-func NewBlockHeader(priori, root utils.Hash) *BlockHeader {
+// for a given priori hash, merkle root and header history root.
+func NewBlockHeader(priori, root, history utils.Hash) *BlockHeader {
 	// Generate and return the block header
 	return &BlockHeader{
 		// Assign the software version
@@ -39,11 +44,20 @@ func NewBlockHeader(priori, root utils.Hash) *BlockHeader {
 		Priori: priori,
 		// Assign the merkle root hash
 		MerkleRoot: root,
+		// Assign the header history root hash
+		HistoryRoot: history,
 		// Assign a nil consensus header
 		ConsensusHeader: nil,
 	}
 }
 
+// A method of BlockHeader that generates the hash of the BlockHeader.
+// This is the same hash that is generated when the Block is minted.
+func (bh *BlockHeader) GenerateHash() utils.Hash {
+	// Serialize the blockheader into a gob and hash it
+	return utils.Hash256(bh.Serialize())
+}
+
 // A method that returns the gob encoded data of the BlockHeader
 func (bh *BlockHeader) Serialize() utils.Gob {
 	// Register the gob library with the Consensus Header type
@@ -59,3 +73,71 @@ func (bh *BlockHeader) Deserialize(gobdata utils.Gob) {
 	// Decode the gob data into the blockheader
 	utils.GobDecode(gobdata, bh)
 }
+
+// A function that validates a BlockHeader for a given block hash.
+// Checks that the hash belongs to the header and that the header
+// satisfies its proof of work for the given work difficulty.
+func ValidateHeader(header *BlockHeader, blockhash utils.Hash, difficulty uint8) error {
+	// Check that the header has a proof of work consensus header
+	pow, ok := header.ConsensusHeader.(*consensus.POW)
+	if !ok {
+		return fmt.Errorf("header has no proof of work")
+	}
+
+	// Check that the block hash is the hash of the header
+	if !bytes.Equal(header.GenerateHash(), blockhash) {
+		return fmt.Errorf("block hash does not match header")
+	}
+
+	// Check that the proof of work target matches the work difficulty
+	if pow.Target.Cmp(consensus.DifficultyTarget(difficulty)) != 0 {
+		return fmt.Errorf("header target does not match the work difficulty")
+	}
+
+	// Check that the header satisfies its proof of work
+	if !pow.Validate(header) {
+		return fmt.Errorf("header does not satisfy its proof of work")
+	}
+
+	// Return a nil error
+	return nil
+}
+
+// A structure that represents a BlockHeader along with the chain metadata
+// of its Block. This is the unit of data that is stored by light nodes.
+type HeaderEntry struct {
+	// Represents the Block Header
+	BlockHeader
+
+	// Represents the Hash of the Block Header
+	BlockHash utils.Hash
+
+	// Represents the Block Height
+	BlockHeight int
+}
+
+// A construcor function that generates and returns a null HeaderEntry
+func NullHeaderEntry() *HeaderEntry {
+	// Create an empty header entry object
+	entry := &HeaderEntry{}
+	// Set the consensus header to null pow block
+	entry.BlockHeader.ConsensusHeader = consensus.NewPOW()
+	// Return the header entry
+	return entry
+}
+
+// A method that returns the gob encoded data of the HeaderEntry
+func (entry *HeaderEntry) Serialize() utils.Gob {
+	// Register the gob library with the Consensus Header type
+	gob.Register(entry.BlockHeader.ConsensusHeader)
+	// Encode the header entry as a gob and return it
+	return utils.GobEncode(entry)
+}
+
+// A method that decodes a gob of bytes into the HeaderEntry struct
+func (entry *HeaderEntry) Deserialize(gobdata utils.Gob) {
+	// Register the gob library with the Consensus Header type
+	gob.Register(entry.BlockHeader.ConsensusHeader)
+	// Decode the gob data into the header entry
+	utils.GobDecode(gobdata, entry)
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+// Represents the maximum number of header entries held in the header cache
+const HeaderCacheSize = 4096
+
+// A structure that represents the body of a Block.
+// The body is stored separately from the header of the block so
+// that walking over the headers of the chain never decodes transactions.
+type BlockBody struct {
+	// Represents the address of the Block origin (miner address)
+	BlockOrigin wallet.Address
+
+	// Represents the no.of Transactions in the Block
+	TXCount int
+
+	// Represents the list of Transactions in the Block
+	TXList []*Transaction
+}
+
+// A method that returns the gob encoded data of the BlockBody
+func (body *BlockBody) Serialize() utils.Gob {
+	// Encode the block body as a gob and return it
+	return utils.GobEncode(body)
+}
+
+// A method that decodes a gob of bytes into the BlockBody struct
+func (body *BlockBody) Deserialize(gobdata utils.Gob) {
+	// Decode the gob data into the block body
+	utils.GobDecode(gobdata, body)
+}
+
+// A function that returns the blocks bucket key for the header of a given block hash
+func headerkey(blockhash utils.Hash) []byte {
+	return append(append([]byte{}, utils.Headerprefix...), blockhash...)
+}
+
+// A function that returns the blocks bucket key for the body of a given block hash
+func bodykey(blockhash utils.Hash) []byte {
+	return append(append([]byte{}, utils.Bodyprefix...), blockhash...)
+}
+
+// A method of BlockChain that sets a Block to the blocks bucket.
+// The header and the body of the block are stored under separate keys.
+// The body is stored first so that a stored header always has a body.
+func (chain *BlockChain) StoreBlock(block *Block) error {
+	// Construct the body of the block
+	body := &BlockBody{BlockOrigin: block.BlockOrigin, TXCount: block.TXCount, TXList: block.TXList}
+
+	// Set the block body to the blocks bucket
+	if err := chain.Blocks.SetKey(bodykey(block.BlockHash), body.Serialize()); err != nil {
+		return err
+	}
+
+	// Set the block header to the blocks bucket
+	entry := block.GenerateHeaderEntry()
+	if err := chain.Blocks.SetKey(headerkey(block.BlockHash), entry.Serialize()); err != nil {
+		return err
+	}
+
+	// Add the header entry to the header cache
+	chain.headers.put(entry)
+	// Return a nil error
+	return nil
+}
+
+// A method of BlockChain that retrieves the HeaderEntry for a given block hash.
+// Headers are served from the header cache when possible.
+func (chain *BlockChain) GetHeader(blockhash utils.Hash) (*HeaderEntry, error) {
+	// Check if the header entry is in the header cache
+	if entry, ok := chain.headers.get(blockhash); ok {
+		return entry, nil
+	}
+
+	// Get the header entry gob data from the blocks bucket
+	entrygob, err := chain.Blocks.GetKey(headerkey(blockhash))
+	if err != nil {
+		// Return the error
+		return nil, fmt.Errorf("header retrieval failed! error - %v", err)
+	}
+
+	// Convert the gob data into a HeaderEntry object
+	entry := NullHeaderEntry()
+	entry.Deserialize(entrygob)
+
+	// Add the header entry to the header cache
+	chain.headers.put(entry)
+	// Return the header entry
+	return entry, nil
+}
+
+// A method of BlockChain that retrieves the BlockBody for a given block hash
+func (chain *BlockChain) GetBody(blockhash utils.Hash) (*BlockBody, error) {
+	// Get the block body gob data from the blocks bucket
+	bodygob, err := chain.Blocks.GetKey(bodykey(blockhash))
+	if err != nil {
+		// Return the error
+		return nil, fmt.Errorf("body retrieval failed! error - %v", err)
+	}
+
+	// Convert the gob data into a BlockBody object
+	body := &BlockBody{}
+	body.Deserialize(bodygob)
+
+	// Return the block body
+	return body, nil
+}
+
+// A method of BlockChain that retrieves the Block for a given block hash from the blocks bucket
+func (chain *BlockChain) GetBlock(blockhash utils.Hash) (*Block, error) {
+	// Retrieve the header of the block
+	entry, err := chain.GetHeader(blockhash)
+	if err != nil {
+		return nil, fmt.Errorf("block retrieval failed! error - %v", err)
+	}
+
+	// Check if the body of the block has been pruned
+	if entry.BlockHeight < chain.PrunedHeight {
+		return nil, fmt.Errorf("block retrieval failed! block at height %v: %w", entry.BlockHeight, ErrBlockPruned)
+	}
+
+	// Retrieve the body of the block
+	body, err := chain.GetBody(blockhash)
+	if err != nil {
+		return nil, fmt.Errorf("block retrieval failed! error - %v", err)
+	}
+
+	// Assemble the block from its header and body
+	return &Block{
+		BlockHeader: entry.BlockHeader,
+		BlockHash:   entry.BlockHash,
+		BlockHeight: entry.BlockHeight,
+		BlockOrigin: body.BlockOrigin,
+		TXCount:     body.TXCount,
+		TXList:      body.TXList,
+	}, nil
+}
+
+// A method of BlockChain that collects the hashes of the blocks on the chain in order
+// of height by walking its headers back from the chain head to the genesis block.
+// Returns an error if a header is missing from the blocks bucket.
+func (chain *BlockChain) blockhashes() ([]utils.Hash, error) {
+	hashes := make([]utils.Hash, chain.ChainHeight)
+	cursor := chain.ChainHead
+	for height := chain.ChainHeight - 1; height >= 0; height-- {
+		// Retrieve the header of the block at the height
+		entry, err := chain.GetHeader(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get header at height %v! error - %v", height, err)
+		}
+
+		// Add the block hash at its height and move to the previous block
+		hashes[height] = entry.BlockHash
+		cursor = entry.Priori
+	}
+
+	return hashes, nil
+}
+
+// A structure that represents a bounded least-recently-used cache of header entries
+type headercache struct {
+	// Represents the mapping of block hashes to cache elements
+	entries map[string]*list.Element
+	// Represents the recency order of the cache elements (most recent first)
+	order *list.List
+	// Represents the synchronization lock for the cache
+	mutex sync.Mutex
+}
+
+// A constructor function that generates and returns an empty headercache
+func newheadercache() *headercache {
+	return &headercache{entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// A method of headercache that retrieves the header entry for a given block hash
+func (cache *headercache) get(blockhash utils.Hash) (*HeaderEntry, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// Retrieve the element from the cache
+	element, ok := cache.entries[string(blockhash)]
+	if !ok {
+		return nil, false
+	}
+
+	// Mark the element as the most recently used
+	cache.order.MoveToFront(element)
+	return element.Value.(*HeaderEntry), true
+}
+
+// A method of headercache that adds a header entry to the cache.
+// The least recently used entry is evicted when the cache is full.
+func (cache *headercache) put(entry *HeaderEntry) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// Check if the entry is already in the cache
+	if element, ok := cache.entries[string(entry.BlockHash)]; ok {
+		element.Value = entry
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	// Add the entry as the most recently used element
+	cache.entries[string(entry.BlockHash)] = cache.order.PushFront(entry)
+
+	// Evict the least recently used element if the cache is full
+	if cache.order.Len() > HeaderCacheSize {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, string(oldest.Value.(*HeaderEntry).BlockHash))
+	}
+}
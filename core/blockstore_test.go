@@ -0,0 +1,77 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_BlockStore(t *testing.T) {
+	t.Parallel()
+	chain := testchain(t)
+
+	address := testaddress()
+	block := chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+
+	// Blocks are stored with a separate header and body
+	if _, err := chain.Blocks.GetKey(headerkey(block.BlockHash)); err != nil {
+		t.Fatalf("StoreBlock() failed! header was not stored: %v", err)
+	}
+	if _, err := chain.Blocks.GetKey(bodykey(block.BlockHash)); err != nil {
+		t.Fatalf("StoreBlock() failed! body was not stored: %v", err)
+	}
+
+	// A block is rebuilt from its header and body
+	stored, err := chain.GetBlock(block.BlockHash)
+	if err != nil {
+		t.Fatalf("GetBlock() failed! %v", err)
+	}
+	if !bytes.Equal(stored.Serialize(), block.Serialize()) {
+		t.Fatalf("GetBlock() failed! stored block does not match the block")
+	}
+
+	// The header of a block is retrieved without its body
+	chain.Blocks.DeleteKey(bodykey(block.BlockHash))
+	entry, err := chain.GetHeader(block.BlockHash)
+	if err != nil || entry.BlockHeight != 1 || !bytes.Equal(entry.Priori, block.Priori) {
+		t.Fatalf("GetHeader() failed! expected the header of the block, got: %v", err)
+	}
+	if _, err := chain.GetBlock(block.BlockHash); err == nil {
+		t.Fatalf("GetBlock() failed! expected an error for a block without a body")
+	}
+
+	// Headers are served from the cache after they are retrieved
+	chain.Blocks.DeleteKey(headerkey(block.BlockHash))
+	if _, err := chain.GetHeader(block.BlockHash); err != nil {
+		t.Fatalf("GetHeader() failed! expected the header from the cache, got: %v", err)
+	}
+}
+
+func Test_HeaderCache(t *testing.T) {
+	t.Parallel()
+	cache := newheadercache()
+
+	// Fill the cache upto its size
+	entries := make([]*HeaderEntry, HeaderCacheSize+1)
+	for i := range entries {
+		entries[i] = &HeaderEntry{BlockHash: []byte{byte(i >> 8), byte(i)}, BlockHeight: i}
+	}
+	for _, entry := range entries[:HeaderCacheSize] {
+		cache.put(entry)
+	}
+
+	// Use the oldest entry and add one more entry
+	if _, ok := cache.get(entries[0].BlockHash); !ok {
+		t.Fatalf("headercache.get() failed! expected the entry in the cache")
+	}
+	cache.put(entries[HeaderCacheSize])
+
+	// The least recently used entry is evicted
+	if _, ok := cache.get(entries[1].BlockHash); ok {
+		t.Fatalf("headercache.put() failed! expected the least recently used entry to be evicted")
+	}
+	for _, entry := range []*HeaderEntry{entries[0], entries[HeaderCacheSize]} {
+		if cached, ok := cache.get(entry.BlockHash); !ok || cached.BlockHeight != entry.BlockHeight {
+			t.Fatalf("headercache.get() failed! expected the entry at height %v", entry.BlockHeight)
+		}
+	}
+}
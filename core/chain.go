@@ -1,6 +1,10 @@
 package core
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
 	"github.com/manishmeganathan/weave/merkle"
 	"github.com/manishmeganathan/weave/persistence"
 	"github.com/manishmeganathan/weave/utils"
@@ -8,81 +12,311 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Represents the error returned when a new chain would be set up over the data of an existing chain
+var ErrChainData = errors.New("database holds chain data without a chain head. run 'weave db verify --repair' to recover it")
+
 // A structure that represents the blockchain
 type BlockChain struct {
 	// Represents the database bucket for the chain state
-	State *persistence.DatabaseBucket
+	State persistence.Store
 
 	// Represents the database bucket for the chain blocks
-	Blocks *persistence.DatabaseBucket
+	Blocks persistence.Store
+
+	// Represents the database bucket for the chain indexes
+	Index persistence.Store
 
 	// Represents the hash of the latest block
 	ChainHead utils.Hash
 
 	// Represents the number of block on the chain (last block height+1)
 	ChainHeight int
+
+	// Represents the header history MMR of the chain
+	History *merkle.MMR
+
+	// Represents the number of most recent block bodies kept (0 keeps all)
+	PruneDepth int
+
+	// Represents the height of the lowest block with a body on the chain
+	PrunedHeight int
+
+	// Represents the consensus parameters of the chain
+	Params ChainParams
+
+	// Represents the pool of blocks whose priori block is not known yet
+	Orphans *OrphanPool[*Block]
+
+	// Represents the cache of recently used block headers
+	headers *headercache
+}
+
+// A structure that represents the options for opening a BlockChain
+type ChainOptions struct {
+	// Represents the directory that holds the database buckets of the chain
+	DataDir string
+
+	// Represents whether the chain is held in memory (DataDir is ignored)
+	InMemory bool
+
+	// Represents the consensus parameters of the chain
+	Params ChainParams
+
+	// Represents the address that receives the reward of the genesis block
+	Coinbase wallet.Address
+
+	// Represents the genesis block of a new chain (minted for Coinbase if nil)
+	Genesis *Block
+
+	// Represents the utxo snapshot that a new chain is started from (overrides Genesis)
+	Snapshot *UTXOSnapshot
+
+	// Represents the number of most recent block bodies kept (0 keeps all)
+	PruneDepth int
 }
 
 // A constructor function that creates a new BlockChain object.
 // Checks if the chain database is already configured and initializes
 // the object based on that, otherwise configures a new chain database.
+// The chain is configured from the config file with the default params.
 func NewBlockChain() *BlockChain {
-	// Create a null blockchain
-	blockchain := BlockChain{}
+	return newconfigchain(nil)
+}
+
+// A constructor function that creates a new BlockChain object from the config
+// file like NewBlockChain but connects a given genesis block if the chain database
+// is new instead of minting one. Used to bootstrap a chain from an archive.
+func NewBlockChainFromGenesis(genesis *Block) *BlockChain {
+	return newconfigchain(func(chain *BlockChain) error { return chain.setup_genesis(genesis) })
+}
+
+// A constructor function that creates a new BlockChain object from the config
+// file like NewBlockChain but loads a given utxo snapshot if the chain database
+// is new instead of minting a genesis block. The snapshot must have been verified
+// by the caller against a trusted snapshot hash.
+func NewBlockChainFromSnapshot(snapshot *UTXOSnapshot) *BlockChain {
+	return newconfigchain(func(chain *BlockChain) error { return chain.setup_snapshot(snapshot) })
+}
+
+// A function that creates a BlockChain object from the config file with the default
+// params. A new chain database is set up with the given setup function, or with a
+// genesis block minted for the default JBOK address if the setup function is nil.
+func newconfigchain(setup func(chain *BlockChain) error) *BlockChain {
+	// Get the Config data
+	config := utils.ReadConfigFile()
+	// Create a null blockchain with the default params
+	blockchain := newchain(DefaultChainParams())
 
-	// Check if a blockchain db already exists
-	if persistence.CheckDatabase() {
+	// Check if a blockchain db already exists (clears a partial db)
+	persistence.CheckDatabase()
+	// Open the database clients for all buckets
+	blockchain.OpenBuckets()
+
+	// Check if the database has a chain on it
+	if blockchain.HasChain() {
 		// Setup existing blockchain db
-		blockchain.setup_oldchain()
+		if err := blockchain.setup_oldchain(); err != nil {
+			// Log a fatal error
+			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to open chain. run 'weave db verify --repair' to recover it.")
+		}
+	} else if setup != nil {
+		// Setup new blockchain db with the setup function
+		if err := setup(blockchain); err != nil {
+			// Log a fatal error
+			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to setup new chain.")
+		}
 	} else {
+		// Get the wallet address of the miner coinbase from the config
+		address, err := wallet.NewAddress(config.JBOK.Default)
+		if err != nil {
+			// Log a fatal error
+			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to get address for coinbase.")
+		}
+
 		// Setup new blockchain db
-		blockchain.setup_newchain()
+		if err := blockchain.setup_newchain(*address); err != nil {
+			// Log a fatal error
+			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to add genesis block to chain.")
+		}
+	}
+
+	// Configure block pruning for the chain
+	if err := blockchain.OpenPruning(config.DB.PruneDepth); err != nil {
+		// Log a fatal error
+		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to configure block pruning.")
 	}
 
 	// Return the blockchain
-	return &blockchain
+	return blockchain
 }
 
-// A method of BlockChain that configures an existing chain database.
-func (chain *BlockChain) setup_oldchain() {
-	chain.OpenBuckets()
+// A constructor function that creates a new BlockChain object for the given options.
+// The config file is not accessed, which allows multiple chains to be opened by a
+// process at the same time (in memory or in separate data directories). Used for
+// tests and simulations. Returns an error if the chain could not be opened.
+func NewBlockChainWithOptions(options ChainOptions) (*BlockChain, error) {
+	// Create a null blockchain with the options params
+	blockchain := newchain(options.Params)
+
+	// Check if the chain should be held in memory
+	if options.InMemory {
+		// Create an in-memory store for each bucket
+		blockchain.State = persistence.NewMemoryStore()
+		blockchain.Blocks = persistence.NewMemoryStore()
+		blockchain.Index = persistence.NewMemoryStore()
+
+	} else {
+		// Open a badger store for each bucket in the data directory
+		stores := make([]*persistence.DatabaseBucket, 0, 3)
+		for _, bucket := range []persistence.Bucket{persistence.STATE, persistence.BLOCKS, persistence.INDEX} {
+			store, err := persistence.OpenBadgerStore(bucket, filepath.Join(options.DataDir, string(bucket)))
+			if err != nil {
+				// Close the stores that have been opened
+				for _, opened := range stores {
+					opened.Close()
+				}
+
+				return nil, err
+			}
+
+			stores = append(stores, store)
+		}
+
+		blockchain.State, blockchain.Blocks, blockchain.Index = stores[0], stores[1], stores[2]
+	}
+
+	// Check if the stores have a chain on them
+	var err error
+	if blockchain.HasChain() {
+		err = blockchain.setup_oldchain()
+	} else if options.Snapshot != nil {
+		err = blockchain.setup_snapshot(options.Snapshot)
+	} else if options.Genesis != nil {
+		err = blockchain.setup_genesis(options.Genesis)
+	} else {
+		err = blockchain.setup_newchain(options.Coinbase)
+	}
+
+	// Handle any potential error
+	if err != nil {
+		blockchain.CloseBuckets()
+		return nil, err
+	}
+
+	// Configure block pruning for the chain
+	if err := blockchain.OpenPruning(options.PruneDepth); err != nil {
+		blockchain.CloseBuckets()
+		return nil, err
+	}
+
+	// Return the blockchain
+	return blockchain, nil
+}
+
+// A function that creates a null BlockChain with an empty header cache, an empty orphan pool and given params
+func newchain(params ChainParams) *BlockChain {
+	return &BlockChain{
+		Params:  params,
+		Orphans: NewOrphanPool[*Block](DefaultMaxOrphanBlocks, DefaultMaxOrphansPerPeer, DefaultOrphanTTL),
+		headers: newheadercache(),
+	}
+}
+
+// A method of BlockChain that returns whether its state bucket has a chain head.
+// A database without a chain head may still hold the data of a chain whose head
+// was lost, which is checked with checkempty before a new chain is set up on it.
+func (chain *BlockChain) HasChain() bool {
+	_, err := chain.State.GetKey(utils.ChainHeadKey)
+	return err == nil
+}
+
+// A method of BlockChain that checks that its state and blocks buckets hold no data,
+// so that a new chain is never set up over the data of a chain whose head is missing.
+// The data of a utxo snapshot whose load was interrupted is cleared from the buckets.
+// Returns ErrChainData if the buckets hold a schema version or any other key.
+func (chain *BlockChain) checkempty() error {
+	// Clear the data of an interrupted snapshot load (headers, utxos and header history),
+	// the mark is removed last so that an interrupted clear is completed on the next run
+	if _, err := chain.State.GetKey(utils.SnapshotPendingKey); err == nil {
+		logrus.Warn("clearing the data of an interrupted snapshot load.")
+		chain.Blocks.DeleteKeyPrefix(utils.Headerprefix)
+		chain.State.DeleteKeyPrefix(utils.UTXOprefix)
+		chain.State.DeleteKeyPrefix(utils.MMRprefix)
 
+		if err := chain.State.DeleteKey(utils.SnapshotPendingKey); err != nil {
+			return fmt.Errorf("failed to clear interrupted snapshot! error - %v", err)
+		}
+	}
+
+	// Check if the state bucket has a schema version
+	if _, err := chain.State.GetKey(utils.SchemaVersionKey); err == nil {
+		return ErrChainData
+	}
+
+	// Check if either bucket has any key (such as headers, bodies or utxos)
+	for _, store := range []persistence.Store{chain.State, chain.Blocks} {
+		err := store.IteratePrefix([]byte{}, func(key, value []byte) error {
+			return ErrChainData
+		})
+
+		if err != nil {
+			return ErrChainData
+		}
+	}
+
+	// Return a nil error
+	return nil
+}
+
+// A method of BlockChain that configures an existing chain database.
+func (chain *BlockChain) setup_oldchain() error {
 	// Get the chain head from the state bucket
 	chainhead, err := chain.State.GetKey(utils.ChainHeadKey)
 	if err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to get chain head from state.")
+		return fmt.Errorf("failed to get chain head from state! error - %v", err)
+	}
+
+	// Assign the current chain head
+	chain.ChainHead = chainhead
+
+	// Migrate the chain database to the latest schema version
+	if err := persistence.Migrate(chain.State, chain.migrations()); err != nil {
+		return err
 	}
 
 	// Get the chain height from the state bucket
 	chainheight, err := chain.State.GetKey(utils.ChainHeightKey)
 	if err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to get chain height from state.")
+		return fmt.Errorf("failed to get chain height from state! error - %v", err)
 	}
 
-	// Assign the current chain head
-	chain.ChainHead = chainhead
 	// Assign the current chain height
-	chain.ChainHeight = utils.HexDecode(chainheight)
-}
+	if chain.ChainHeight, err = utils.IntDecode(chainheight); err != nil {
+		return fmt.Errorf("chain height in state is corrupt! error - %v", err)
+	}
 
-// A method of BlockChain that configures a new chain database.
-func (chain *BlockChain) setup_newchain() {
-	// Open the database clients for all buckets
-	chain.OpenBuckets()
+	// Assign the pruned height if the chain has been pruned
+	if prunedheight, err := chain.State.GetKey(utils.PrunedHeightKey); err == nil {
+		if chain.PrunedHeight, err = utils.IntDecode(prunedheight); err != nil {
+			return fmt.Errorf("pruned height in state is corrupt! error - %v", err)
+		}
+	}
 
-	// Get the Config data
-	config := utils.ReadConfigFile()
-	// Get the wallet address of the miner coinbase from the config
-	address, err := wallet.NewAddress(config.JBOK.Default)
-	if err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to get address for coinbase.")
+	// Check the consistency of the chain database
+	if err := chain.CheckConsistency(); err != nil {
+		return fmt.Errorf("chain database is inconsistent! error - %v", err)
 	}
 
+	// Return a nil error
+	return nil
+}
+
+// A method of BlockChain that configures a new chain database.
+// Mints the genesis block with a coinbase for the given address
+// and connects it to the empty chain.
+func (chain *BlockChain) setup_newchain(address wallet.Address) error {
 	// Generate a coinbase transaction for the genesis block
-	coinbase := NewCoinbaseTransaction(*address)
+	coinbase := NewCoinbaseTransaction(address, chain.Params.Reward)
 
 	// Create a merkle builder
 	merkletree := merkle.NewMerkleTree()
@@ -90,32 +324,37 @@ func (chain *BlockChain) setup_newchain() {
 	merkletree.BuildFull([]utils.GobEncodable{coinbase})
 
 	// Generate a Genesis Block for the chain with a coinbase transaction
-	genesisblock := NewBlock(merkletree, []byte{}, 0, *address)
+	genesisblock := NewBlock(merkletree, []byte{}, nil, 0, address, chain.Params.Difficulty)
 	// Log the minting of the genesis block
 	logrus.WithFields(logrus.Fields{"address": address.String, "reward": coinbase.Outputs[0].Value}).Info("genesis block has been minted!")
 
-	// Set the genesis block to the blocks bukcet
-	if err = chain.Blocks.SetKey(genesisblock.BlockHash, genesisblock.Serialize()); err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to add genesis block to blocks.")
+	// Setup the chain with the genesis block
+	return chain.setup_genesis(genesisblock)
+}
+
+// A method of BlockChain that configures a new chain database
+// with a given genesis block after validating it.
+func (chain *BlockChain) setup_genesis(genesisblock *Block) error {
+	// Check that the database does not hold the data of another chain
+	if err := chain.checkempty(); err != nil {
+		return err
 	}
 
-	// Set the genesis block hash as the chain head in the state bucket
-	if err = chain.State.SetKey(utils.ChainHeadKey, genesisblock.BlockHash); err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to add chain head to state.")
+	// Set the schema version of the chain database to the latest version
+	if err := persistence.SetSchemaVersion(chain.State, persistence.LatestVersion(chain.migrations())); err != nil {
+		return err
 	}
 
-	// Set the chain height as 1 in the state bucket
-	if err = chain.State.SetKey(utils.ChainHeightKey, utils.HexEncode(1)); err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to add chain height to state.")
+	// Create an empty header history for the chain
+	chain.History = merkle.NewMMR(&historystore{store: chain.State}, 0)
+
+	// Validate the genesis block against the empty chain
+	if err := chain.ValidateBlock(genesisblock); err != nil {
+		return fmt.Errorf("invalid genesis block! error - %v", err)
 	}
 
-	// Assign the current chain head
-	chain.ChainHead = genesisblock.BlockHash
-	// Assign the current chain height
-	chain.ChainHeight = 1
+	// Connect the genesis block to the empty chain
+	return chain.ConnectBlock(genesisblock)
 }
 
 // A method of BlockChain that adds a new Block to the chain and returns it
@@ -132,30 +371,20 @@ func (chain *BlockChain) AddBlock(blocktxns []*Transaction, addr wallet.Address)
 	// Close the build queue
 	close(merkletree.BuildQueue)
 
-	// Generate a new Block
-	block := NewBlock(merkletree, chain.ChainHead, chain.ChainHeight, addr)
-
-	// Assign the hash of the block as the chain head
-	chain.ChainHead = block.BlockHash
-	// Increment the chain height
-	chain.ChainHeight++
-
-	// Set the block to the blocks bucket
-	if err := chain.Blocks.SetKey(block.BlockHash, block.Serialize()); err != nil {
+	// Get the root of the header history
+	history, err := chain.History.Root()
+	if err != nil {
 		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to add block head to blocks.")
+		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to get header history root.")
 	}
 
-	// Set the block hash as the chain head in the state bucket
-	if err := chain.State.SetKey(utils.ChainHeadKey, chain.ChainHead); err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to update chain head state.")
-	}
+	// Generate a new Block
+	block := NewBlock(merkletree, chain.ChainHead, history, chain.ChainHeight, addr, chain.Params.Difficulty)
 
-	// Set the chain height as the current chain height in the state bucket
-	if err := chain.State.SetKey(utils.ChainHeightKey, utils.HexEncode(chain.ChainHeight)); err != nil {
+	// Connect the block to the chain
+	if err := chain.ConnectBlock(block); err != nil {
 		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to update chain height state.")
+		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to add block to chain.")
 	}
 
 	// Return the block
@@ -163,17 +392,14 @@ func (chain *BlockChain) AddBlock(blocktxns []*Transaction, addr wallet.Address)
 }
 
 // A method of BlockChain that opens the client for all database buckets.
-// The method also sets up the exit handler to automatically close the clients.
+// The clients must be closed with CloseBuckets (see the node package).
 func (chain *BlockChain) OpenBuckets() {
 	// Set up the database client for the state bucket
 	chain.State = persistence.NewDatabaseBucket(persistence.STATE)
 	// Set up the database client for the blocks bucket
 	chain.Blocks = persistence.NewDatabaseBucket(persistence.BLOCKS)
-
-	logrus.RegisterExitHandler(func() {
-		// Close the database client
-		chain.CloseBuckets()
-	})
+	// Set up the database client for the index bucket
+	chain.Index = persistence.NewDatabaseBucket(persistence.INDEX)
 }
 
 // A method of BlockChain that closes the client for all database buckets.
@@ -182,4 +408,40 @@ func (chain *BlockChain) CloseBuckets() {
 	chain.State.Close()
 	// Close the database client for the blocks bucket
 	chain.Blocks.Close()
+	// Close the database client for the index bucket
+	chain.Index.Close()
+}
+
+// A method of BlockChain that collects the header entries of the blocks on the chain
+// from a given start height upto a given count, in order of height. Used to serve
+// the headers of the chain to light nodes that are synchronizing their header chain.
+func (chain *BlockChain) CollectHeaders(startheight, count int) []*HeaderEntry {
+	// Check that the start height is on the chain
+	if startheight < 0 || startheight >= chain.ChainHeight {
+		return nil
+	}
+
+	// Determine the height after the last header to collect
+	endheight := startheight + count
+	if endheight > chain.ChainHeight {
+		endheight = chain.ChainHeight
+	}
+
+	// Create a slice for the header entries
+	headers := make([]*HeaderEntry, endheight-startheight)
+
+	// Get an iterator for the blockchain and iterate back to the start height
+	iter := NewIterator(chain)
+	for height := chain.ChainHeight - 1; height >= startheight; height-- {
+		// Get a header entry from the iterator
+		entry := iter.NextHeader()
+		// Check if the header is within the collected range
+		if height < endheight {
+			// Add the header entry at its position
+			headers[height-startheight] = entry
+		}
+	}
+
+	// Return the header entries
+	return headers
 }
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+// Represents the chain params used for tests (low difficulty for fast minting)
+var testparams = ChainParams{Difficulty: 8, Reward: 25}
+
+// A function that returns a new wallet address for tests
+func testaddress() wallet.Address {
+	return *wallet.NewWallet().GenerateAddress(byte(0x00))
+}
+
+// A function that returns a new in-memory chain for tests
+func testchain(t *testing.T) *BlockChain {
+	chain, err := NewBlockChainWithOptions(ChainOptions{InMemory: true, Params: testparams, Coinbase: testaddress()})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	t.Cleanup(chain.CloseBuckets)
+	return chain
+}
+
+func Test_InMemoryChain(t *testing.T) {
+	t.Parallel()
+	chain := testchain(t)
+
+	if chain.ChainHeight != 1 {
+		t.Fatalf("genesis chain height failed! expected: 1, got: %v", chain.ChainHeight)
+	}
+	if count := chain.CountUTXOS(); count != 1 {
+		t.Fatalf("genesis utxos failed! expected: 1, got: %v", count)
+	}
+
+	genesis := chain.ChainHead
+	address := testaddress()
+	block := chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+
+	if chain.ChainHeight != 2 || !bytes.Equal(chain.ChainHead, block.BlockHash) {
+		t.Fatalf("AddBlock() failed! chain head was not updated")
+	}
+	if count := chain.CountUTXOS(); count != 2 {
+		t.Fatalf("AddBlock() utxos failed! expected: 2, got: %v", count)
+	}
+	if err := ValidateHeader(&block.BlockHeader, block.BlockHash, testparams.Difficulty); err != nil {
+		t.Fatalf("ValidateHeader() failed! %v", err)
+	}
+
+	proof, err := chain.ProveAncestor(genesis)
+	if err != nil {
+		t.Fatalf("ProveAncestor() failed! %v", err)
+	}
+
+	entry, _ := chain.GetHeader(genesis)
+	if !VerifyAncestor(&entry.BlockHeader, &block.BlockHeader, proof) {
+		t.Fatalf("VerifyAncestor() failed! genesis is not an ancestor of the head")
+	}
+}
+
+func Test_ChainIsolation(t *testing.T) {
+	t.Parallel()
+	first, second := testchain(t), testchain(t)
+
+	address := testaddress()
+	first.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+
+	if second.ChainHeight != 1 {
+		t.Fatalf("chain isolation failed! expected: 1, got: %v", second.ChainHeight)
+	}
+	if bytes.Equal(first.ChainHead, second.ChainHead) {
+		t.Fatalf("chain isolation failed! chains share a chain head")
+	}
+}
+
+func Test_ChainDataDir(t *testing.T) {
+	t.Parallel()
+	options := ChainOptions{DataDir: t.TempDir(), Params: testparams, Coinbase: testaddress()}
+
+	chain, err := NewBlockChainWithOptions(options)
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	address := testaddress()
+	block := chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	chain.CloseBuckets()
+
+	reopened, err := NewBlockChainWithOptions(options)
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() reopen failed! %v", err)
+	}
+	defer reopened.CloseBuckets()
+
+	if reopened.ChainHeight != 2 || !bytes.Equal(reopened.ChainHead, block.BlockHash) {
+		t.Fatalf("reopen failed! expected height 2 with the added block as head")
+	}
+	if _, err := reopened.FindTransaction(block.TXList[0].ID); err != nil {
+		t.Fatalf("FindTransaction() failed! %v", err)
+	}
+}
+
+func Test_ChainMissingHead(t *testing.T) {
+	t.Parallel()
+	options := ChainOptions{DataDir: t.TempDir(), Params: testparams, Coinbase: testaddress()}
+
+	chain, err := NewBlockChainWithOptions(options)
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	// Lose the chain head of the chain
+	head := chain.ChainHead
+	if err := chain.State.DeleteKey(utils.ChainHeadKey); err != nil {
+		t.Fatalf("DeleteKey() failed! %v", err)
+	}
+	chain.CloseBuckets()
+
+	// A new chain is not set up over the data of the chain
+	if _, err := NewBlockChainWithOptions(options); !errors.Is(err, ErrChainData) {
+		t.Fatalf("NewBlockChainWithOptions() failed! expected: %v, got: %v", ErrChainData, err)
+	}
+
+	// The blocks of the chain are left untouched for a repair
+	blocks, err := persistence.OpenBadgerStore(persistence.BLOCKS, filepath.Join(options.DataDir, string(persistence.BLOCKS)))
+	if err != nil {
+		t.Fatalf("OpenBadgerStore() failed! %v", err)
+	}
+	defer blocks.Close()
+
+	if _, err := blocks.GetKey(headerkey(head)); err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! the header of the chain head was lost")
+	}
+}
@@ -3,39 +3,28 @@ package core
 import (
 	"bytes"
 	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
-	"math/big"
 
+	"github.com/manishmeganathan/weave/merkle"
 	"github.com/manishmeganathan/weave/utils"
-	"github.com/sirupsen/logrus"
 )
 
 // A method of BlockChain that finds a transaction
-// from the chain given a valid Transaction ID
+// from the chain given a valid Transaction ID.
+// The block of the transaction is looked up from the transaction index.
 func (chain *BlockChain) FindTransaction(txnid []byte) (Transaction, error) {
+	// Retrieve the block that includes the transaction
+	block, err := chain.txnblock(txnid, "find transaction")
+	if err != nil {
+		return Transaction{}, err
+	}
 
-	// Get an iterator for the blockchain and iterate over its block
-	iter := NewIterator(chain)
-
-	for {
-		// Get a block from the iterator
-		block := iter.Next()
-
-		// Iterate over the transactions of the block
-		for _, txn := range block.TXList {
-			// Check if the transaction ID matches
-			if bytes.Equal(txn.ID, txnid) {
-				// Return the transaction with a nil error
-				return *txn, nil
-			}
-		}
-
-		// Check if the block is genesis block
-		if block.BlockHeight == 0 {
-			break
+	// Iterate over the transactions of the block
+	for _, txn := range block.TXList {
+		// Check if the transaction ID matches
+		if bytes.Equal(txn.ID, txnid) {
+			// Return the transaction with a nil error
+			return *txn, nil
 		}
 	}
 
@@ -43,133 +32,100 @@ func (chain *BlockChain) FindTransaction(txnid []byte) (Transaction, error) {
 	return Transaction{}, fmt.Errorf("transaction does not exist")
 }
 
-// A method of BlockChain that signs a transaction given a private key
-func (chain *BlockChain) SignTransaction(txn *Transaction, privatekey ecdsa.PrivateKey) {
-	// Check if the transaction is a coinbase (cannot sign coinbase txns)
-	if txn.IsCoinbase() {
-		return
-	}
-
-	// Create a map of transaction IDs to Transactions
-	prevtxns := make(map[string]Transaction)
+// A method of BlockChain that returns the outputs spent by the inputs of a transaction
+// from the utxo layer, in the order of the inputs. Works on pruned chains because the
+// outputs are not looked up from the blocks that created them.
+func (chain *BlockChain) spentoutputs(txn *Transaction) (TXOList, error) {
+	// Declare the outputs spent by the inputs of the transaction
+	var spent TXOList
 
 	// Iterate over the inputs of the transaction
 	for _, input := range txn.Inputs {
-		// Find the Transaction with ID on the input from the blockchain
-		prevtxn, err := chain.FindTransaction(input.ID)
+		// Retrieve the unspent output of the input
+		output, err := chain.GetUTXO(input.ID, input.OutIndex)
 		if err != nil {
-			// Log a fatal error
-			logrus.WithFields(logrus.Fields{"error": err}).Fatalf("failed to sign transaction. could not find transaction associated with txn input - %s\n", input.ID)
+			return nil, err
 		}
 
-		// Add the transaction to the map
-		prevtxns[hex.EncodeToString(prevtxn.ID)] = prevtxn
+		// Add the output spent by the input
+		spent = append(spent, output)
 	}
 
-	// Generate a safe copy of the transaction
-	txncopy := txn.GenerateSafeCopy()
-
-	// Iterate over the inputs of the trimmed transaction
-	for inpindex, input := range txncopy.Inputs {
-		// Retrive the corresponding the previous transaction
-		prevtxn := prevtxns[hex.EncodeToString(input.ID)]
-		// Retrieve the public key hash of the corresponding transaction output
-		pubkeyhash := prevtxn.Outputs[input.OutIndex].PublicKeyHash
-
-		// Set the input signature to nil
-		txncopy.Inputs[inpindex].Signature = nil
-
-		// Set the input public key with the public key hash from the prev txn
-		txncopy.Inputs[inpindex].PublicKey = utils.PublicKey(pubkeyhash)
-		// Generate the hash of the trimmed transaction and assign it to its ID
-		txncopy.ID = txncopy.GenerateHash()
-		// Set the input public key to nil
-		txncopy.Inputs[inpindex].PublicKey = nil
+	return spent, nil
+}
 
-		// Sign the transaction with the ECDSA method using the private key and ID of the transaction trim
-		r, s, err := ecdsa.Sign(rand.Reader, &privatekey, txncopy.ID)
-		if err != nil {
-			// Log a fatal error
-			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to sign transaction.")
-		}
+// A method of BlockChain that signs a transaction given a private key. The public key
+// hashes of the spent outputs are read from the utxo layer. Returns an error if an input
+// does not spend an unspent output of the chain or the transaction cannot be signed.
+func (chain *BlockChain) SignTransaction(txn *Transaction, privatekey ecdsa.PrivateKey) error {
+	// Check if the transaction is a coinbase (cannot sign coinbase txns)
+	if txn.IsCoinbase() {
+		return nil
+	}
 
-		// Append method outputs to form the signature
-		signature := append(r.Bytes(), s.Bytes()...)
-		// Assign the signature of the Transaction
-		txn.Inputs[inpindex].Signature = signature
+	// Retrieve the outputs spent by the transaction
+	spent, err := chain.spentoutputs(txn)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction! error - %v", err)
 	}
+
+	// Sign the inputs of the transaction
+	return txn.Sign(privatekey, spent)
 }
 
-// A method of BlockChain that verifies the signature of a transaction given a private key
-func (chain *BlockChain) VerifyTransaction(txn *Transaction, privatekey ecdsa.PrivateKey) bool {
+// A method of BlockChain that verifies the signatures of a transaction against the
+// outputs that it spends from the utxo layer. Returns an error if an input does not
+// spend an unspent output of the chain or has an invalid signature.
+func (chain *BlockChain) VerifyTransaction(txn *Transaction) error {
 	// Check if transaction is a coinbase
 	if txn.IsCoinbase() {
-		return true
+		return nil
 	}
 
-	// Create a map of transaction IDs to Transactions
-	prevtxns := make(map[string]Transaction)
+	// Retrieve the outputs spent by the transaction
+	spent, err := chain.spentoutputs(txn)
+	if err != nil {
+		return fmt.Errorf("failed to verify transaction! error - %v", err)
+	}
 
-	// Iterate over the inputs of the transaction
-	for _, input := range txn.Inputs {
-		// Find the Transaction with ID on the input from the blockchain
-		prevtxn, err := chain.FindTransaction(input.ID)
-		if err != nil {
-			// Log a fatal error
-			logrus.WithFields(logrus.Fields{"error": err}).Fatalf("failed to verify transaction. could not find transaction associated with txn input - %s\n", input.ID)
-		}
+	// Verify the signatures of the inputs
+	return txn.Verify(spent)
+}
 
-		// Add the transaction to the map
-		prevtxns[hex.EncodeToString(prevtxn.ID)] = prevtxn
+// A method of BlockChain that generates a merkle inclusion proof for a transaction
+// given a valid Transaction ID. Returns the transaction, the hash of the block that
+// includes it and the proof of its inclusion against the merkle root of that block.
+func (chain *BlockChain) ProveTransaction(txnid []byte) (*Transaction, utils.Hash, *merkle.MerkleProof, error) {
+	// Retrieve the block that includes the transaction
+	block, err := chain.txnblock(txnid, "prove transaction")
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	// Generate a safe copy of the transaction
-	txncopy := txn.GenerateSafeCopy()
-
-	// Iterate over the inputs of the trimmed transaction
-	for inpindex, input := range txncopy.Inputs {
-		// Retrive the corresponding the previous transaction
-		prevtxn := prevtxns[hex.EncodeToString(input.ID)]
-		// Retrieve the public key hash of the corresponding transaction output
-		pubkeyhash := prevtxn.Outputs[input.OutIndex].PublicKeyHash
-
-		// Set the input signature to nil
-		txncopy.Inputs[inpindex].Signature = nil
-
-		// Set the input public key with the public key hash from the prev txn
-		txncopy.Inputs[inpindex].PublicKey = utils.PublicKey(pubkeyhash)
-		// Generate the hash of the trimmed transaction and assign it to its ID
-		txncopy.ID = txncopy.GenerateHash()
-		// Set the input public key to nil
-		txncopy.Inputs[inpindex].PublicKey = nil
-
-		// Declare r and s as big Ints
-		r := big.Int{}
-		s := big.Int{}
-		// Retrieve the size of the signature
-		signaturesize := len(input.Signature)
-		// Split the signature into r and s values
-		r.SetBytes(input.Signature[:(signaturesize / 2)])
-		s.SetBytes(input.Signature[(signaturesize / 2):])
-
-		// Declare the x and y as big Ints
-		x := big.Int{}
-		y := big.Int{}
-		// Retrieve the size of the public key
-		keysize := len(input.PublicKey)
-		// Split the public key into its x and y coordinates
-		x.SetBytes(input.PublicKey[:(keysize / 2)])
-		y.SetBytes(input.PublicKey[(keysize / 2):])
-
-		// Create an ECDSA public key from sepc256r1 curve and the x, y coordinates
-		rawpublickey := ecdsa.PublicKey{Curve: elliptic.P256(), X: &x, Y: &y}
-
-		// Check if the transaction has been signed with the public key's private pair
-		if !ecdsa.Verify(&rawpublickey, txncopy.ID, &r, &s) {
-			return false
+	// Iterate over the transactions of the block
+	for index, txn := range block.TXList {
+		// Check if the transaction ID matches
+		if bytes.Equal(txn.ID, txnid) {
+			// Rebuild the merkle tree for the block transactions
+			items := make([]utils.GobEncodable, len(block.TXList))
+			for i, blocktxn := range block.TXList {
+				items[i] = blocktxn
+			}
+
+			merkletree := merkle.NewMerkleTree()
+			merkletree.BuildFull(items)
+
+			// Generate the proof for the transaction
+			proof, err := merkletree.GenerateProof(index)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			// Return the transaction, block hash and proof
+			return txn, block.BlockHash, proof, nil
 		}
 	}
 
-	// Return true if transactions is verified
-	return true
+	// Return a nil proof with an error
+	return nil, nil, nil, fmt.Errorf("transaction does not exist")
 }
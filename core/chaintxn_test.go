@@ -0,0 +1,81 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+func Test_SignTransaction(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+
+	// Prune the body of the genesis block that created the output
+	address := testaddress()
+	for i := 0; i < MinPruneDepth; i++ {
+		chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+	chain.OpenPruning(MinPruneDepth)
+	if _, err := chain.FindTransaction(coinbase.ID); !errors.Is(err, ErrBlockPruned) {
+		t.Fatalf("FindTransaction() failed! expected: %v, got: %v", ErrBlockPruned, err)
+	}
+
+	// A transaction that spends the output is signed and verified from the utxo layer
+	txn := &Transaction{Inputs: TXIList{{ID: coinbase.ID, OutIndex: 0, PublicKey: w.PublicKey}}}
+	txn.Outputs = TXOList{*NewTXO(20, address)}
+	if err := chain.SignTransaction(txn, w.PrivateKey); err != nil {
+		t.Fatalf("SignTransaction() failed! %v", err)
+	}
+	if err := chain.VerifyTransaction(txn); err != nil {
+		t.Fatalf("VerifyTransaction() failed! %v", err)
+	}
+
+	// A transaction signed by another wallet fails verification
+	forged := &Transaction{Inputs: TXIList{{ID: coinbase.ID, OutIndex: 0, PublicKey: w.PublicKey}}}
+	forged.Outputs = txn.Outputs
+	if err := chain.SignTransaction(forged, wallet.NewWallet().PrivateKey); err != nil {
+		t.Fatalf("SignTransaction() failed! %v", err)
+	}
+	if err := chain.VerifyTransaction(forged); err == nil {
+		t.Fatalf("VerifyTransaction() failed! expected an error for a forged signature")
+	}
+
+	// A transaction that spends a missing output cannot be signed
+	missing := &Transaction{Inputs: TXIList{{ID: coinbase.ID, OutIndex: 1, PublicKey: w.PublicKey}}}
+	if err := chain.SignTransaction(missing, w.PrivateKey); err == nil {
+		t.Fatalf("SignTransaction() failed! expected an error for a missing output")
+	}
+}
+
+func Test_TransactionIndex(t *testing.T) {
+	t.Parallel()
+	chain, _ := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+
+	address := testaddress()
+	block := chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+
+	// The transactions of connected blocks are indexed with their block
+	if blockhash, err := chain.GetTxnBlock(block.TXList[0].ID); err != nil || !bytes.Equal(blockhash, block.BlockHash) {
+		t.Fatalf("GetTxnBlock() failed! expected: %x, got: %x %v", block.BlockHash, blockhash, err)
+	}
+	if txn, err := chain.FindTransaction(coinbase.ID); err != nil || !bytes.Equal(txn.ID, coinbase.ID) {
+		t.Fatalf("FindTransaction() failed! %v", err)
+	}
+
+	// Transactions that are not indexed cannot be found
+	chain.Index.DeleteKeyPrefix(utils.Txnprefix)
+	if _, err := chain.FindTransaction(coinbase.ID); err == nil {
+		t.Fatalf("FindTransaction() failed! expected an error for a transaction that is not indexed")
+	}
+
+	// The index is rebuilt from the blocks of the chain
+	chain.ReindexTxns()
+	if _, _, _, err := chain.ProveTransaction(coinbase.ID); err != nil {
+		t.Fatalf("ProveTransaction() failed! %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/merkle"
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// A method of BlockChain that connects a Block to the tip of the chain.
+//
+// The block is first written to the blocks bucket, where it remains unreferenced
+// until the chain state points at it. All the state changes of the block (utxos,
+// header history, chain head and chain height) are then committed in a single
+// batch on the state bucket. A crash at any point leaves the chain at either
+// the previous block or the new block, never in between.
+//
+// The filters and transactions of the block are indexed after it is connected.
+// A block whose indexes could not be written is indexed again with the next
+// block, or by CheckConsistency if the chain is reopened after a crash.
+func (chain *BlockChain) ConnectBlock(block *Block) error {
+	// Check that the block is at the next height
+	if block.BlockHeight != chain.ChainHeight {
+		return fmt.Errorf("block height %v does not extend chain height %v", block.BlockHeight, chain.ChainHeight)
+	}
+
+	// Check that the block links to the current chain head
+	if !bytes.Equal(block.Priori, chain.ChainHead) {
+		return fmt.Errorf("block priori does not match the chain head")
+	}
+
+	// Set the block to the blocks bucket
+	if err := chain.StoreBlock(block); err != nil {
+		return fmt.Errorf("block connection failed! error - %v", err)
+	}
+
+	// Determine the number of header history leaves after the block is connected
+	leaves := chain.History.Leaves + 1
+
+	// Define a batch on the state bucket
+	err := chain.State.Batch(func(batch persistence.Batch) error {
+		// Apply the transactions of the block to the utxo layer
+		if err := applyUTXOS(batch, block); err != nil {
+			return err
+		}
+
+		// Append the block to the header history staged on the batch
+		history := merkle.NewMMR(&historystore{store: batch}, chain.History.Leaves)
+		if _, err := history.Append(block.BlockHash); err != nil {
+			return err
+		}
+
+		// Set the number of header history leaves
+		if err := batch.SetKey(utils.MMRLeavesKey, utils.IntEncode(int(leaves))); err != nil {
+			return err
+		}
+
+		// Set the block hash as the chain head
+		if err := batch.SetKey(utils.ChainHeadKey, block.BlockHash); err != nil {
+			return err
+		}
+
+		// Set the chain height as the height after the block
+		return batch.SetKey(utils.ChainHeightKey, utils.IntEncode(block.BlockHeight+1))
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return fmt.Errorf("block connection failed! error - %v", err)
+	}
+
+	// Assign the hash of the block as the chain head
+	chain.ChainHead = block.BlockHash
+	// Assign the height after the block as the chain height
+	chain.ChainHeight = block.BlockHeight + 1
+	// Reopen the header history with the committed leaves
+	chain.History = merkle.NewMMR(&historystore{store: chain.State}, leaves)
+
+	// Index the filter and the transactions of the block (and any earlier unindexed blocks)
+	if err := chain.IndexBlocks(); err != nil {
+		return fmt.Errorf("block connected but not indexed! error - %v", err)
+	}
+
+	// Prune the block bodies that are deeper than the prune depth
+	if err := chain.PruneBlocks(); err != nil {
+		return err
+	}
+
+	// Return a nil error
+	return nil
+}
+
+// A method of BlockChain that submits a block received from a peer to the chain.
+// A block that extends the chain head is validated and connected. A block whose priori
+// block is not known is held as an orphan after its proof of work is checked and ErrOrphan
+// is returned. When a block is connected, the orphans that extend it are submitted again
+// recursively. Returns the blocks that were connected in order.
+func (chain *BlockChain) SubmitBlock(block *Block, peer string) ([]*Block, error) {
+	// Check that the block is not already on the chain
+	if _, err := chain.GetHeader(block.BlockHash); err == nil {
+		return nil, fmt.Errorf("block is already on the chain")
+	}
+
+	// Check if the block extends a block other than the chain head
+	if !bytes.Equal(block.Priori, chain.ChainHead) {
+		// Check if the priori block is on the chain
+		if _, err := chain.GetHeader(block.Priori); err == nil {
+			return nil, fmt.Errorf("block does not extend the chain head")
+		}
+
+		// Check the header and its proof of work before holding the block
+		if err := ValidateHeader(&block.BlockHeader, block.BlockHash, chain.Params.Difficulty); err != nil {
+			return nil, err
+		}
+
+		// Hold the block as an orphan until its priori block is connected
+		if err := chain.Orphans.Add(block.BlockHash, block, peer, block.Priori); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrOrphan
+	}
+
+	// Validate and connect the block
+	if err := chain.connectvalid(block); err != nil {
+		return nil, err
+	}
+
+	// Process the orphans of each connected block
+	connected := []*Block{block}
+	for index := 0; index < len(connected); index++ {
+		for _, orphan := range chain.Orphans.Resolve(connected[index].BlockHash) {
+			// Connect the orphan if it is valid, only one orphan can extend the block
+			if err := chain.connectvalid(orphan.Item); err == nil {
+				connected = append(connected, orphan.Item)
+			}
+		}
+	}
+
+	return connected, nil
+}
+
+// A method of BlockChain that validates a block and connects it to the chain
+func (chain *BlockChain) connectvalid(block *Block) error {
+	// Validate the block against the chain head
+	if err := chain.ValidateBlock(block); err != nil {
+		return err
+	}
+
+	// Connect the block to the chain
+	return chain.ConnectBlock(block)
+}
+
+// A method of BlockChain that returns the number of blocks on the chain whose filters
+// and transactions have been indexed. A missing or corrupt indexed height returns 0.
+func (chain *BlockChain) IndexedHeight() int {
+	// Get the indexed height from the index bucket
+	value, err := chain.Index.GetKey(utils.IndexedHeightKey)
+	if err != nil {
+		return 0
+	}
+
+	// Decode the indexed height
+	height, err := utils.IntDecode(value)
+	if err != nil {
+		return 0
+	}
+
+	return height
+}
+
+// A method of BlockChain that indexes the filters and transactions of the blocks on the chain
+// above its indexed height. The indexes of each block are written in a single batch along with
+// the indexed height after it, so that indexing resumes from the first block without indexes
+// after a failure or a crash. Blocks whose bodies have been pruned are not indexed.
+// Returns an error if a block cannot be retrieved or its indexes cannot be written.
+func (chain *BlockChain) IndexBlocks() error {
+	// Start from the indexed height or the lowest block with a body
+	for height := max(chain.IndexedHeight(), chain.PrunedHeight); height < chain.ChainHeight; height++ {
+		// Retrieve the block at the height from the header history
+		blockhash, err := chain.blockhashat(height)
+		if err != nil {
+			return err
+		}
+
+		block, err := chain.GetBlock(blockhash)
+		if err != nil {
+			return err
+		}
+
+		// Define a batch on the index bucket
+		err = chain.Index.Batch(func(batch persistence.Batch) error {
+			// Set the filter of the block
+			if err := indexfilter(batch, block); err != nil {
+				return err
+			}
+
+			// Set the block hash for each transaction of the block
+			if err := indextxns(batch, block); err != nil {
+				return err
+			}
+
+			// Set the indexed height as the height after the block
+			return batch.SetKey(utils.IndexedHeightKey, utils.IntEncode(height+1))
+		})
+
+		// Handle any potential error
+		if err != nil {
+			return fmt.Errorf("failed to index block at height %v! error - %v", height, err)
+		}
+	}
+
+	return nil
+}
+
+// A method of BlockChain that sets the indexed height to the chain height,
+// after the indexes of the chain have been rebuilt from all of its blocks
+func (chain *BlockChain) setindexed() error {
+	if err := chain.Index.SetKey(utils.IndexedHeightKey, utils.IntEncode(chain.ChainHeight)); err != nil {
+		return fmt.Errorf("failed to update indexed height! error - %v", err)
+	}
+
+	return nil
+}
+
+// A method of BlockChain that checks the consistency of the chain database on startup.
+// The chain head in the state bucket must refer to a block in the blocks bucket at the
+// height below the chain height. Derived data such as the header history, utxo layer
+// and block filters are rebuilt if they are behind the chain. Returns an error if the
+// state of the chain cannot be recovered from the data in the database.
+func (chain *BlockChain) CheckConsistency() error {
+	// Retrieve the header of the chain head
+	entry, err := chain.GetHeader(chain.ChainHead)
+	if err != nil {
+		return fmt.Errorf("chain head %x is missing from blocks! error - %v", chain.ChainHead, err)
+	}
+
+	// Check that the chain head is at the top of the chain
+	if entry.BlockHeight != chain.ChainHeight-1 {
+		return fmt.Errorf("chain head is at height %v but chain height is %v", entry.BlockHeight, chain.ChainHeight)
+	}
+
+	// Open the header history of the chain (reindexes if it is behind)
+	if err := chain.OpenHistory(); err != nil {
+		return err
+	}
+
+	// Check that the header history commits to the chain head
+	if _, err := chain.History.Root(); err != nil {
+		return fmt.Errorf("header history is corrupted! error - %v", err)
+	}
+
+	// Check if the utxo layer is empty (legacy databases did not apply blocks to it)
+	if chain.CountUTXOS() == 0 {
+		// Log the reindexing of the utxo layer
+		logrus.Info("reindexing utxos.")
+		// Reindex the utxo layer
+		if err := chain.ReindexUTXOS(); err != nil {
+			return err
+		}
+	}
+
+	// Check if the indexed height is missing (legacy databases did not track it) or above the
+	// chain. The indexes of such a chain are assumed to be complete and are checked at its head.
+	if _, err := chain.Index.GetKey(utils.IndexedHeightKey); err != nil || chain.IndexedHeight() > chain.ChainHeight {
+		if err := chain.setindexed(); err != nil {
+			return err
+		}
+	}
+
+	// Check if blocks were connected without being indexed (such as by a crash after connection)
+	if indexed := chain.IndexedHeight(); indexed < chain.ChainHeight {
+		// Log the indexing of the blocks
+		logrus.WithFields(logrus.Fields{"indexed": indexed, "height": chain.ChainHeight}).Info("indexing unindexed blocks.")
+		// Index the blocks above the indexed height
+		if err := chain.IndexBlocks(); err != nil {
+			return err
+		}
+	}
+
+	// Check if the chain head has been indexed with a filter (the bodies needed to
+	// reindex the filters of a pruned chain are gone, so its filters are left as is)
+	if _, err := chain.GetBlockFilter(chain.ChainHead); err != nil && !chain.IsPruned() {
+		// Log the reindexing of the filters
+		logrus.Info("reindexing block filters.")
+		// Reindex the block filters
+		if err := chain.ReindexFilters(); err != nil {
+			return err
+		}
+	}
+
+	// Check if the chain head has its transactions indexed (if it has a body)
+	if chain.PrunedHeight < chain.ChainHeight {
+		if head, err := chain.GetBlock(chain.ChainHead); err == nil {
+			if _, err := chain.GetTxnBlock(head.TXList[0].ID); err != nil {
+				// Log the reindexing of the transactions
+				logrus.Info("reindexing transactions.")
+				// Reindex the transactions
+				if err := chain.ReindexTxns(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Return a nil error
+	return nil
+}
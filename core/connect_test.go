@@ -0,0 +1,196 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/manishmeganathan/weave/merkle"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A function that returns a mined block with a set of transactions that extends the chain head
+func testnewblock(chain *BlockChain, txns []*Transaction) *Block {
+	items := make([]utils.GobEncodable, len(txns))
+	for i, txn := range txns {
+		items[i] = txn
+	}
+
+	merkletree := merkle.NewMerkleTree()
+	merkletree.BuildFull(items)
+
+	history, _ := chain.History.Root()
+	return NewBlock(merkletree, chain.ChainHead, history, chain.ChainHeight, testaddress(), chain.Params.Difficulty)
+}
+
+func Test_ConnectBlock(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	reward := NewCoinbaseTransaction(testaddress(), testparams.Reward)
+
+	// Blocks that do not extend the chain head are not connected
+	block := testnewblock(chain, []*Transaction{reward})
+	block.BlockHeight++
+	if err := chain.ConnectBlock(block); err == nil {
+		t.Fatalf("ConnectBlock() failed! expected an error for a block above the chain height")
+	}
+
+	// A block that spends an output twice fails to connect
+	head, height, leaves := chain.ChainHead, chain.ChainHeight, chain.History.Leaves
+	invalid := testnewblock(chain, []*Transaction{reward, testspend(w, coinbase.ID, 0, 20), testspend(w, coinbase.ID, 0, 10)})
+	if err := chain.ConnectBlock(invalid); err == nil {
+		t.Fatalf("ConnectBlock() failed! expected an error for a double spend")
+	}
+
+	// None of the state of the failed block is committed
+	if !bytes.Equal(chain.ChainHead, head) || chain.ChainHeight != height || chain.History.Leaves != leaves {
+		t.Fatalf("ConnectBlock() failed! chain state was changed by a failed block")
+	}
+	if value, _ := chain.State.GetKey(utils.ChainHeadKey); !bytes.Equal(value, head) {
+		t.Fatalf("ConnectBlock() failed! stored chain head was changed by a failed block")
+	}
+	if value, _ := chain.State.GetKey(utils.MMRLeavesKey); !bytes.Equal(value, utils.IntEncode(int(leaves))) {
+		t.Fatalf("ConnectBlock() failed! stored history leaves were changed by a failed block")
+	}
+	if utxos := chain.FetchUTXOS(w.GenerateAddress(byte(0x00)).PublicKeyHash); len(utxos) != 1 {
+		t.Fatalf("ConnectBlock() failed! expected: 1 utxo, got: %v", len(utxos))
+	}
+
+	// A valid block is connected after the failed block
+	valid := testnewblock(chain, []*Transaction{reward, testspend(w, coinbase.ID, 0, 20)})
+	if err := chain.ConnectBlock(valid); err != nil {
+		t.Fatalf("ConnectBlock() failed! %v", err)
+	}
+	if !bytes.Equal(chain.ChainHead, valid.BlockHash) || chain.ChainHeight != height+1 || chain.History.Leaves != leaves+1 {
+		t.Fatalf("ConnectBlock() failed! chain state was not updated")
+	}
+}
+
+// A function that deletes the indexes of the blocks above a height and sets the indexed height to it,
+// which leaves the index bucket as it would be if the blocks were connected but not indexed
+func testunindex(t *testing.T, chain *BlockChain, height int) {
+	for h := height; h < chain.ChainHeight; h++ {
+		hash, _ := chain.blockhashat(h)
+		block, err := chain.GetBlock(hash)
+		if err != nil {
+			t.Fatalf("GetBlock() failed! %v", err)
+		}
+
+		chain.Index.DeleteKey(filterkey(block.BlockHash))
+		for _, txn := range block.TXList {
+			chain.Index.DeleteKey(txnkey(txn.ID))
+		}
+	}
+
+	chain.Index.SetKey(utils.IndexedHeightKey, utils.IntEncode(height))
+}
+
+// A function that checks that the blocks of a chain have their filters and transactions indexed
+func testindexed(t *testing.T, chain *BlockChain) {
+	if chain.IndexedHeight() != chain.ChainHeight {
+		t.Fatalf("IndexBlocks() failed! expected indexed height: %v, got: %v", chain.ChainHeight, chain.IndexedHeight())
+	}
+
+	for h := 0; h < chain.ChainHeight; h++ {
+		hash, _ := chain.blockhashat(h)
+		block, _ := chain.GetBlock(hash)
+		if _, err := chain.GetBlockFilter(hash); err != nil {
+			t.Fatalf("IndexBlocks() failed! block filter at height %v is missing: %v", h, err)
+		}
+		if blockhash, err := chain.GetTxnBlock(block.TXList[0].ID); err != nil || !bytes.Equal(blockhash, hash) {
+			t.Fatalf("IndexBlocks() failed! transactions at height %v are not indexed: %v", h, err)
+		}
+	}
+}
+
+func Test_IndexBlocks(t *testing.T) {
+	t.Parallel()
+	options := ChainOptions{DataDir: t.TempDir(), Params: testparams, Coinbase: testaddress()}
+
+	chain, err := NewBlockChainWithOptions(options)
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	address := testaddress()
+	for i := 0; i < 4; i++ {
+		chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+	testindexed(t, chain)
+
+	// Blocks that were connected but not indexed are indexed with the next block
+	testunindex(t, chain, 2)
+	chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	testindexed(t, chain)
+
+	// A chain that crashed before indexing its head is indexed when it is reopened
+	testunindex(t, chain, chain.ChainHeight-1)
+	chain.CloseBuckets()
+
+	chain, err = NewBlockChainWithOptions(options)
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() reopen failed! %v", err)
+	}
+	defer chain.CloseBuckets()
+	testindexed(t, chain)
+
+	// A chain without an indexed height (legacy databases) is assumed to be indexed upto its head
+	chain.Index.DeleteKey(utils.IndexedHeightKey)
+	if err := chain.CheckConsistency(); err != nil {
+		t.Fatalf("CheckConsistency() failed! %v", err)
+	}
+	testindexed(t, chain)
+}
+
+func Test_CheckConsistency(t *testing.T) {
+	t.Parallel()
+	options := ChainOptions{DataDir: t.TempDir(), Params: testparams, Coinbase: testaddress()}
+
+	chain, err := NewBlockChainWithOptions(options)
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	address := testaddress()
+	var block *Block
+	for i := 0; i < 3; i++ {
+		block = chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+	head := chain.ChainHead
+	root, _ := chain.History.Root()
+	utxos := chain.CountUTXOS()
+
+	// Lose the derived state of the chain
+	chain.State.DeleteKey(utils.MMRLeavesKey)
+	chain.State.DeleteKeyPrefix(utils.UTXOprefix)
+	chain.Index.DeleteKeyPrefix(utils.Filterprefix)
+	chain.Index.DeleteKeyPrefix(utils.Txnprefix)
+	chain.CloseBuckets()
+
+	// The derived state is rebuilt from the blocks when the chain is reopened
+	chain, err = NewBlockChainWithOptions(options)
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() reopen failed! %v", err)
+	}
+
+	if reindexed, _ := chain.History.Root(); !bytes.Equal(reindexed, root) || chain.History.Leaves != 4 {
+		t.Fatalf("CheckConsistency() failed! header history was not rebuilt")
+	}
+	if chain.CountUTXOS() != utxos {
+		t.Fatalf("CheckConsistency() failed! expected: %v utxos, got: %v", utxos, chain.CountUTXOS())
+	}
+	if _, err := chain.GetBlockFilter(head); err != nil {
+		t.Fatalf("CheckConsistency() failed! block filters were not rebuilt: %v", err)
+	}
+	if blockhash, err := chain.GetTxnBlock(block.TXList[0].ID); err != nil || !bytes.Equal(blockhash, head) {
+		t.Fatalf("CheckConsistency() failed! transaction index was not rebuilt: %v", err)
+	}
+
+	// A chain head that does not match the chain height cannot be recovered
+	chain.State.SetKey(utils.ChainHeightKey, utils.IntEncode(10))
+	chain.CloseBuckets()
+
+	if _, err := NewBlockChainWithOptions(options); err == nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! expected an error for an inconsistent chain height")
+	}
+}
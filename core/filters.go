@@ -0,0 +1,156 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/filter"
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// A function that generates and returns the compact filter for a Block.
+// The filter is built over the public key hashes of all transaction outputs
+// and the outpoints of all transaction inputs (spent outputs) in the block.
+func NewBlockFilter(block *Block) *filter.Filter {
+	// Declare a slice of filter items
+	var items [][]byte
+
+	// Iterate over the transactions in the block
+	for _, txn := range block.TXList {
+		// Add the public key hash of each output
+		for _, output := range txn.Outputs {
+			items = append(items, output.PublicKeyHash)
+		}
+
+		// Check if the transaction is a coinbase transaction
+		if !txn.IsCoinbase() {
+			// Add the outpoint of each input
+			for _, input := range txn.Inputs {
+				items = append(items, Outpoint(input.ID, input.OutIndex))
+			}
+		}
+	}
+
+	// Build the filter with the block hash as the key
+	return filter.NewFilter(block.BlockHash, items)
+}
+
+// A function that returns the outpoint of a transaction output as a filter item.
+// The outpoint is the transaction ID followed by the big-endian output index.
+func Outpoint(txnid utils.Hash, outindex int) []byte {
+	// Encode the output index
+	encodedindex := make([]byte, 4)
+	binary.BigEndian.PutUint32(encodedindex, uint32(outindex))
+
+	// Construct the outpoint from the transaction ID and index
+	return append(append([]byte{}, txnid...), encodedindex...)
+}
+
+// A function that returns the index key for the filter of a given block hash
+func filterkey(blockhash utils.Hash) []byte {
+	return append(append([]byte{}, utils.Filterprefix...), blockhash...)
+}
+
+// A method of BlockChain that generates the filter for a Block and sets it to the index bucket
+func (chain *BlockChain) IndexBlockFilter(block *Block) error {
+	// Set the filter to the index bucket
+	if err := indexfilter(chain.Index, block); err != nil {
+		return fmt.Errorf("failed to add block filter to index! error - %v", err)
+	}
+
+	return nil
+}
+
+// A function that generates the filter for a block and sets it to a store (or batch)
+func indexfilter(store persistence.Writer, block *Block) error {
+	// Generate the filter for the block
+	blockfilter := NewBlockFilter(block)
+
+	// Set the filter to the store
+	return store.SetKey(filterkey(block.BlockHash), blockfilter.Serialize())
+}
+
+// A method of BlockChain that retrieves the filter for a given block hash from the index bucket
+func (chain *BlockChain) GetBlockFilter(blockhash utils.Hash) (*filter.Filter, error) {
+	// Get the filter gob data from the index bucket
+	filtergob, err := chain.Index.GetKey(filterkey(blockhash))
+	if err != nil {
+		// Return the error
+		return nil, fmt.Errorf("block filter retrieval failed! error - %v", err)
+	}
+
+	// Convert the gob data into a Filter object
+	blockfilter := &filter.Filter{}
+	blockfilter.Deserialize(filtergob)
+
+	// Return the filter
+	return blockfilter, nil
+}
+
+// A method of BlockChain that regenerates the filters for all the blocks on the chain.
+// Returns an error if the chain has pruned block bodies or a filter cannot be indexed.
+func (chain *BlockChain) ReindexFilters() error {
+	// Check that the chain has all its block bodies
+	if err := chain.RequireFullChain("reindex filters"); err != nil {
+		return err
+	}
+
+	// Delete all the filters stored on the index
+	chain.Index.DeleteKeyPrefix(utils.Filterprefix)
+
+	// Get an iterator for the blockchain and iterate over its block
+	iter := NewIterator(chain)
+	for {
+		// Get a block from the iterator
+		block := iter.Next()
+		// Index the filter of the block
+		if err := chain.IndexBlockFilter(block); err != nil {
+			return err
+		}
+
+		// Check if the block is the genesis block and break from the loop
+		if block.BlockHeight == 0 {
+			break
+		}
+	}
+
+	return nil
+}
+
+// A method of BlockChain that scans the filters of all blocks on the chain for a
+// set of items (public key hashes and outpoints) and returns the hashes of the
+// blocks whose filters match any of them, in order of height. The matched blocks
+// may include false positives but will never miss a block with any of the items.
+func (chain *BlockChain) ScanFilters(items [][]byte) []utils.Hash {
+	// Declare a slice of matched block hashes
+	var matches []utils.Hash
+
+	// Iterate over the blocks of the chain from the head backwards
+	cursor := chain.ChainHead
+	for height := chain.ChainHeight - 1; height >= 0; height-- {
+		// Retrieve the filter for the block
+		blockfilter, err := chain.GetBlockFilter(cursor)
+		if err != nil {
+			// Log a fatal error
+			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to scan block filters.")
+		}
+
+		// Check if the filter matches any of the items
+		if blockfilter.MatchAny(cursor, items) {
+			matches = append([]utils.Hash{cursor}, matches...)
+		}
+
+		// Retrieve the block header to move the cursor to its priori
+		entry, err := chain.GetHeader(cursor)
+		if err != nil {
+			// Log a fatal error
+			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to scan block filters.")
+		}
+		cursor = entry.Priori
+	}
+
+	// Return the matched block hashes
+	return matches
+}
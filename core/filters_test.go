@@ -0,0 +1,51 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+func Test_BlockFilters(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+
+	// Connect a block that spends the genesis output
+	address := testaddress()
+	spend := testspend(w, coinbase.ID, 0, 20)
+	block := chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward), spend}, address)
+
+	// Connected blocks are indexed with a filter that matches their outputs and outpoints
+	blockfilter, err := chain.GetBlockFilter(block.BlockHash)
+	if err != nil {
+		t.Fatalf("GetBlockFilter() failed! %v", err)
+	}
+	if !blockfilter.Match(block.BlockHash, address.PublicKeyHash) || !blockfilter.Match(block.BlockHash, Outpoint(coinbase.ID, 0)) {
+		t.Fatalf("IndexBlockFilter() failed! expected the filter to match the outputs and outpoints of the block")
+	}
+	if !bytes.Equal(blockfilter.Serialize(), NewBlockFilter(block).Serialize()) {
+		t.Fatalf("IndexBlockFilter() failed! expected the indexed filter to match the generated filter")
+	}
+
+	// The filters are scanned for the blocks that have an item
+	matches := chain.ScanFilters([][]byte{address.PublicKeyHash})
+	if len(matches) != 1 || !bytes.Equal(matches[0], block.BlockHash) {
+		t.Fatalf("ScanFilters() failed! expected: %x, got: %x", block.BlockHash, matches)
+	}
+
+	// Filters that are missing from the index are not found
+	chain.Index.DeleteKeyPrefix(utils.Filterprefix)
+	if _, err := chain.GetBlockFilter(block.BlockHash); err == nil {
+		t.Fatalf("GetBlockFilter() failed! expected an error for a filter that is not indexed")
+	}
+
+	// The filters of every block are regenerated
+	chain.ReindexFilters()
+	for _, blockhash := range []utils.Hash{block.BlockHash, block.Priori} {
+		if _, err := chain.GetBlockFilter(blockhash); err != nil {
+			t.Fatalf("ReindexFilters() failed! %v", err)
+		}
+	}
+}
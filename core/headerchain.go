@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/merkle"
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// A structure that represents a header-only chain for light nodes.
+// A HeaderChain stores only the headers of the blocks on the chain and
+// verifies the inclusion of transactions with merkle proofs from full nodes.
+type HeaderChain struct {
+	// Represents the database bucket for the chain headers
+	Headers persistence.Store
+
+	// Represents the hash of the latest header
+	ChainHead utils.Hash
+
+	// Represents the number of headers on the chain (last header height+1)
+	ChainHeight int
+
+	// Represents the header history MMR of the chain
+	History *merkle.MMR
+
+	// Represents the consensus parameters of the chain
+	Params ChainParams
+}
+
+// A constructor function that creates a new HeaderChain object.
+// Checks if the headers database is already configured and initializes
+// the object based on that, otherwise configures an empty header chain.
+// An empty header chain expects the genesis header as its first header.
+func NewHeaderChain() *HeaderChain {
+	// Create a null header chain with the default params
+	headerchain := HeaderChain{Params: DefaultChainParams()}
+
+	// Check if a headers db already exists
+	exists := persistence.CheckHeaderDatabase()
+	// Open the database client for the headers bucket
+	headerchain.Headers = persistence.NewDatabaseBucket(persistence.HEADERS)
+
+	// Check if the headers db has a chain on it
+	if exists {
+		// Migrate the headers database to the latest schema version
+		if err := persistence.Migrate(headerchain.Headers, headerchain.migrations()); err != nil {
+			// Log a fatal error
+			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to migrate headers.")
+		}
+
+		// Get the chain head from the headers bucket
+		chainhead, err := headerchain.Headers.GetKey(utils.ChainHeadKey)
+		if err == nil {
+			// Get the chain height from the headers bucket
+			chainheight, err := headerchain.Headers.GetKey(utils.ChainHeightKey)
+			if err != nil {
+				// Log a fatal error
+				logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to get chain height from headers.")
+			}
+
+			// Decode the chain height
+			height, err := utils.IntDecode(chainheight)
+			if err != nil {
+				// Log a fatal error
+				logrus.WithFields(logrus.Fields{"error": err}).Fatalln("chain height in headers is corrupt.")
+			}
+
+			// Assign the current chain head and height
+			headerchain.ChainHead = chainhead
+			headerchain.ChainHeight = height
+		}
+	} else {
+		// Set the schema version of the headers database to the latest version
+		if err := persistence.SetSchemaVersion(headerchain.Headers, persistence.LatestVersion(headerchain.migrations())); err != nil {
+			// Log a fatal error
+			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to set headers schema version.")
+		}
+	}
+
+	// Open the header history MMR with a leaf for every header
+	headerchain.History = merkle.NewMMR(&historystore{store: headerchain.Headers}, uint64(headerchain.ChainHeight))
+
+	// Return the header chain
+	return &headerchain
+}
+
+// A constructor function that creates an empty HeaderChain held in memory with
+// the given consensus parameters. The config file is not accessed, which allows
+// a light client to be run without a headers database (such as in tests).
+func NewMemoryHeaderChain(params ChainParams) *HeaderChain {
+	// Create a header chain on an in-memory store
+	headerchain := HeaderChain{Headers: persistence.NewMemoryStore(), Params: params}
+	// Open the empty header history MMR
+	headerchain.History = merkle.NewMMR(&historystore{store: headerchain.Headers}, 0)
+
+	return &headerchain
+}
+
+// A method of HeaderChain that validates a header entry and adds it to the chain.
+// The header must satisfy its proof of work, extend the current chain head and
+// commit to the header history of the chain. Returns an error if it is invalid.
+func (hc *HeaderChain) AddHeader(entry *HeaderEntry) error {
+	// Check that the header is at the next height
+	if entry.BlockHeight != hc.ChainHeight {
+		return fmt.Errorf("header height %v does not extend chain height %v", entry.BlockHeight, hc.ChainHeight)
+	}
+
+	// Check that the header links to the current chain head
+	if !bytes.Equal(entry.Priori, hc.ChainHead) {
+		return fmt.Errorf("header priori does not match the chain head")
+	}
+
+	// Validate the proof of work of the header
+	if err := ValidateHeader(&entry.BlockHeader, entry.BlockHash, hc.Params.Difficulty); err != nil {
+		return err
+	}
+
+	// Check that the header commits to the header history of the chain
+	history, err := hc.History.Root()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(entry.HistoryRoot, history) {
+		return fmt.Errorf("header history root does not match the chain history")
+	}
+
+	// Determine the number of header history leaves after the header is added
+	leaves := hc.History.Leaves + 1
+
+	// Define a batch on the headers bucket, so that a header is either
+	// added along with its history and the chain head or not at all
+	err = hc.Headers.Batch(func(batch persistence.Batch) error {
+		// Set the header entry to the headers bucket
+		if err := batch.SetKey(headerkey(entry.BlockHash), entry.Serialize()); err != nil {
+			return err
+		}
+
+		// Append the header to the header history staged on the batch
+		history := merkle.NewMMR(&historystore{store: batch}, hc.History.Leaves)
+		if _, err := history.Append(entry.BlockHash); err != nil {
+			return err
+		}
+
+		// Set the header hash as the chain head
+		if err := batch.SetKey(utils.ChainHeadKey, entry.BlockHash); err != nil {
+			return err
+		}
+
+		// Set the chain height as the height after the header
+		return batch.SetKey(utils.ChainHeightKey, utils.IntEncode(entry.BlockHeight+1))
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return fmt.Errorf("header addition failed! error - %v", err)
+	}
+
+	// Assign the hash of the header as the chain head
+	hc.ChainHead = entry.BlockHash
+	// Assign the height after the header as the chain height
+	hc.ChainHeight = entry.BlockHeight + 1
+	// Reopen the header history with the committed leaves
+	hc.History = merkle.NewMMR(&historystore{store: hc.Headers}, leaves)
+
+	// Return a nil error
+	return nil
+}
+
+// A method of HeaderChain that retrieves the HeaderEntry for a given block hash
+func (hc *HeaderChain) GetHeader(blockhash utils.Hash) (*HeaderEntry, error) {
+	// Get the header entry gob data from the headers bucket
+	entrygob, err := hc.Headers.GetKey(headerkey(blockhash))
+	if err != nil {
+		// Return the error
+		return nil, fmt.Errorf("header retrieval failed! error - %v", err)
+	}
+
+	// Convert the gob data into a HeaderEntry object
+	entry := NullHeaderEntry()
+	entry.Deserialize(entrygob)
+
+	// Return the header entry
+	return entry, nil
+}
+
+// A method of HeaderChain that verifies that a transaction is included in the block
+// with the given hash using a merkle proof obtained from a full node. The header of
+// the block must already be on the header chain. Returns an error if it is not included.
+func (hc *HeaderChain) VerifyInclusion(txn *Transaction, blockhash utils.Hash, proof *merkle.MerkleProof) error {
+	// Retrieve the header entry of the block
+	entry, err := hc.GetHeader(blockhash)
+	if err != nil {
+		return err
+	}
+
+	// Verify the proof against the merkle root of the header
+	if !proof.Verify(txn, entry.MerkleRoot) {
+		return fmt.Errorf("transaction is not included in block")
+	}
+
+	// Return a nil error
+	return nil
+}
+
+// A method of HeaderChain that closes the client for the headers database bucket.
+func (hc *HeaderChain) Close() {
+	hc.Headers.Close()
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A function that returns an empty in-memory header chain
+func testheaderchain() *HeaderChain {
+	return NewMemoryHeaderChain(testparams)
+}
+
+func Test_HeaderChain(t *testing.T) {
+	t.Parallel()
+	chain := testchain(t)
+
+	address := testaddress()
+	for i := 0; i < 3; i++ {
+		chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+	headers := chain.CollectHeaders(0, chain.ChainHeight)
+
+	// Headers that do not start at the genesis are rejected
+	hc := testheaderchain()
+	defer hc.Close()
+	if err := hc.AddHeader(headers[1]); err == nil {
+		t.Fatalf("AddHeader() failed! expected an error for a header above the chain height")
+	}
+
+	// The headers of the chain are added in order
+	for _, entry := range headers {
+		if err := hc.AddHeader(entry); err != nil {
+			t.Fatalf("AddHeader() failed! %v", err)
+		}
+	}
+	if hc.ChainHeight != chain.ChainHeight || string(hc.ChainHead) != string(chain.ChainHead) {
+		t.Fatalf("AddHeader() failed! expected height: %v, got: %v", chain.ChainHeight, hc.ChainHeight)
+	}
+
+	// The chain head and height are committed to the headers bucket
+	height, _ := hc.Headers.GetKey(utils.ChainHeightKey)
+	head, _ := hc.Headers.GetKey(utils.ChainHeadKey)
+	if decoded, _ := utils.IntDecode(height); decoded != chain.ChainHeight || string(head) != string(chain.ChainHead) {
+		t.Fatalf("AddHeader() failed! expected height: %v, got: %v", chain.ChainHeight, decoded)
+	}
+
+	// Headers that do not match their proof of work are rejected
+	block := chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	tampered := *block.GenerateHeaderEntry()
+	tampered.HistoryRoot = headers[0].BlockHash
+	if err := hc.AddHeader(&tampered); err == nil {
+		t.Fatalf("AddHeader() failed! expected an error for a tampered header")
+	}
+
+	// Transactions are verified against the headers with proofs from the chain
+	genesis, _ := chain.GetBlock(headers[0].BlockHash)
+	txn, blockhash, proof, err := chain.ProveTransaction(genesis.TXList[0].ID)
+	if err != nil {
+		t.Fatalf("ProveTransaction() failed! %v", err)
+	}
+	if err := hc.VerifyInclusion(txn, blockhash, proof); err != nil {
+		t.Fatalf("VerifyInclusion() failed! %v", err)
+	}
+	if err := hc.VerifyInclusion(txn, headers[1].BlockHash, proof); err == nil {
+		t.Fatalf("VerifyInclusion() failed! expected an error for the wrong block")
+	}
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/merkle"
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// A structure that represents the node store of the header
+// history MMR that is backed by a database store
+type historystore struct {
+	// Represents the reference to the store (or batch) that holds the history
+	store persistence.Batch
+}
+
+// A method of historystore that returns the hash of the node at a given position
+func (hs *historystore) GetNode(pos uint64) (utils.Hash, error) {
+	return hs.store.GetKey(historykey(pos))
+}
+
+// A method of historystore that sets the hash of the node at a given position
+func (hs *historystore) SetNode(pos uint64, hash utils.Hash) error {
+	return hs.store.SetKey(historykey(pos), hash)
+}
+
+// A function that returns the state key for the header history node at a given position
+func historykey(pos uint64) []byte {
+	// Encode the position as a fixed width big-endian integer
+	encodedpos := make([]byte, 8)
+	binary.BigEndian.PutUint64(encodedpos, pos)
+
+	// Construct the key by adding the mmr key prefix
+	return append(append([]byte{}, utils.MMRprefix...), encodedpos...)
+}
+
+// A method of BlockChain that returns the hash of the block at a given height on the chain.
+// The leaves of the header history are the block hashes in order of height, so the hash is
+// read from the leaf at the height without iterating over the chain from its head.
+func (chain *BlockChain) blockhashat(height int) (utils.Hash, error) {
+	// Check that the height has a leaf in the header history
+	if height < 0 || uint64(height) >= chain.History.Leaves {
+		return nil, fmt.Errorf("no block at height %v in the header history", height)
+	}
+
+	return (&historystore{store: chain.State}).GetNode(merkle.LeafPosition(uint64(height)))
+}
+
+// A method of BlockChain that opens the header history MMR from the state bucket.
+// The history is reindexed if it does not contain a leaf for every block on the chain.
+// Returns an error if the history needs to be reindexed and cannot be.
+func (chain *BlockChain) OpenHistory() error {
+	// Get the number of history leaves from the state bucket
+	// A missing or corrupt number of leaves reindexes the history
+	leaves := 0
+	if value, err := chain.State.GetKey(utils.MMRLeavesKey); err == nil {
+		leaves, _ = utils.IntDecode(value)
+	}
+
+	// Create the header history MMR
+	chain.History = merkle.NewMMR(&historystore{store: chain.State}, uint64(leaves))
+
+	// Check if the history is behind the chain
+	if leaves != chain.ChainHeight {
+		// Log the reindexing of the history
+		logrus.WithFields(logrus.Fields{"leaves": leaves, "height": chain.ChainHeight}).Info("reindexing header history.")
+		// Reindex the header history
+		return chain.ReindexHistory()
+	}
+
+	return nil
+}
+
+// A method of BlockChain that appends a block hash to the header history MMR and
+// updates the number of history leaves in the state bucket. The nodes of the leaf
+// and the number of leaves are written in a single batch. Returns an error if the
+// batch cannot be committed, in which case the history is left unchanged.
+func (chain *BlockChain) AppendHistory(blockhash utils.Hash) error {
+	// Determine the number of header history leaves after the append
+	leaves := chain.History.Leaves + 1
+
+	// Define a batch on the state bucket
+	err := chain.State.Batch(func(batch persistence.Batch) error {
+		// Append the block hash as a leaf of the MMR staged on the batch
+		history := merkle.NewMMR(&historystore{store: batch}, chain.History.Leaves)
+		if _, err := history.Append(blockhash); err != nil {
+			return err
+		}
+
+		// Set the number of history leaves
+		return batch.SetKey(utils.MMRLeavesKey, utils.IntEncode(int(leaves)))
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return fmt.Errorf("failed to append to header history! error - %v", err)
+	}
+
+	// Reopen the header history with the committed leaves
+	chain.History = merkle.NewMMR(&historystore{store: chain.State}, leaves)
+	return nil
+}
+
+// A method of BlockChain that rebuilds the header history MMR from all the blocks on
+// the chain. The hashes of the blocks are collected before the history is deleted and
+// each block is appended in its own batch, so an interrupted reindex is started again
+// when the history is opened. Returns an error if a header is missing or cannot be appended.
+func (chain *BlockChain) ReindexHistory() error {
+	// Collect the hashes of all blocks on the chain in order of height
+	hashes, err := chain.blockhashes()
+	if err != nil {
+		return fmt.Errorf("failed to reindex header history! error - %v", err)
+	}
+
+	// Delete the number of leaves and all the history nodes stored on the database
+	if err := chain.State.DeleteKey(utils.MMRLeavesKey); err != nil {
+		return fmt.Errorf("failed to reset header history! error - %v", err)
+	}
+	chain.State.DeleteKeyPrefix(utils.MMRprefix)
+
+	// Reset the header history MMR
+	chain.History = merkle.NewMMR(&historystore{store: chain.State}, 0)
+
+	// Append the hashes in order of height
+	for _, hash := range hashes {
+		if err := chain.AppendHistory(hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// A method of BlockChain that generates a proof that the Block with the given hash
+// is an ancestor of the chain head. The proof verifies against the HistoryRoot of
+// the chain head, which commits to every header that precedes it on the chain.
+func (chain *BlockChain) ProveAncestor(blockhash utils.Hash) (*merkle.MMRProof, error) {
+	// Check that the block is not the chain head itself
+	if bytes.Equal(blockhash, chain.ChainHead) {
+		return nil, fmt.Errorf("block is the chain head")
+	}
+
+	// Retrieve the header of the block from the blocks bucket
+	entry, err := chain.GetHeader(blockhash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate a proof for the block against all the headers before the chain head
+	return chain.History.GenerateProof(uint64(entry.BlockHeight), uint64(chain.ChainHeight-1))
+}
+
+// A function that verifies a proof that a given header is
+// an ancestor of a given tip header on the same chain.
+func VerifyAncestor(header, tip *BlockHeader, proof *merkle.MMRProof) bool {
+	return proof.Verify(header.GenerateHash(), tip.HistoryRoot)
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+func Test_ProveAncestor(t *testing.T) {
+	t.Parallel()
+	chain := testchain(t)
+
+	address := testaddress()
+	for i := 0; i < 5; i++ {
+		chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+	headers := chain.CollectHeaders(0, chain.ChainHeight)
+	tip := &headers[len(headers)-1].BlockHeader
+
+	// Every block before the chain head is proven against the HistoryRoot of the head
+	for _, entry := range headers[:len(headers)-1] {
+		proof, err := chain.ProveAncestor(entry.BlockHash)
+		if err != nil {
+			t.Fatalf("ProveAncestor() failed! %v", err)
+		}
+		if !VerifyAncestor(&entry.BlockHeader, tip, proof) {
+			t.Fatalf("VerifyAncestor() failed! expected the block at height %v to be an ancestor", entry.BlockHeight)
+		}
+	}
+
+	// The chain head and unknown blocks cannot be proven
+	if _, err := chain.ProveAncestor(chain.ChainHead); err == nil {
+		t.Fatalf("ProveAncestor() failed! expected an error for the chain head")
+	}
+	if _, err := chain.ProveAncestor([]byte{1, 2, 3}); err == nil {
+		t.Fatalf("ProveAncestor() failed! expected an error for an unknown block")
+	}
+
+	proof, err := chain.ProveAncestor(headers[1].BlockHash)
+	if err != nil {
+		t.Fatalf("ProveAncestor() failed! %v", err)
+	}
+
+	// A proof does not verify for another header of the chain
+	if VerifyAncestor(&headers[2].BlockHeader, tip, proof) {
+		t.Fatalf("VerifyAncestor() failed! expected a proof for another header to be rejected")
+	}
+
+	// A proof does not verify against a tip that does not commit to the same history
+	if VerifyAncestor(&headers[1].BlockHeader, &headers[3].BlockHeader, proof) {
+		t.Fatalf("VerifyAncestor() failed! expected a proof against another tip to be rejected")
+	}
+
+	// A forged proof with a tampered sibling does not verify
+	forged := *proof
+	forged.Siblings = append([]utils.Hash{}, proof.Siblings...)
+	forged.Siblings[0] = bytes.Repeat([]byte{0xff}, len(proof.Siblings[0]))
+	if VerifyAncestor(&headers[1].BlockHeader, tip, &forged) {
+		t.Fatalf("VerifyAncestor() failed! expected a forged proof to be rejected")
+	}
+
+	// A forged proof that moves the leaf to another position does not verify
+	moved := *proof
+	moved.LeafIndex = 2
+	if VerifyAncestor(&headers[1].BlockHeader, tip, &moved) {
+		t.Fatalf("VerifyAncestor() failed! expected a proof for another position to be rejected")
+	}
+}
+
+func Test_ReindexHistory(t *testing.T) {
+	t.Parallel()
+	chain := testchain(t)
+
+	address := testaddress()
+	for i := 0; i < 4; i++ {
+		chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+	root, _ := chain.History.Root()
+
+	// The reindexed history has the same root as the history built by connecting blocks
+	if err := chain.ReindexHistory(); err != nil {
+		t.Fatalf("ReindexHistory() failed! %v", err)
+	}
+	if reindexed, _ := chain.History.Root(); !bytes.Equal(reindexed, root) || int(chain.History.Leaves) != chain.ChainHeight {
+		t.Fatalf("ReindexHistory() failed! expected: %x, got: %x", root, reindexed)
+	}
+
+	// A history that is behind the chain is reindexed when it is opened
+	chain.State.SetKey(utils.MMRLeavesKey, utils.IntEncode(2))
+	if err := chain.OpenHistory(); err != nil {
+		t.Fatalf("OpenHistory() failed! %v", err)
+	}
+	if reopened, _ := chain.History.Root(); !bytes.Equal(reopened, root) {
+		t.Fatalf("OpenHistory() failed! expected: %x, got: %x", root, reopened)
+	}
+
+	// A history cannot be reindexed if a header of the chain is missing
+	chain.Blocks.DeleteKey(headerkey(chain.ChainHead))
+	chain.headers = newheadercache()
+	if err := chain.ReindexHistory(); err == nil {
+		t.Fatalf("ReindexHistory() failed! expected an error for a missing header")
+	}
+}
@@ -1,10 +1,6 @@
 package core
 
 import (
-	"fmt"
-
-	"github.com/dgraph-io/badger"
-	"github.com/manishmeganathan/weave/persistence"
 	"github.com/sirupsen/logrus"
 )
 
@@ -12,8 +8,8 @@ import (
 type BlockChainIterator struct {
 	// Represents the hash of the block that the iterator is currently on
 	Cursor []byte
-	// Represents the reference to the chain blocks database bucket
-	Blocks *persistence.DatabaseBucket
+	// Represents the reference to the chain being iterated over
+	Chain *BlockChain
 }
 
 // A constructor function that generates and returns an iterator for the BlockChain
@@ -21,44 +17,15 @@ func NewIterator(chain *BlockChain) *BlockChainIterator {
 	// Assign the values of the BlockChainIterator from the chain and return it
 	return &BlockChainIterator{
 		Cursor: chain.ChainHead,
-		Blocks: chain.Blocks,
+		Chain:  chain,
 	}
 }
 
 // A method of BlockChainIterator that iterates over chain and returns the
 // next block on the chain (backwards) from the chain DB and returns it
 func (iter *BlockChainIterator) Next() *Block {
-	// Create null Block object
-	block := NullBlock()
-
-	// Define a View Transaction on the BadgerDB
-	err := iter.Blocks.Client.View(func(txn *badger.Txn) error {
-
-		// Get the block item for the current hash of the iterator
-		item, err := txn.Get(iter.Cursor)
-		// Return any potential error
-		if err != nil {
-			return fmt.Errorf("block item retrieval failed! error - %v", err)
-		}
-
-		// Declare a slice of bytes for the gob of block data
-		var blockgob []byte
-		// Retrieve the value of the gob data
-		if err = item.Value(func(val []byte) error {
-			blockgob = val
-			return nil
-
-		}); err != nil {
-			// Return any potential error
-			return fmt.Errorf("block item value retrival failed! error - %v", err)
-		}
-
-		// Convert the block gob data into a Block object
-		block.Deserialize(blockgob)
-		// Return any potential error
-		return err
-	})
-
+	// Retrieve the block for the current hash of the iterator
+	block, err := iter.Chain.GetBlock(iter.Cursor)
 	// Handle any potential error
 	if err != nil {
 		// Log a fatal error
@@ -70,3 +37,20 @@ func (iter *BlockChainIterator) Next() *Block {
 	// Return the block
 	return block
 }
+
+// A method of BlockChainIterator that iterates over chain and returns the next
+// header entry on the chain (backwards) without retrieving the block body
+func (iter *BlockChainIterator) NextHeader() *HeaderEntry {
+	// Retrieve the header entry for the current hash of the iterator
+	entry, err := iter.Chain.GetHeader(iter.Cursor)
+	// Handle any potential error
+	if err != nil {
+		// Log a fatal error
+		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to iterate over chain headers.")
+	}
+
+	// Update the iterator's cursor to the hash of block before the current block
+	iter.Cursor = entry.BlockHeader.Priori
+	// Return the header entry
+	return entry
+}
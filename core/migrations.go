@@ -0,0 +1,130 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A method of BlockChain that returns the migrations of the chain database.
+// Each change to the on-disk layout of the chain adds a migration with the next
+// schema version. New chains are created at the latest schema version and existing
+// chains are migrated step by step to the latest schema version when they are opened.
+func (chain *BlockChain) migrations() []persistence.Migration {
+	return []persistence.Migration{
+		{Version: 1, Description: "store block headers and bodies under separate keys", Apply: chain.migrateBlockLayout},
+		{Version: 2, Description: "store numeric state as fixed width integers", Apply: func() error {
+			return migrateIntegers(chain.State, 2, utils.ChainHeightKey, utils.MMRLeavesKey, utils.PrunedHeightKey)
+		}},
+	}
+}
+
+// A method of HeaderChain that returns the migrations of the headers database.
+// Works like the migrations of the chain database, with its own schema versions.
+func (hc *HeaderChain) migrations() []persistence.Migration {
+	return []persistence.Migration{
+		{Version: 1, Description: "store numeric state as fixed width integers", Apply: func() error {
+			return migrateIntegers(hc.Headers, 1, utils.ChainHeightKey)
+		}},
+	}
+}
+
+// A function that migrates the numeric values of the given keys in a store from the
+// legacy hex encoding of their decimal string to fixed width big-endian integers.
+// The values are converted in a single batch along with the schema version, because
+// a legacy value cannot always be told apart from a converted one. Missing keys are skipped.
+func migrateIntegers(store persistence.Store, version int, keys ...[]byte) error {
+	return store.Batch(func(batch persistence.Batch) error {
+		// Iterate over the keys
+		for _, key := range keys {
+			// Get the legacy value of the key
+			value, err := batch.GetKey(key)
+			if errors.Is(err, persistence.ErrKeyNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			// Decode the legacy value
+			number, err := utils.HexDecode(value)
+			if err != nil {
+				return fmt.Errorf("legacy value of %s is corrupt! error - %v", key, err)
+			}
+
+			// Set the value as a fixed width integer
+			if err := batch.SetKey(key, utils.IntEncode(number)); err != nil {
+				return err
+			}
+		}
+
+		// Set the schema version along with the converted values
+		return persistence.SetSchemaVersion(batch, version)
+	})
+}
+
+// A method of BlockChain that migrates the blocks bucket from the legacy layout
+// where each whole block is stored under its hash to separate header and body keys.
+// The migration walks the chain from the head and converts every block that is still
+// stored with the legacy layout, following the header of blocks that were already
+// converted. The legacy keys are only deleted after the whole walk has succeeded,
+// so a migration that is interrupted is completed when the chain is opened again.
+func (chain *BlockChain) migrateBlockLayout() error {
+	// Collect the keys of the converted legacy blocks
+	legacy := make([][]byte, 0)
+
+	// Iterate over the blocks from the chain head backwards
+	cursor := chain.ChainHead
+	for {
+		// Get the legacy block gob data from the blocks bucket
+		blockgob, err := chain.Blocks.GetKey(cursor)
+		if err != nil {
+			// Get the header of a block that was already converted
+			entry, err := chain.GetHeader(cursor)
+			if err != nil {
+				return fmt.Errorf("block %x is missing from the blocks bucket! error - %v", cursor, err)
+			}
+
+			// Check if the block is the genesis block and break from the loop
+			if entry.BlockHeight == 0 {
+				break
+			}
+
+			// Move the cursor to the previous block
+			cursor = entry.Priori
+			continue
+		}
+
+		// Convert the block gob data into a Block object
+		block := NullBlock()
+		block.Deserialize(blockgob)
+
+		// Store the block with separate header and body keys
+		if err := chain.StoreBlock(block); err != nil {
+			return err
+		}
+
+		// Add the legacy block key to the keys to delete
+		legacy = append(legacy, cursor)
+
+		// Check if the block is the genesis block and break from the loop
+		if block.BlockHeight == 0 {
+			break
+		}
+
+		// Move the cursor to the previous block
+		cursor = block.Priori
+	}
+
+	// Delete the legacy block keys after every block has been converted
+	return batchwrite(chain.Blocks, len(legacy), func(batch persistence.Batch, start, end int) error {
+		for _, key := range legacy[start:end] {
+			if err := batch.DeleteKey(key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+func Test_MigrateIntegers(t *testing.T) {
+	t.Parallel()
+	datadir := t.TempDir()
+
+	chain, err := NewBlockChainWithOptions(ChainOptions{DataDir: datadir, Params: testparams, Coinbase: testaddress()})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	address := testaddress()
+	chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	chain.CloseBuckets()
+
+	// Rewrite the numeric state with the legacy encoding at schema version 1
+	state, err := persistence.OpenBadgerStore(persistence.STATE, filepath.Join(datadir, string(persistence.STATE)))
+	if err != nil {
+		t.Fatalf("OpenBadgerStore() failed! %v", err)
+	}
+	state.SetKey(utils.ChainHeightKey, utils.HexEncode(2))
+	state.SetKey(utils.MMRLeavesKey, utils.HexEncode(2))
+	persistence.SetSchemaVersion(state, 1)
+	state.Close()
+
+	chain, err = NewBlockChainWithOptions(ChainOptions{DataDir: datadir, Params: testparams})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() migration failed! %v", err)
+	}
+	defer chain.CloseBuckets()
+
+	if chain.ChainHeight != 2 || chain.History.Leaves != 2 {
+		t.Fatalf("migrateIntegers() failed! expected: height 2, got: height %v with %v leaves", chain.ChainHeight, chain.History.Leaves)
+	}
+	if value, _ := chain.State.GetKey(utils.ChainHeightKey); !bytes.Equal(value, utils.IntEncode(2)) {
+		t.Fatalf("migrateIntegers() failed! expected: %v, got: %v", utils.IntEncode(2), value)
+	}
+	if version, _ := persistence.SchemaVersion(chain.State); version != 2 {
+		t.Fatalf("SchemaVersion() failed! expected: 2, got: %v", version)
+	}
+}
+
+func Test_MigrateBlockLayout(t *testing.T) {
+	t.Parallel()
+	datadir := t.TempDir()
+
+	chain, err := NewBlockChainWithOptions(ChainOptions{DataDir: datadir, Params: testparams, Coinbase: testaddress()})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	address := testaddress()
+	blocks := []*Block{}
+	for i := 0; i < 3; i++ {
+		blocks = append(blocks, chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address))
+	}
+	genesis, _ := chain.GetBlock(blocks[0].Priori)
+	blocks = append([]*Block{genesis}, blocks...)
+	chain.CloseBuckets()
+
+	// Rewrite the blocks below the head with the legacy layout, as left by a migration
+	// that was interrupted after converting the chain head and deleting its legacy key
+	store, err := persistence.OpenBadgerStore(persistence.BLOCKS, filepath.Join(datadir, string(persistence.BLOCKS)))
+	if err != nil {
+		t.Fatalf("OpenBadgerStore() failed! %v", err)
+	}
+	for _, block := range blocks[:3] {
+		store.SetKey(block.BlockHash, block.Serialize())
+		store.DeleteKey(headerkey(block.BlockHash))
+		store.DeleteKey(bodykey(block.BlockHash))
+	}
+	store.Close()
+
+	// Rewrite the numeric state with the legacy encoding at schema version 0
+	state, err := persistence.OpenBadgerStore(persistence.STATE, filepath.Join(datadir, string(persistence.STATE)))
+	if err != nil {
+		t.Fatalf("OpenBadgerStore() failed! %v", err)
+	}
+	state.SetKey(utils.ChainHeightKey, utils.HexEncode(4))
+	state.SetKey(utils.MMRLeavesKey, utils.HexEncode(4))
+	persistence.SetSchemaVersion(state, 0)
+	state.Close()
+
+	chain, err = NewBlockChainWithOptions(ChainOptions{DataDir: datadir, Params: testparams})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() migration failed! %v", err)
+	}
+	defer chain.CloseBuckets()
+
+	// Every block is converted and no legacy key is left behind
+	for _, block := range blocks {
+		if _, err := chain.GetBlock(block.BlockHash); err != nil {
+			t.Fatalf("migrateBlockLayout() failed! block at height %v was not converted: %v", block.BlockHeight, err)
+		}
+		if _, err := chain.Blocks.GetKey(block.BlockHash); err == nil {
+			t.Fatalf("migrateBlockLayout() failed! legacy key of block at height %v was not deleted", block.BlockHeight)
+		}
+	}
+	if version, _ := persistence.SchemaVersion(chain.State); version != 2 {
+		t.Fatalf("SchemaVersion() failed! expected: 2, got: %v", version)
+	}
+}
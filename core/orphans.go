@@ -0,0 +1,186 @@
+package core
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A set of constants that represent the default limits of an OrphanPool
+const (
+	// Represents the default maximum number of orphan transactions held
+	DefaultMaxOrphanTxns = 100
+	// Represents the default maximum number of orphan blocks held
+	DefaultMaxOrphanBlocks = 50
+	// Represents the default maximum number of orphans held for a single peer
+	DefaultMaxOrphansPerPeer = 10
+	// Represents the default time after which an orphan expires
+	DefaultOrphanTTL = 20 * time.Minute
+)
+
+// Represents the error returned when an item is held as an orphan until its parents are seen
+var ErrOrphan = errors.New("parents are missing, held as orphan")
+
+// A structure that represents an item that is held in an OrphanPool
+// because some of the items that it depends on have not been seen yet
+type Orphan[T any] struct {
+	// Represents the orphan item
+	Item T
+
+	// Represents the peer that the orphan was received from
+	Peer string
+
+	// Represents the hex encoded IDs of the missing parents of the orphan
+	Missing []string
+
+	// Represents the order in which the orphan was added to the pool
+	sequence uint64
+}
+
+// A structure that represents a bounded pool of orphan items of type T (transactions or
+// blocks) that are waiting for a missing parent. When the pool is full the oldest orphan is
+// evicted, orphans expire after a time to live and each peer can only hold a limited number
+// of orphans in the pool, so that a single peer cannot fill the pool.
+type OrphanPool[T any] struct {
+	// Represents the memory pool of orphans by the hex encoded ID of the item
+	Pool *persistence.MemPool[string, *Orphan[T]]
+
+	// Represents the maximum number of orphans held for a single peer
+	perpeer int
+	// Represents the sequence number of the next orphan in the pool
+	sequence uint64
+	// Represents the synchronization lock for the peer limits
+	mutex sync.Mutex
+}
+
+// A constructor function that generates and returns an OrphanPool of the given size,
+// with a limit of orphans for each peer and a time to live for each orphan
+func NewOrphanPool[T any](size uint, perpeer int, ttl time.Duration) *OrphanPool[T] {
+	return &OrphanPool[T]{
+		Pool:    persistence.NewMemPool[string](size, persistence.NewTTLPolicy[string, *Orphan[T]](ttl)),
+		perpeer: perpeer,
+	}
+}
+
+// A method of OrphanPool that adds an item with a given ID that was received from a
+// peer and is waiting for a set of missing parents. Returns an error if the item is
+// already in the pool or if the peer has reached its limit of orphans.
+func (orphans *OrphanPool[T]) Add(id utils.Hash, item T, peer string, missing ...utils.Hash) error {
+	// Acquire the lock on the pool
+	orphans.mutex.Lock()
+	defer orphans.mutex.Unlock()
+
+	// Check that the item is not already in the pool
+	key := hex.EncodeToString(id)
+	if orphans.Pool.Contains(key) {
+		return fmt.Errorf("orphan %v is already in the pool", key)
+	}
+
+	// Remove any expired orphans before counting the orphans of the peer
+	orphans.Pool.Expire()
+	// Check that the peer has not reached its limit of orphans
+	if orphans.count(peer) >= orphans.perpeer {
+		return fmt.Errorf("peer %v has too many orphans", peer)
+	}
+
+	// Create the orphan with the hex encoded IDs of its missing parents
+	orphan := &Orphan[T]{Item: item, Peer: peer, sequence: orphans.sequence}
+	orphans.sequence++
+	for _, parent := range missing {
+		orphan.Missing = append(orphan.Missing, hex.EncodeToString(parent))
+	}
+
+	// Add the orphan to the pool
+	return orphans.Pool.Put(key, orphan)
+}
+
+// A method of OrphanPool that returns the number of orphans in the pool for a peer.
+// Must be called while holding the lock on the pool.
+func (orphans *OrphanPool[T]) count(peer string) int {
+	count := 0
+	orphans.Pool.Range(func(key string, orphan *Orphan[T]) bool {
+		if orphan.Peer == peer {
+			count++
+		}
+
+		return true
+	})
+
+	return count
+}
+
+// A method of OrphanPool that removes and returns the orphans that are waiting for a parent.
+// The orphans are returned in the order that they were added to the pool and should be
+// processed again, because they may still be missing other parents.
+func (orphans *OrphanPool[T]) Resolve(parent utils.Hash) []*Orphan[T] {
+	// Acquire the lock on the pool
+	orphans.mutex.Lock()
+	defer orphans.mutex.Unlock()
+
+	// Collect the keys of the orphans that are waiting for the parent
+	key := hex.EncodeToString(parent)
+	var keys []string
+	orphans.Pool.Range(func(orphankey string, orphan *Orphan[T]) bool {
+		for _, missing := range orphan.Missing {
+			if missing == key {
+				keys = append(keys, orphankey)
+				break
+			}
+		}
+
+		return true
+	})
+
+	// Remove the orphans from the pool
+	resolved := make([]*Orphan[T], 0, len(keys))
+	for _, orphankey := range keys {
+		if orphan, ok := orphans.Pool.Pop(orphankey); ok {
+			resolved = append(resolved, orphan)
+		}
+	}
+
+	// Sort the orphans by the order they were added
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].sequence < resolved[j].sequence })
+	return resolved
+}
+
+// A method of OrphanPool that removes every orphan received from a peer.
+// Used when a peer disconnects or misbehaves. Returns the number of orphans removed.
+func (orphans *OrphanPool[T]) RemovePeer(peer string) int {
+	// Acquire the lock on the pool
+	orphans.mutex.Lock()
+	defer orphans.mutex.Unlock()
+
+	// Collect the keys of the orphans of the peer
+	var keys []string
+	orphans.Pool.Range(func(key string, orphan *Orphan[T]) bool {
+		if orphan.Peer == peer {
+			keys = append(keys, key)
+		}
+
+		return true
+	})
+
+	// Remove the orphans from the pool
+	for _, key := range keys {
+		orphans.Pool.Remove(key)
+	}
+
+	return len(keys)
+}
+
+// A method of OrphanPool that returns whether an item with a given ID is in the pool
+func (orphans *OrphanPool[T]) Contains(id utils.Hash) bool {
+	return orphans.Pool.Contains(hex.EncodeToString(id))
+}
+
+// A method of OrphanPool that returns the number of orphans in the pool
+func (orphans *OrphanPool[T]) Count() int {
+	return int(orphans.Pool.Count)
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_OrphanPool(t *testing.T) {
+	t.Parallel()
+	orphans := NewOrphanPool[string](3, 2, time.Hour)
+
+	// A peer is limited in the number of orphans it can add
+	orphans.Add([]byte{1}, "a", "peer1", []byte{10})
+	orphans.Add([]byte{2}, "b", "peer1", []byte{10}, []byte{11})
+	if err := orphans.Add([]byte{3}, "c", "peer1", []byte{11}); err == nil {
+		t.Fatalf("Add() failed! expected an error for a peer over its limit")
+	}
+	if err := orphans.Add([]byte{2}, "b", "peer2", []byte{10}); err == nil {
+		t.Fatalf("Add() failed! expected an error for a duplicate orphan")
+	}
+
+	// A full pool evicts the oldest orphan
+	orphans.Add([]byte{3}, "c", "peer2", []byte{11})
+	orphans.Add([]byte{4}, "d", "peer2", []byte{12})
+	if orphans.Contains([]byte{1}) || orphans.Count() != 3 {
+		t.Fatalf("Add() failed! expected the oldest orphan to be evicted")
+	}
+
+	// Resolving a parent returns the orphans waiting for it in order
+	resolved := orphans.Resolve([]byte{11})
+	if len(resolved) != 2 || resolved[0].Item != "b" || resolved[1].Item != "c" || orphans.Count() != 1 {
+		t.Fatalf("Resolve() failed! expected: b and c, got: %v orphans", len(resolved))
+	}
+
+	// Removing a peer removes its orphans
+	if removed := orphans.RemovePeer("peer2"); removed != 1 || orphans.Count() != 0 {
+		t.Fatalf("RemovePeer() failed! expected: 1, got: %v", removed)
+	}
+
+	// Orphans expire after their time to live
+	expiring := NewOrphanPool[string](3, 2, time.Millisecond)
+	expiring.Add([]byte{1}, "a", "peer1")
+	time.Sleep(5 * time.Millisecond)
+	if expiring.Pool.Expire(); expiring.Count() != 0 {
+		t.Fatalf("Expire() failed! expected the orphan to have expired")
+	}
+}
+
+func Test_TxPoolOrphans(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	txpool := NewTxPool(chain, DefaultTxPoolSize)
+
+	parent := testspend(w, coinbase.ID, 0, 20)
+	child := testspend(w, parent.ID, 0, 19)
+	grandchild := testspend(w, child.ID, 0, 18)
+
+	// Transactions with unknown parents are held as orphans
+	for _, txn := range []*Transaction{grandchild, child} {
+		if _, err := txpool.Submit(txn, "peer"); !errors.Is(err, ErrOrphan) {
+			t.Fatalf("Submit() failed! expected: ErrOrphan, got: %v", err)
+		}
+	}
+
+	// The parent pulls its orphans into the pool recursively
+	added, err := txpool.Submit(parent, "peer")
+	if err != nil {
+		t.Fatalf("Submit() failed! %v", err)
+	}
+
+	expected := []*Transaction{parent, child, grandchild}
+	if len(added) != len(expected) || txpool.Count() != 3 || txpool.Orphans.Count() != 0 {
+		t.Fatalf("Submit() failed! expected: 3 added, got: %v", len(added))
+	}
+	for index, txn := range expected {
+		if !bytes.Equal(added[index].ID, txn.ID) {
+			t.Fatalf("Submit() failed! unexpected transaction at position %v", index)
+		}
+	}
+}
+
+func Test_SubmitBlock(t *testing.T) {
+	t.Parallel()
+	source := testchain(t)
+
+	// Mint two blocks on a source chain
+	address := testaddress()
+	first := source.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	second := source.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+
+	// Create a chain with the same genesis block
+	genesis, err := source.GetBlock(first.Priori)
+	if err != nil {
+		t.Fatalf("GetBlock() failed! %v", err)
+	}
+
+	chain, err := NewBlockChainWithOptions(ChainOptions{InMemory: true, Params: testparams, Genesis: genesis})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+	t.Cleanup(chain.CloseBuckets)
+
+	// A block with an unknown priori is held as an orphan
+	if _, err := chain.SubmitBlock(second, "peer"); !errors.Is(err, ErrOrphan) {
+		t.Fatalf("SubmitBlock() failed! expected: ErrOrphan, got: %v", err)
+	}
+
+	// The priori block connects its orphan
+	connected, err := chain.SubmitBlock(first, "peer")
+	if err != nil || len(connected) != 2 {
+		t.Fatalf("SubmitBlock() failed! expected: 2 connected, got: %v, %v", len(connected), err)
+	}
+	if !bytes.Equal(chain.ChainHead, second.BlockHash) || chain.Orphans.Count() != 0 {
+		t.Fatalf("SubmitBlock() failed! expected the orphan to be the chain head")
+	}
+
+	// A known block is rejected
+	if _, err := chain.SubmitBlock(first, "peer"); err == nil {
+		t.Fatalf("SubmitBlock() failed! expected an error for a known block")
+	}
+}
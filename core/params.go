@@ -0,0 +1,23 @@
+package core
+
+import (
+	"github.com/manishmeganathan/weave/consensus"
+)
+
+// Represents the default token reward for minting a block
+const DefaultBlockReward = 25
+
+// A structure that represents the consensus parameters of a chain
+type ChainParams struct {
+	// Represents the work difficulty of the proof of work for blocks on the chain
+	Difficulty uint8
+
+	// Represents the token reward of the coinbase transaction for minting a block
+	Reward int
+}
+
+// A constructor function that generates and returns the default
+// ChainParams with the network work difficulty and block reward.
+func DefaultChainParams() ChainParams {
+	return ChainParams{Difficulty: consensus.WorkDifficulty, Reward: DefaultBlockReward}
+}
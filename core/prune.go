@@ -0,0 +1,116 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Represents the minimum number of most recent block bodies kept by a pruned chain
+const MinPruneDepth = 16
+
+// Represents the error returned when an operation needs a block body that has been pruned
+var ErrBlockPruned = errors.New("block body has been pruned")
+
+// A method of BlockChain that configures block pruning for the chain.
+// A prune depth of 0 disables pruning and keeps every block body on the chain.
+// The height of the lowest unpruned block body is read from the state bucket.
+// Returns an error if the pruned height is corrupt or the chain fails to prune.
+func (chain *BlockChain) OpenPruning(depth int) error {
+	// Check if pruning is enabled with a depth below the minimum
+	if depth > 0 && depth < MinPruneDepth {
+		// Log the adjustment of the prune depth
+		logrus.WithFields(logrus.Fields{"depth": depth, "minimum": MinPruneDepth}).Warn("prune depth is below the minimum.")
+		depth = MinPruneDepth
+	}
+
+	// Assign the prune depth of the chain
+	chain.PruneDepth = depth
+
+	// Get the pruned height from the state bucket
+	if value, err := chain.State.GetKey(utils.PrunedHeightKey); err == nil {
+		prunedheight, err := utils.IntDecode(value)
+		if err != nil {
+			return fmt.Errorf("pruned height in state is corrupt! error - %v", err)
+		}
+
+		chain.PrunedHeight = prunedheight
+	}
+
+	// Check if pruning has been disabled on a pruned chain
+	if chain.PruneDepth == 0 && chain.PrunedHeight > 0 {
+		// Log a warning that the pruned bodies cannot be restored
+		logrus.WithFields(logrus.Fields{"prunedheight": chain.PrunedHeight}).Warn("pruning is disabled but blocks below the pruned height have no bodies.")
+	}
+
+	// Prune the chain upto the configured depth
+	return chain.PruneBlocks()
+}
+
+// A method of BlockChain that returns whether the chain has pruned any block bodies
+func (chain *BlockChain) IsPruned() bool {
+	return chain.PrunedHeight > 0
+}
+
+// A method of BlockChain that deletes the bodies of all blocks that are deeper
+// than the prune depth from the chain head. Headers, filters and the state of
+// the chain are kept. Does nothing if pruning is not enabled for the chain.
+//
+// The new pruned height is saved before any body is deleted, so that a body is never
+// served after its deletion has started. Only the blocks from the previous pruned height
+// upto the new pruned height are visited, which are found from the header history.
+// Returns an error if the pruned height cannot be saved or a body cannot be deleted.
+func (chain *BlockChain) PruneBlocks() error {
+	// Check if pruning is enabled
+	if chain.PruneDepth == 0 {
+		return nil
+	}
+
+	// Determine the height of the lowest block body to keep
+	target := chain.ChainHeight - chain.PruneDepth
+	// Check if there are any block bodies to prune
+	if target <= chain.PrunedHeight {
+		return nil
+	}
+
+	// Set the pruned height in the state bucket
+	if err := chain.State.SetKey(utils.PrunedHeightKey, utils.IntEncode(target)); err != nil {
+		return fmt.Errorf("failed to update pruned height state! error - %v", err)
+	}
+
+	// Update the pruned height of the chain
+	previous := chain.PrunedHeight
+	chain.PrunedHeight = target
+
+	// Iterate over the heights of the newly pruned blocks
+	for height := previous; height < target; height++ {
+		// Get the hash of the block at the height
+		blockhash, err := chain.blockhashat(height)
+		if err != nil {
+			return fmt.Errorf("failed to prune block body at height %v! error - %v", height, err)
+		}
+
+		// Delete the body of the block from the blocks bucket
+		if err := chain.Blocks.DeleteKey(bodykey(blockhash)); err != nil {
+			return fmt.Errorf("failed to prune block body at height %v! error - %v", height, err)
+		}
+	}
+
+	// Log the pruning of the block bodies
+	logrus.WithFields(logrus.Fields{"prunedheight": chain.PrunedHeight}).Debug("pruned block bodies.")
+	return nil
+}
+
+// A method of BlockChain that returns an error if the chain has pruned
+// block bodies that are needed for a given operation on the full chain
+func (chain *BlockChain) RequireFullChain(operation string) error {
+	// Check if the chain has been pruned
+	if chain.IsPruned() {
+		return fmt.Errorf("cannot %v: %w below height %v", operation, ErrBlockPruned, chain.PrunedHeight)
+	}
+
+	// Return a nil error
+	return nil
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+func Test_PruneBlocks(t *testing.T) {
+	t.Parallel()
+	chain := testchain(t)
+	genesis := chain.ChainHead
+
+	// A prune depth below the minimum is raised to the minimum
+	if err := chain.OpenPruning(1); err != nil || chain.PruneDepth != MinPruneDepth {
+		t.Fatalf("OpenPruning() failed! expected: %v, got: %v (%v)", MinPruneDepth, chain.PruneDepth, err)
+	}
+
+	// A chain that is not deeper than the prune depth keeps all its bodies
+	if chain.IsPruned() || chain.RequireFullChain("test") != nil {
+		t.Fatalf("PruneBlocks() failed! expected an unpruned chain")
+	}
+
+	// Extend the chain past the prune depth
+	address := testaddress()
+	for i := 0; i < MinPruneDepth+2; i++ {
+		chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+
+	// The bodies that are deeper than the prune depth are pruned
+	if chain.PrunedHeight != chain.ChainHeight-MinPruneDepth || chain.PrunedHeight != 3 {
+		t.Fatalf("PruneBlocks() failed! expected: 3, got: %v", chain.PrunedHeight)
+	}
+	if err := chain.RequireFullChain("test"); !errors.Is(err, ErrBlockPruned) {
+		t.Fatalf("RequireFullChain() failed! expected: %v, got: %v", ErrBlockPruned, err)
+	}
+
+	// Pruned blocks keep their headers but lose their bodies
+	if _, err := chain.GetHeader(genesis); err != nil {
+		t.Fatalf("GetHeader() failed! expected the header of a pruned block, got: %v", err)
+	}
+	if _, err := chain.GetBlock(genesis); err == nil {
+		t.Fatalf("GetBlock() failed! expected an error for a pruned block")
+	}
+	if _, err := chain.GetBlock(chain.ChainHead); err != nil {
+		t.Fatalf("GetBlock() failed! expected the body of the chain head, got: %v", err)
+	}
+
+	// Pruning continues from the pruned height as the chain is extended
+	for i := 0; i < 2; i++ {
+		chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+	if chain.PrunedHeight != 5 {
+		t.Fatalf("PruneBlocks() failed! expected: 5, got: %v", chain.PrunedHeight)
+	}
+
+	// Only the blocks below the pruned height have lost their bodies
+	for height := 0; height < chain.ChainHeight; height++ {
+		blockhash, err := chain.blockhashat(height)
+		if err != nil {
+			t.Fatalf("blockhashat() failed! %v", err)
+		}
+		if _, err := chain.GetBody(blockhash); (err == nil) != (height >= chain.PrunedHeight) {
+			t.Fatalf("PruneBlocks() failed! unexpected body state at height %v: %v", height, err)
+		}
+	}
+
+	// The pruned height is saved to the state bucket
+	value, err := chain.State.GetKey(utils.PrunedHeightKey)
+	if prunedheight, _ := utils.IntDecode(value); err != nil || prunedheight != chain.PrunedHeight {
+		t.Fatalf("PruneBlocks() failed! expected a saved pruned height of %v, got: %v", chain.PrunedHeight, prunedheight)
+	}
+
+	// Disabling pruning keeps the pruned height of the chain
+	if err := chain.OpenPruning(0); err != nil {
+		t.Fatalf("OpenPruning() failed! %v", err)
+	}
+	if chain.PruneDepth != 0 || chain.PrunedHeight != 5 || !chain.IsPruned() {
+		t.Fatalf("OpenPruning() failed! expected the pruned height to be kept, got: %v", chain.PrunedHeight)
+	}
+}
@@ -0,0 +1,317 @@
+package core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/manishmeganathan/weave/consensus"
+	"github.com/manishmeganathan/weave/merkle"
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A structure that represents the utxos of a single transaction in a UTXOSnapshot
+type SnapshotEntry struct {
+	// Represents the ID of the transaction
+	TXID utils.Hash
+
+	// Represents the unspent outputs of the transaction
+	Outputs TXOList
+}
+
+// A structure that represents a snapshot of the utxo layer of a chain at a block.
+// A snapshot carries the header chain upto the block, which allows a fresh node
+// to start from the block and validate the chain forward without its history.
+type UTXOSnapshot struct {
+	// Represents the hash of the block that the snapshot was taken at
+	BlockHash utils.Hash
+
+	// Represents the height of the block that the snapshot was taken at
+	BlockHeight int
+
+	// Represents the header entries of the chain from the genesis to the block
+	Headers []*HeaderEntry
+
+	// Represents the utxo layer of the chain after the block (sorted by ID)
+	UTXOS []SnapshotEntry
+
+	// Represents the hash of the snapshot
+	Hash utils.Hash
+}
+
+// A method of UTXOSnapshot that generates the hash of the snapshot. The hash commits
+// to the block and every utxo in order, while the headers are committed to by the
+// block hash through their linkage.
+func (snapshot *UTXOSnapshot) GenerateHash() utils.Hash {
+	// Create a buffer with the block hash and height
+	var buffer bytes.Buffer
+	buffer.Write(snapshot.BlockHash)
+	buffer.Write(utils.IntEncode(snapshot.BlockHeight))
+
+	// Add each utxo entry to the buffer
+	for _, entry := range snapshot.UTXOS {
+		buffer.Write(entry.TXID)
+		buffer.Write(entry.Outputs.Serialize())
+	}
+
+	// Hash the buffer
+	return utils.Hash256(buffer.Bytes())
+}
+
+// A method of UTXOSnapshot that verifies the snapshot for a given work difficulty.
+// Checks that the headers link from the genesis to the snapshot block with a valid
+// proof of work and that the hash of the snapshot matches its contents.
+func (snapshot *UTXOSnapshot) Verify(difficulty uint8) error {
+	// Check that there is a header for every block upto the snapshot block
+	if len(snapshot.Headers) != snapshot.BlockHeight+1 {
+		return fmt.Errorf("snapshot has %v headers for height %v", len(snapshot.Headers), snapshot.BlockHeight)
+	}
+
+	// Iterate over the headers from the genesis
+	priori := utils.Hash{}
+	for height, entry := range snapshot.Headers {
+		// Check the height and linkage of the header
+		if entry.BlockHeight != height || !bytes.Equal(entry.Priori, priori) {
+			return fmt.Errorf("snapshot header at height %v does not link to the chain", height)
+		}
+
+		// Check the header and its proof of work
+		if err := ValidateHeader(&entry.BlockHeader, entry.BlockHash, difficulty); err != nil {
+			return fmt.Errorf("snapshot header at height %v is invalid! error - %v", height, err)
+		}
+
+		priori = entry.BlockHash
+	}
+
+	// Check that the headers end at the snapshot block
+	if !bytes.Equal(priori, snapshot.BlockHash) {
+		return fmt.Errorf("snapshot headers do not end at the snapshot block")
+	}
+
+	// Check that the hash matches the snapshot
+	if !bytes.Equal(snapshot.GenerateHash(), snapshot.Hash) {
+		return fmt.Errorf("snapshot hash does not match its contents")
+	}
+
+	// Return a nil error
+	return nil
+}
+
+// A method of UTXOSnapshot that writes the gob encoded snapshot to a writer
+func (snapshot *UTXOSnapshot) Write(w io.Writer) error {
+	// Register the gob library with the Consensus Header type
+	gob.Register(consensus.NewPOW())
+	// Encode the snapshot to the writer
+	return gob.NewEncoder(w).Encode(snapshot)
+}
+
+// A function that reads a gob encoded UTXOSnapshot from a reader
+func ReadSnapshot(r io.Reader) (*UTXOSnapshot, error) {
+	// Register the gob library with the Consensus Header type
+	gob.Register(consensus.NewPOW())
+
+	// Decode the snapshot from the reader
+	snapshot := &UTXOSnapshot{}
+	if err := gob.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot! error - %v", err)
+	}
+
+	// Return the snapshot
+	return snapshot, nil
+}
+
+// A method of BlockChain that creates a UTXOSnapshot of the chain at the chain head.
+// The utxo layer is read from a consistent snapshot of the state bucket.
+func (chain *BlockChain) CreateSnapshot() (*UTXOSnapshot, error) {
+	// Create a snapshot of the state bucket
+	state := chain.State.Snapshot()
+	defer state.Discard()
+
+	// Get the chain head from the state snapshot
+	chainhead, err := state.GetKey(utils.ChainHeadKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain head from state! error - %v", err)
+	}
+
+	// Retrieve the header of the chain head
+	head, err := chain.GetHeader(chainhead)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the snapshot for the chain head
+	snapshot := &UTXOSnapshot{BlockHash: head.BlockHash, BlockHeight: head.BlockHeight}
+
+	// Collect the headers of the chain from the head to the genesis
+	snapshot.Headers = make([]*HeaderEntry, head.BlockHeight+1)
+	iter := &BlockChainIterator{Cursor: chainhead, Chain: chain}
+	for height := head.BlockHeight; height >= 0; height-- {
+		snapshot.Headers[height] = iter.NextHeader()
+	}
+
+	// Collect the utxos from the state snapshot
+	err = state.IteratePrefix(utils.UTXOprefix, func(key, value []byte) error {
+		// Deserialize the transaction outputs
+		var outputs TXOList
+		outputs.Deserialize(value)
+
+		// Add the utxo entry to the snapshot
+		txid := append(utils.Hash{}, key[len(utils.UTXOprefix):]...)
+		snapshot.UTXOS = append(snapshot.UTXOS, SnapshotEntry{TXID: txid, Outputs: outputs})
+		return nil
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect utxos! error - %v", err)
+	}
+
+	// Generate the hash of the snapshot
+	snapshot.Hash = snapshot.GenerateHash()
+	// Return the snapshot
+	return snapshot, nil
+}
+
+// Represents the maximum number of snapshot items written to a bucket in a single batch
+const SnapshotBatchSize = 1000
+
+// A function that writes a number of snapshot items to a store in batches of at most
+// SnapshotBatchSize items, so that a large snapshot does not exceed the size of a
+// single database transaction. The function is called with each batch and the range
+// of the items to write on it.
+func batchwrite(store persistence.Store, count int, fn func(batch persistence.Batch, start, end int) error) error {
+	for start := 0; start < count; start += SnapshotBatchSize {
+		end := min(start+SnapshotBatchSize, count)
+		if err := store.Batch(func(batch persistence.Batch) error { return fn(batch, start, end) }); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// A method of BlockChain that configures a new chain database from a UTXOSnapshot
+// after verifying it. The headers of the snapshot are stored without bodies and the
+// chain is marked as pruned below the height after the snapshot block, so that
+// blocks after the snapshot are validated and connected as usual.
+//
+// The snapshot is written in batches while the state bucket is marked with a pending
+// snapshot key. The chain head is set and the mark is removed in the last batch, so
+// the data of a load that is interrupted is cleared when a new chain is set up again.
+func (chain *BlockChain) setup_snapshot(snapshot *UTXOSnapshot) error {
+	// Check that the database does not hold the data of another chain
+	if err := chain.checkempty(); err != nil {
+		return err
+	}
+
+	// Verify the snapshot for the chain difficulty
+	if err := snapshot.Verify(chain.Params.Difficulty); err != nil {
+		return err
+	}
+
+	// Mark the state bucket with the pending snapshot
+	if err := chain.State.SetKey(utils.SnapshotPendingKey, snapshot.BlockHash); err != nil {
+		return fmt.Errorf("failed to mark snapshot! error - %v", err)
+	}
+
+	// Set each header of the snapshot to the blocks bucket
+	err := batchwrite(chain.Blocks, len(snapshot.Headers), func(batch persistence.Batch, start, end int) error {
+		for _, entry := range snapshot.Headers[start:end] {
+			if err := batch.SetKey(headerkey(entry.BlockHash), entry.Serialize()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return fmt.Errorf("failed to store snapshot headers! error - %v", err)
+	}
+
+	// Set each utxo entry of the snapshot to the state bucket
+	err = batchwrite(chain.State, len(snapshot.UTXOS), func(batch persistence.Batch, start, end int) error {
+		for _, entry := range snapshot.UTXOS[start:end] {
+			key := append(append([]byte{}, utils.UTXOprefix...), entry.TXID...)
+			if err := batch.SetKey(key, entry.Outputs.Serialize()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return fmt.Errorf("failed to store snapshot utxos! error - %v", err)
+	}
+
+	// Build the header history from the headers of the snapshot
+	err = batchwrite(chain.State, len(snapshot.Headers), func(batch persistence.Batch, start, end int) error {
+		history := merkle.NewMMR(&historystore{store: batch}, uint64(start))
+		for _, entry := range snapshot.Headers[start:end] {
+			if _, err := history.Append(entry.BlockHash); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return fmt.Errorf("failed to build snapshot header history! error - %v", err)
+	}
+
+	// Determine the chain height after the snapshot block
+	height := snapshot.BlockHeight + 1
+
+	// Define a batch on the state bucket
+	err = chain.State.Batch(func(batch persistence.Batch) error {
+		// Set the schema version of the chain database to the latest version
+		if err := persistence.SetSchemaVersion(batch, persistence.LatestVersion(chain.migrations())); err != nil {
+			return err
+		}
+
+		// Set the number of header history leaves
+		if err := batch.SetKey(utils.MMRLeavesKey, utils.IntEncode(height)); err != nil {
+			return err
+		}
+
+		// Set the pruned height as the height after the snapshot block (no block has a body)
+		if err := batch.SetKey(utils.PrunedHeightKey, utils.IntEncode(height)); err != nil {
+			return err
+		}
+
+		// Set the chain height as the height after the snapshot block
+		if err := batch.SetKey(utils.ChainHeightKey, utils.IntEncode(height)); err != nil {
+			return err
+		}
+
+		// Set the snapshot block as the chain head
+		if err := batch.SetKey(utils.ChainHeadKey, snapshot.BlockHash); err != nil {
+			return err
+		}
+
+		// Remove the mark of the pending snapshot
+		return batch.DeleteKey(utils.SnapshotPendingKey)
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot state! error - %v", err)
+	}
+
+	// Assign the chain head, chain height and pruned height
+	chain.ChainHead = snapshot.BlockHash
+	chain.ChainHeight = height
+	chain.PrunedHeight = height
+	// Open the header history with the committed leaves
+	chain.History = merkle.NewMMR(&historystore{store: chain.State}, uint64(height))
+
+	// Return a nil error
+	return nil
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+func Test_UTXOSnapshot(t *testing.T) {
+	t.Parallel()
+	source := testchain(t)
+
+	address := testaddress()
+	for i := 0; i < 2; i++ {
+		source.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+
+	snapshot, err := source.CreateSnapshot()
+	if err != nil {
+		t.Fatalf("CreateSnapshot() failed! %v", err)
+	}
+	if snapshot.BlockHeight != 2 || len(snapshot.UTXOS) != 3 {
+		t.Fatalf("CreateSnapshot() failed! expected: height 2 with 3 utxos, got: height %v with %v utxos", snapshot.BlockHeight, len(snapshot.UTXOS))
+	}
+
+	// The snapshot survives a round trip through its encoding
+	var buffer bytes.Buffer
+	if err := snapshot.Write(&buffer); err != nil {
+		t.Fatalf("UTXOSnapshot.Write() failed! %v", err)
+	}
+	decoded, err := ReadSnapshot(&buffer)
+	if err != nil || !bytes.Equal(decoded.Hash, snapshot.Hash) {
+		t.Fatalf("ReadSnapshot() failed! %v", err)
+	}
+
+	// Leave the data of an interrupted snapshot load in a data directory
+	datadir := t.TempDir()
+	state, err := persistence.OpenBadgerStore(persistence.STATE, filepath.Join(datadir, string(persistence.STATE)))
+	if err != nil {
+		t.Fatalf("OpenBadgerStore() failed! %v", err)
+	}
+	state.SetKey(utils.SnapshotPendingKey, decoded.BlockHash)
+	leftover := TXOList{*NewTXO(1, address)}
+	state.SetKey(append(append([]byte{}, utils.UTXOprefix...), 1, 2, 3), leftover.Serialize())
+	state.Close()
+
+	// Start a new chain from the snapshot over the interrupted load
+	target, err := NewBlockChainWithOptions(ChainOptions{DataDir: datadir, Params: testparams, Snapshot: decoded})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	if !bytes.Equal(target.ChainHead, source.ChainHead) || target.CountUTXOS() != source.CountUTXOS() || !target.IsPruned() {
+		t.Fatalf("setup_snapshot() failed! chain does not match the source chain")
+	}
+	if _, err := target.State.GetKey(utils.SnapshotPendingKey); err == nil {
+		t.Fatalf("setup_snapshot() failed! the pending snapshot mark was not removed")
+	}
+
+	// Transactions cannot be found or proven on a chain without block bodies
+	if _, err := target.FindTransaction(snapshot.UTXOS[0].TXID); !errors.Is(err, ErrBlockPruned) {
+		t.Fatalf("FindTransaction() failed! expected: %v, got: %v", ErrBlockPruned, err)
+	}
+	if _, _, _, err := target.ProveTransaction(snapshot.UTXOS[0].TXID); !errors.Is(err, ErrBlockPruned) {
+		t.Fatalf("ProveTransaction() failed! expected: %v, got: %v", ErrBlockPruned, err)
+	}
+
+	// Blocks after the snapshot are validated and connected forward
+	block := source.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	if err := target.ValidateBlock(block); err != nil {
+		t.Fatalf("ValidateBlock() failed! %v", err)
+	}
+	if err := target.ConnectBlock(block); err != nil {
+		t.Fatalf("ConnectBlock() failed! %v", err)
+	}
+
+	// The chain started from the snapshot can be reopened
+	target.CloseBuckets()
+	target, err = NewBlockChainWithOptions(ChainOptions{DataDir: datadir, Params: testparams})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() reopen failed! %v", err)
+	}
+	defer target.CloseBuckets()
+
+	if !bytes.Equal(target.ChainHead, block.BlockHash) || target.PrunedHeight != 3 {
+		t.Fatalf("NewBlockChainWithOptions() reopen failed! chain head or pruned height was not restored")
+	}
+
+	// A tampered snapshot fails verification
+	decoded.UTXOS = decoded.UTXOS[1:]
+	if err := decoded.Verify(testparams.Difficulty); err == nil {
+		t.Fatalf("UTXOSnapshot.Verify() failed! expected an error for a tampered snapshot")
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/manishmeganathan/weave/consensus"
+	"github.com/manishmeganathan/weave/merkle"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+// A set of constants that represent the default limits of a BlockTemplate
+const (
+	// Represents the default maximum number of pool transactions included in a block
+	DefaultMaxBlockTxns = 1000
+	// Represents the default maximum combined size of the transactions of a block in bytes
+	DefaultMaxBlockSize = 1 << 20
+)
+
+// A structure that represents the limits for building a BlockTemplate
+type TemplateOptions struct {
+	// Represents the maximum number of pool transactions included in the block (the miner poolsize)
+	MaxTxns int
+
+	// Represents the maximum combined size of the transactions of the block in bytes
+	MaxSize int
+}
+
+// A constructor function that generates and returns the default TemplateOptions
+func DefaultTemplateOptions() TemplateOptions {
+	return TemplateOptions{MaxTxns: DefaultMaxBlockTxns, MaxSize: DefaultMaxBlockSize}
+}
+
+// A structure that represents a candidate block for the chain head that has not been solved.
+// The block has its transactions, coinbase and header set, with a zero nonce and no hash.
+type BlockTemplate struct {
+	// Represents the unsolved block
+	Block *Block
+
+	// Represents the combined fees of the pool transactions of the block
+	Fees int
+}
+
+// A method of BlockChain that builds a BlockTemplate that extends the chain head. Transactions
+// are selected from the pool by the fee rate of their packages within the limits of the options.
+// The coinbase pays the block reward and the fees to the given address. The pool can be nil,
+// which builds a block with only the coinbase.
+func (chain *BlockChain) NewBlockTemplate(txpool *TxPool, coinbase wallet.Address, options TemplateOptions) (*BlockTemplate, error) {
+	// Create a placeholder coinbase to account for its size
+	placeholder := NewCoinbaseTransaction(coinbase, chain.Params.Reward)
+	maxsize := options.MaxSize - len(placeholder.Serialize())
+
+	// Select the transactions from the pool
+	var selected []*PoolTxn
+	if txpool != nil && maxsize > 0 {
+		selected = txpool.Select(maxsize, options.MaxTxns)
+	}
+
+	// Accumulate the fees of the selected transactions
+	fees := 0
+	for _, entry := range selected {
+		fees += entry.Fee
+	}
+
+	// Create the coinbase with the block reward and fees and collect the transactions
+	txns := make([]*Transaction, 0, len(selected)+1)
+	txns = append(txns, NewCoinbaseTransaction(coinbase, chain.Params.Reward+fees))
+	for _, entry := range selected {
+		txns = append(txns, entry.Txn)
+	}
+
+	// Build the merkle tree of the transactions
+	items := make([]utils.GobEncodable, len(txns))
+	for index, txn := range txns {
+		items[index] = txn
+	}
+
+	merkletree := merkle.NewMerkleTree()
+	merkletree.BuildFull(items)
+	merkletree.BuildGroup.Wait()
+
+	// Get the root of the header history
+	history, err := chain.History.Root()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header history root! error - %v", err)
+	}
+
+	// Create the unsolved block for the chain head
+	block := &Block{
+		BlockHeader: *NewBlockHeader(chain.ChainHead, merkletree.MerkleRoot, history),
+		BlockHeight: chain.ChainHeight,
+		BlockOrigin: coinbase,
+		TXCount:     len(txns),
+		TXList:      txns,
+	}
+
+	// Set the Consensus Header to Proof Of Work with the work difficulty
+	block.BlockHeader.ConsensusHeader = consensus.NewDifficultyPOW(chain.Params.Difficulty)
+
+	// Return the block template
+	return &BlockTemplate{Block: block, Fees: fees}, nil
+}
+
+// A method of BlockTemplate that returns the proof of work of the template header
+func (template *BlockTemplate) pow() *consensus.POW {
+	return template.Block.BlockHeader.ConsensusHeader.(*consensus.POW)
+}
+
+// A method of BlockTemplate that returns the target that the hash of the solved header must be below
+func (template *BlockTemplate) Target() *big.Int {
+	return template.pow().Target
+}
+
+// A method of BlockTemplate that solves the template with a nonce and returns the solved block.
+// The template is not modified, so that it can be solved again. Returns an error if the header
+// with the nonce does not satisfy the proof of work.
+func (template *BlockTemplate) Solve(nonce int) (*Block, error) {
+	// Copy the block and its proof of work with the nonce
+	block := *template.Block
+	pow := *template.pow()
+	pow.Nonce = nonce
+	block.BlockHeader.ConsensusHeader = &pow
+
+	// Check that the header satisfies the proof of work
+	if !pow.Validate(&block.BlockHeader) {
+		return nil, fmt.Errorf("nonce does not satisfy the proof of work")
+	}
+
+	// Set the hash of the solved header
+	block.BlockHash = block.BlockHeader.GenerateHash()
+	return &block, nil
+}
+
+// A method of BlockTemplate that searches a range of nonces for a solution of the template.
+// Returns the solved block and true if a nonce in the range satisfies the proof of work.
+// The template is not modified, so that the search can continue with the next range.
+func (template *BlockTemplate) Mine(start, count int) (*Block, bool) {
+	// Copy the block and its proof of work
+	block := *template.Block
+	pow := *template.pow()
+	block.BlockHeader.ConsensusHeader = &pow
+
+	// Search the range of nonces
+	hash, ok := pow.MintRange(&block.BlockHeader, start, count)
+	if !ok {
+		return nil, false
+	}
+
+	// Set the hash of the solved header
+	block.BlockHash = hash
+	return &block, true
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"testing"
+)
+
+// A function that solves a block template by searching for a valid nonce
+func testsolve(t *testing.T, template *BlockTemplate) *Block {
+	for nonce := 0; nonce < 1<<20; nonce++ {
+		if block, err := template.Solve(nonce); err == nil {
+			return block
+		}
+	}
+
+	t.Fatalf("Solve() failed! no nonce found")
+	return nil
+}
+
+func Test_BlockTemplate(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	txpool := NewTxPool(chain, DefaultTxPoolSize)
+
+	// Pool a parent with a fee of 5 and a child with a fee of 1
+	parent := testspend(w, coinbase.ID, 0, 20)
+	child := testspend(w, parent.ID, 0, 19)
+	txpool.Add(parent)
+	txpool.Add(child)
+
+	// The template includes the pool transactions and pays their fees to the coinbase
+	address := testaddress()
+	template, err := chain.NewBlockTemplate(txpool, address, DefaultTemplateOptions())
+	if err != nil {
+		t.Fatalf("NewBlockTemplate() failed! %v", err)
+	}
+	if template.Fees != 6 || template.Block.TXCount != 3 || template.Block.TXList[0].Outputs[0].Value != testparams.Reward+6 {
+		t.Fatalf("NewBlockTemplate() failed! expected: 3 transactions with 6 fees, got: %v with %v fees", template.Block.TXCount, template.Fees)
+	}
+
+	// An unsolved nonce is rejected
+	if _, err := template.Solve(-1); err == nil {
+		t.Fatalf("Solve() failed! expected an error for an invalid nonce")
+	}
+
+	// The solved block is valid and connects to the chain
+	block := testsolve(t, template)
+	if _, err := chain.SubmitBlock(block, ""); err != nil {
+		t.Fatalf("SubmitBlock() failed! %v", err)
+	}
+
+	// The confirmed transactions are dropped from the pool
+	if dropped := txpool.Revalidate(); dropped != 2 {
+		t.Fatalf("Revalidate() failed! expected: 2, got: %v", dropped)
+	}
+
+	// A template with limits excludes the pool transactions
+	txpool.Add(testspend(w, child.ID, 0, 18))
+	template, _ = chain.NewBlockTemplate(txpool, address, TemplateOptions{MaxTxns: 0, MaxSize: DefaultMaxBlockSize})
+	if template.Block.TXCount != 1 || template.Fees != 0 {
+		t.Fatalf("NewBlockTemplate() failed! expected only the coinbase, got: %v", template.Block.TXCount)
+	}
+
+	// A coinbase that claims more than the reward and fees is rejected
+	chain.Params.Reward++
+	template, _ = chain.NewBlockTemplate(nil, address, DefaultTemplateOptions())
+	chain.Params.Reward--
+	if err := chain.ValidateBlock(testsolve(t, template)); err == nil {
+		t.Fatalf("ValidateBlock() failed! expected an error for an excessive coinbase")
+	}
+}
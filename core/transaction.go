@@ -1,10 +1,12 @@
 package core
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
-	"log"
+	"math/big"
 	"strings"
 
 	"github.com/manishmeganathan/weave/utils"
@@ -24,9 +26,10 @@ type Transaction struct {
 	Outputs TXOList
 }
 
-// A constructor function that generates and returns a Transaction
-// given the to and from addresses and the amount to transact.
-func NewTransaction(from, to wallet.Address, amount int, chain *BlockChain) *Transaction {
+// A constructor function that generates and returns a signed Transaction
+// given the to and from addresses and the amount to transact. Returns an
+// error if the account has insufficient funds or the transaction cannot be signed.
+func NewTransaction(from, to wallet.Address, amount int, chain *BlockChain) (*Transaction, error) {
 	// Declare slices of transaction outputs and inputs
 	var txinputs TXIList
 	var txoutputs TXOList
@@ -44,7 +47,7 @@ func NewTransaction(from, to wallet.Address, amount int, chain *BlockChain) *Tra
 
 	// Check if the account has enough funds
 	if accumulated < amount {
-		log.Panic("error: insufficient funds!")
+		return nil, fmt.Errorf("insufficient funds! %v available for %v", accumulated, amount)
 	}
 
 	// Iterate over the spendable transaction output IDs
@@ -72,19 +75,19 @@ func NewTransaction(from, to wallet.Address, amount int, chain *BlockChain) *Tra
 
 	// Create a Transaction with the list of input and outputs
 	txn := Transaction{ID: nil, Inputs: txinputs, Outputs: txoutputs}
-	// Set the ID (hash) for the transaction
-	txn.ID = txn.GenerateHash()
-	// Sign the transaction using the wallet's private key
-	chain.SignTransaction(&txn, w.PrivateKey)
+	// Sign the transaction using the wallet's private key (which sets its ID)
+	if err := chain.SignTransaction(&txn, w.PrivateKey); err != nil {
+		return nil, err
+	}
 
 	// Return the transaction
-	return &txn
+	return &txn, nil
 }
 
 // A constructor function that generates and returns a coinbase Transaction.
 // A Coinbase transaction refers to a first transaction on a block and does not refer to any
 // previous output transactions and contains a token reward for the user who signs the block.
-func NewCoinbaseTransaction(to wallet.Address) *Transaction {
+func NewCoinbaseTransaction(to wallet.Address, reward int) *Transaction {
 	// Create a slice a bytes
 	randdata := make([]byte, 24)
 	// Add random data to the slice of bytes
@@ -100,7 +103,7 @@ func NewCoinbaseTransaction(to wallet.Address) *Transaction {
 	// Create a transaction input with no reference to a previous output
 	inputs := TXI{ID: []byte{}, OutIndex: -1, Signature: nil, PublicKey: []byte(data)}
 	// Create a transaction output with the token reward
-	outputs := *NewTXO(25, to)
+	outputs := *NewTXO(reward, to)
 
 	// Construct a transaction with no ID, and the set of inputs and outputs
 	txn := Transaction{
@@ -153,6 +156,96 @@ func (txn *Transaction) GenerateSafeCopy() Transaction {
 	return txncopy
 }
 
+// A method of Transaction that returns the hash signed by one of its inputs. The hash is
+// generated from a safe copy of the transaction in which only the signed input carries
+// the public key hash of the output that it spends.
+func (txn *Transaction) SignatureHash(index int, pubkeyhash utils.Hash) utils.Hash {
+	// Generate a safe copy of the transaction
+	txncopy := txn.GenerateSafeCopy()
+	// Set the input public key with the public key hash of the spent output
+	txncopy.Inputs[index].PublicKey = utils.PublicKey(pubkeyhash)
+
+	// Generate the hash of the trimmed transaction
+	return txncopy.GenerateHash()
+}
+
+// A method of Transaction that signs its inputs with a private key given the outputs
+// that are spent by each input, in the order of the inputs. The ID of the transaction
+// is generated again after signing because the hash includes the signatures.
+func (txn *Transaction) Sign(privatekey ecdsa.PrivateKey, spent TXOList) error {
+	// Check that there is a spent output for each input
+	if len(spent) != len(txn.Inputs) {
+		return fmt.Errorf("transaction has %v inputs but %v spent outputs", len(txn.Inputs), len(spent))
+	}
+
+	// Retrieve the size of the signature values for the curve of the key
+	size := (privatekey.Curve.Params().BitSize + 7) / 8
+
+	// Iterate over the inputs of the transaction
+	for index := range txn.Inputs {
+		// Sign the hash with the ECDSA method using the private key
+		r, s, err := ecdsa.Sign(rand.Reader, &privatekey, txn.SignatureHash(index, spent[index].PublicKeyHash))
+		if err != nil {
+			return fmt.Errorf("failed to sign transaction! error - %v", err)
+		}
+
+		// Append the padded r and s values to form the signature
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+
+		// Assign the signature of the input
+		txn.Inputs[index].Signature = signature
+	}
+
+	// Set the ID (hash) for the signed transaction
+	txn.ID = txn.GenerateHash()
+	return nil
+}
+
+// A method of Transaction that verifies the signatures of its inputs given the outputs
+// that are spent by each input, in the order of the inputs. Every input must carry the
+// public key whose hash locks the spent output and a signature made with its private pair.
+func (txn *Transaction) Verify(spent TXOList) error {
+	// Check that there is a spent output for each input
+	if len(spent) != len(txn.Inputs) {
+		return fmt.Errorf("transaction has %v inputs but %v spent outputs", len(txn.Inputs), len(spent))
+	}
+
+	// Iterate over the inputs of the transaction
+	for index, input := range txn.Inputs {
+		// Check that the output is locked by the public key of the input
+		if !input.CheckKey(spent[index].PublicKeyHash) {
+			return fmt.Errorf("transaction input %v has an invalid key", index)
+		}
+
+		// Check that the signature and public key can be split in half
+		if len(input.Signature) == 0 || len(input.Signature)%2 != 0 || len(input.PublicKey)%2 != 0 {
+			return fmt.Errorf("transaction input %v has an invalid signature", index)
+		}
+
+		// Split the signature into r and s values
+		signaturesize := len(input.Signature)
+		r := new(big.Int).SetBytes(input.Signature[:(signaturesize / 2)])
+		s := new(big.Int).SetBytes(input.Signature[(signaturesize / 2):])
+
+		// Split the public key into its x and y coordinates
+		keysize := len(input.PublicKey)
+		x := new(big.Int).SetBytes(input.PublicKey[:(keysize / 2)])
+		y := new(big.Int).SetBytes(input.PublicKey[(keysize / 2):])
+
+		// Create an ECDSA public key from sepc256r1 curve and the x, y coordinates
+		rawpublickey := ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+
+		// Check if the input has been signed with the public key's private pair
+		if !ecdsa.Verify(&rawpublickey, txn.SignatureHash(index, spent[index].PublicKeyHash), r, s) {
+			return fmt.Errorf("transaction input %v has an invalid signature", index)
+		}
+	}
+
+	return nil
+}
+
 // A method of Transaction that generates the string representation
 // of a transaction and all its inputs and outputs.
 // TODO: NEEDS REWORK FOR FORMATTING
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A function that returns the index key for the block of a given transaction ID
+func txnkey(txnid utils.Hash) []byte {
+	return append(append([]byte{}, utils.Txnprefix...), txnid...)
+}
+
+// A method of BlockChain that sets the hash of a Block to the index bucket
+// for the ID of each of its transactions
+func (chain *BlockChain) IndexBlockTxns(block *Block) error {
+	// Set the block hash for each transaction in a single batch
+	err := chain.Index.Batch(func(batch persistence.Batch) error {
+		return indextxns(batch, block)
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return fmt.Errorf("failed to add block transactions to index! error - %v", err)
+	}
+
+	return nil
+}
+
+// A function that sets the block hash for each transaction of a block to a store (or batch)
+func indextxns(store persistence.Writer, block *Block) error {
+	for _, txn := range block.TXList {
+		if err := store.SetKey(txnkey(txn.ID), block.BlockHash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// A method of BlockChain that retrieves the hash of the block that
+// includes a given transaction ID from the index bucket
+func (chain *BlockChain) GetTxnBlock(txnid utils.Hash) (utils.Hash, error) {
+	// Get the block hash from the index bucket
+	blockhash, err := chain.Index.GetKey(txnkey(txnid))
+	if err != nil {
+		// Return the error
+		return nil, fmt.Errorf("transaction index retrieval failed! error - %v", err)
+	}
+
+	// Return the block hash
+	return blockhash, nil
+}
+
+// A method of BlockChain that regenerates the transaction index for all the blocks
+// on the chain that have a body. The transactions of pruned blocks are not indexed.
+// Returns an error if the transactions of a block cannot be indexed.
+func (chain *BlockChain) ReindexTxns() error {
+	// Delete all the transactions stored on the index
+	chain.Index.DeleteKeyPrefix(utils.Txnprefix)
+
+	// Get an iterator for the blockchain and iterate over its blocks with a body
+	iter := NewIterator(chain)
+	for height := chain.ChainHeight - 1; height >= chain.PrunedHeight; height-- {
+		// Get a block from the iterator and index its transactions
+		if err := chain.IndexBlockTxns(iter.Next()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// A method of BlockChain that returns the block that includes a given transaction ID
+// from the transaction index for an operation. Returns an error wrapping ErrBlockPruned if the block body
+// has been pruned or if the transaction is not indexed and may be in a pruned block.
+func (chain *BlockChain) txnblock(txnid utils.Hash, operation string) (*Block, error) {
+	// Retrieve the hash of the block that includes the transaction
+	blockhash, err := chain.GetTxnBlock(txnid)
+	if err != nil {
+		// Check if the transaction may be in a pruned block
+		if err := chain.RequireFullChain(operation); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("transaction does not exist")
+	}
+
+	// Retrieve the header of the block and check that its body has not been pruned
+	entry, err := chain.GetHeader(blockhash)
+	if err != nil {
+		return nil, err
+	}
+	if entry.BlockHeight < chain.PrunedHeight {
+		return nil, fmt.Errorf("cannot %v: %w below height %v", operation, ErrBlockPruned, chain.PrunedHeight)
+	}
+
+	// Retrieve the block from the chain
+	return chain.GetBlock(blockhash)
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"encoding/hex"
+	"sort"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A structure that represents a package of unconfirmed transactions in a TxPool.
+// A package is a transaction with the ancestors in the pool that it spends, which
+// must be confirmed together. A child with a high fee can pay for its parents by
+// raising the fee rate of its package (child pays for parent).
+type TxPackage struct {
+	// Represents the transactions of the package with every parent before its children
+	Txns []*PoolTxn
+
+	// Represents the combined fee of the transactions
+	Fee int
+
+	// Represents the combined size of the transactions in bytes
+	Size int
+
+	// Represents the positions of the transactions in the graph they were collected from
+	positions []int
+}
+
+// A method of TxPackage that returns the combined fee rate of the package in tokens per kilobyte
+func (pkg *TxPackage) FeeRate() int64 {
+	return int64(pkg.Fee) * 1000 / int64(pkg.Size)
+}
+
+// A method of TxPackage that reports whether a package has a higher fee rate than another.
+// Fee rates are compared by cross multiplication to avoid rounding.
+func (pkg *TxPackage) better(other *TxPackage) bool {
+	return int64(pkg.Fee)*int64(other.Size) > int64(other.Fee)*int64(pkg.Size)
+}
+
+// A structure that represents a snapshot of the transactions in a TxPool with their parents
+type txgraph struct {
+	// Represents the transactions in the order that they entered the pool
+	entries []*PoolTxn
+	// Represents the position of each transaction by its hex encoded ID
+	positions map[string]int
+	// Represents the positions of the parents in the pool of each transaction
+	parents [][]int
+}
+
+// A method of TxPool that creates a graph of the transactions in the pool
+func (txpool *TxPool) graph() *txgraph {
+	// Collect the transactions of the pool in order
+	entries := txpool.Transactions()
+	graph := &txgraph{entries: entries, positions: make(map[string]int), parents: make([][]int, len(entries))}
+
+	for position, entry := range entries {
+		graph.positions[hex.EncodeToString(entry.Txn.ID)] = position
+	}
+
+	// Collect the parents of each transaction
+	for position, entry := range entries {
+		for _, input := range entry.Txn.Inputs {
+			if parent, ok := graph.positions[hex.EncodeToString(input.ID)]; ok {
+				graph.parents[position] = append(graph.parents[position], parent)
+			}
+		}
+	}
+
+	return graph
+}
+
+// A method of txgraph that returns the package of a transaction without the transactions
+// that are already included. Transactions are ordered as they entered the pool, which
+// places every parent before its children.
+func (graph *txgraph) pkg(position int, included []bool) *TxPackage {
+	// Collect the transaction and its ancestors that are not included
+	members := make(map[int]bool)
+	var visit func(position int)
+	visit = func(position int) {
+		if members[position] || included[position] {
+			return
+		}
+
+		members[position] = true
+		for _, parent := range graph.parents[position] {
+			visit(parent)
+		}
+	}
+
+	visit(position)
+
+	// Sort the positions of the members
+	pkg := &TxPackage{positions: make([]int, 0, len(members))}
+	for member := range members {
+		pkg.positions = append(pkg.positions, member)
+	}
+	sort.Ints(pkg.positions)
+
+	// Add the members to the package in order
+	for _, member := range pkg.positions {
+		entry := graph.entries[member]
+		pkg.Txns = append(pkg.Txns, entry)
+		pkg.Fee += entry.Fee
+		pkg.Size += entry.Size
+	}
+
+	return pkg
+}
+
+// A method of TxPool that returns the package of a transaction in the pool, which
+// holds the transaction and every unconfirmed ancestor that it spends. Returns
+// false if the transaction is not in the pool.
+func (txpool *TxPool) Package(txid utils.Hash) (*TxPackage, bool) {
+	// Create a graph of the pool
+	graph := txpool.graph()
+
+	// Find the position of the transaction
+	position, ok := graph.positions[hex.EncodeToString(txid)]
+	if !ok {
+		return nil, false
+	}
+
+	return graph.pkg(position, make([]bool, len(graph.entries))), true
+}
+
+// A method of TxPool that selects transactions from the pool for a block upto a
+// combined size in bytes and a number of transactions. Transactions are selected as packages with their unconfirmed
+// ancestors, in order of the combined fee rate of the package, so that a child with a
+// high fee pulls its low fee parents into the block. The selected transactions are
+// returned with every parent before its children.
+func (txpool *TxPool) Select(maxsize, maxcount int) []*PoolTxn {
+	// Create a graph of the pool
+	graph := txpool.graph()
+	included := make([]bool, len(graph.entries))
+
+	// Collect the package of every transaction
+	packages := make([]*TxPackage, len(graph.entries))
+	for position := range graph.entries {
+		packages[position] = graph.pkg(position, included)
+	}
+
+	var selected []*PoolTxn
+	size := 0
+
+	for {
+		// Find the package with the highest fee rate that fits in the remaining size and count
+		var best *TxPackage
+		for position, pkg := range packages {
+			if included[position] || size+pkg.Size > maxsize || len(selected)+len(pkg.Txns) > maxcount {
+				continue
+			}
+
+			// Keep the earliest package among those with the same fee rate
+			if best == nil || pkg.better(best) {
+				best = pkg
+			}
+		}
+
+		// Check if any package fits
+		if best == nil {
+			break
+		}
+
+		// Include the transactions of the package
+		for index, entry := range best.Txns {
+			included[best.positions[index]] = true
+			selected = append(selected, entry)
+		}
+
+		size += best.Size
+
+		// Recollect the packages that shared transactions with the included package
+		for position, pkg := range packages {
+			if included[position] {
+				continue
+			}
+
+			for _, member := range pkg.positions {
+				if included[member] {
+					packages[position] = graph.pkg(position, included)
+					break
+				}
+			}
+		}
+	}
+
+	return selected
+}
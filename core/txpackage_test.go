@@ -0,0 +1,55 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_TxPoolSelect(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	first := testcoinbase(t, chain)
+	second := testreward(t, chain, w)
+	txpool := NewTxPool(chain, DefaultTxPoolSize)
+
+	// Pool a parent with a fee of 1 and a child with a fee of 10
+	parent := testspend(w, first.ID, 0, 24)
+	child := testspend(w, parent.ID, 0, 14)
+	// Pool an unrelated transaction with a fee of 4
+	other := testspend(w, second.ID, 0, 21)
+
+	for _, txn := range []*Transaction{parent, other, child} {
+		if err := txpool.Add(txn); err != nil {
+			t.Fatalf("Add() failed! %v", err)
+		}
+	}
+
+	// The package of the child includes its parent
+	pkg, ok := txpool.Package(child.ID)
+	if !ok || len(pkg.Txns) != 2 || pkg.Fee != 11 {
+		t.Fatalf("Package() failed! expected: 2 transactions with a fee of 11, got: %v", pkg)
+	}
+
+	// The child pays for its parent, so the package is selected before the other transaction
+	selected := txpool.Select(1<<20, 10)
+	expected := []*Transaction{parent, child, other}
+	if len(selected) != len(expected) {
+		t.Fatalf("Select() failed! expected: %v transactions, got: %v", len(expected), len(selected))
+	}
+	for index, txn := range expected {
+		if !bytes.Equal(selected[index].Txn.ID, txn.ID) {
+			t.Fatalf("Select() failed! unexpected transaction at position %v", index)
+		}
+	}
+
+	// A size limit that only fits one transaction selects the other transaction,
+	// because the package of the child does not fit and the parent alone has a lower fee rate
+	if selected := txpool.Select(pkg.Size-1, 10); len(selected) != 1 || !bytes.Equal(selected[0].Txn.ID, other.ID) {
+		t.Fatalf("Select() failed! expected only the other transaction")
+	}
+
+	// A count limit of one transaction also skips the package of the child
+	if selected := txpool.Select(1<<20, 1); len(selected) != 1 || !bytes.Equal(selected[0].Txn.ID, other.ID) {
+		t.Fatalf("Select() failed! expected only the other transaction")
+	}
+}
@@ -0,0 +1,596 @@
+package core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// Represents the default maximum number of transactions in a TxPool
+const DefaultTxPoolSize = 5000
+
+// Represents the maximum number of transactions that a replacement can evict from a TxPool
+const MaxReplacements = 100
+
+// Represents the error returned when a transaction spends an output that is not known
+var ErrMissingInputs = errors.New("transaction input spends a missing output")
+
+// A structure that represents a pending transaction in a TxPool
+type PoolTxn struct {
+	// Represents the transaction
+	Txn *Transaction
+
+	// Represents the fee of the transaction (inputs minus outputs)
+	Fee int
+
+	// Represents the size of the serialized transaction in bytes
+	Size int
+
+	// Represents the time at which the transaction entered the pool
+	Added time.Time
+
+	// Represents the order in which the transaction entered the pool
+	sequence uint64
+}
+
+// A method of PoolTxn that returns the fee rate of the transaction in tokens per kilobyte
+func (entry *PoolTxn) FeeRate() int64 {
+	return int64(entry.Fee) * 1000 / int64(entry.Size)
+}
+
+// A structure that represents a pool of pending transactions that spend the
+// utxos of a chain or the outputs of other transactions in the pool.
+// When the pool is full, the transactions with the lowest fee rate are evicted
+// along with the transactions in the pool that spend them.
+type TxPool struct {
+	// Represents the memory pool of transactions by the hex encoded transaction ID
+	Pool *persistence.MemPool[string, *PoolTxn]
+
+	// Represents the pool of transactions that spend outputs of unknown transactions
+	Orphans *OrphanPool[*Transaction]
+
+	// Represents the chain whose utxos the transactions spend
+	chain *BlockChain
+
+	// Represents the transaction ID that spends each output in the pool
+	spends map[string]string
+	// Represents the sequence number of the next transaction in the pool
+	sequence uint64
+	// Represents the synchronization lock for admitting transactions
+	mutex sync.RWMutex
+}
+
+// A constructor function that generates and returns an empty TxPool of the given size for a chain
+func NewTxPool(chain *BlockChain, size uint) *TxPool {
+	// Create a memory pool that evicts the lowest fee rate first
+	pool := persistence.NewMemPool[string](size, persistence.NewPriorityPolicy[string](func(entry *PoolTxn) int64 {
+		return entry.FeeRate()
+	}))
+
+	return &TxPool{
+		Pool:    pool,
+		Orphans: NewOrphanPool[*Transaction](DefaultMaxOrphanTxns, DefaultMaxOrphansPerPeer, DefaultOrphanTTL),
+		chain:   chain,
+		spends:  make(map[string]string),
+	}
+}
+
+// A function that returns the key of a transaction output for the spends of a TxPool
+func outpoint(txid utils.Hash, index int) string {
+	return fmt.Sprintf("%x:%d", txid, index)
+}
+
+// A method of TxPool that validates a transaction and adds it to the pool.
+//
+// A transaction that spends an output which is already spent by transactions in the pool
+// replaces them (with their descendants) if it pays a strictly higher fee than all of them
+// together and a higher fee rate than each of them. Returns an error if the transaction is
+// invalid, is not a valid replacement or if the pool is full of transactions with a higher
+// fee rate.
+func (txpool *TxPool) Add(txn *Transaction) error {
+	// Acquire the lock on the pool
+	txpool.mutex.Lock()
+	defer txpool.mutex.Unlock()
+
+	return txpool.add(&PoolTxn{Txn: txn, Added: time.Now()})
+}
+
+// A method of TxPool that validates a pool transaction and adds it to the pool.
+// The fee and size of the entry are computed. Must be called while holding the lock.
+func (txpool *TxPool) add(entry *PoolTxn) error {
+	// Validate the transaction against the chain and the pool
+	fee, conflicts, err := txpool.validate(entry.Txn)
+	if err != nil {
+		return err
+	}
+
+	// Set the fee and size of the entry
+	entry.Fee = fee
+	entry.Size = len(entry.Txn.Serialize())
+
+	// Check if the transaction replaces any transactions in the pool
+	if len(conflicts) > 0 {
+		// Check that the transaction can replace the conflicts
+		replaced, err := txpool.replaceable(entry, conflicts)
+		if err != nil {
+			return err
+		}
+
+		// Remove the replaced transactions from the pool
+		for _, txid := range replaced {
+			txpool.remove(txid)
+		}
+	}
+
+	// Evict the packages with the lowest fee rate until there is room for the transaction
+	if err := txpool.makeroom(entry); err != nil {
+		return err
+	}
+
+	// Assign the next sequence number to the entry
+	entry.sequence = txpool.sequence
+	txpool.sequence++
+
+	// Add the entry to the memory pool
+	txid := hex.EncodeToString(entry.Txn.ID)
+	if err := txpool.Pool.Put(txid, entry); err != nil {
+		return fmt.Errorf("transaction rejected! error - %v", err)
+	}
+
+	// Record the outputs spent by the transaction
+	for _, input := range entry.Txn.Inputs {
+		txpool.spends[outpoint(input.ID, input.OutIndex)] = txid
+	}
+
+	return nil
+}
+
+// A method of TxPool that validates a transaction against the utxos of the chain and the
+// outputs of the transactions in the pool. Checks that the transaction is well formed, that
+// every input spends an unspent output locked by its public key and is signed by its private
+// pair, and that the inputs cover the outputs. Returns the fee of the transaction and the IDs of the transactions in the
+// pool that already spend any of its inputs.
+func (txpool *TxPool) validate(txn *Transaction) (int, []string, error) {
+	// Check that the transaction is not a coinbase
+	if txn.IsCoinbase() {
+		return 0, nil, fmt.Errorf("coinbase transactions cannot be pooled")
+	}
+
+	// Check that the transaction ID is the hash of the transaction
+	if !bytes.Equal(txn.ID, txn.GenerateHash()) {
+		return 0, nil, fmt.Errorf("transaction does not match its ID")
+	}
+
+	// Check that the transaction is not already in the pool
+	if txpool.Pool.Contains(hex.EncodeToString(txn.ID)) {
+		return 0, nil, fmt.Errorf("transaction is already in the pool")
+	}
+
+	// Check that the transaction has inputs and outputs
+	if len(txn.Inputs) == 0 || len(txn.Outputs) == 0 {
+		return 0, nil, fmt.Errorf("transaction has no inputs or outputs")
+	}
+
+	// Accumulate the value of the outputs
+	outvalue := 0
+	for _, output := range txn.Outputs {
+		if output.Value <= 0 {
+			return 0, nil, fmt.Errorf("transaction has an output with an invalid value")
+		}
+
+		outvalue += output.Value
+	}
+
+	// Accumulate the value of the inputs
+	invalue := 0
+	var spent TXOList
+	var conflicts []string
+	inputs := make(map[string]bool)
+	for _, input := range txn.Inputs {
+		key := outpoint(input.ID, input.OutIndex)
+
+		// Check that the output is not spent twice by the transaction
+		if inputs[key] {
+			return 0, nil, fmt.Errorf("transaction spends output %v twice", key)
+		}
+		inputs[key] = true
+
+		// Collect the transaction in the pool that already spends the output
+		if spender, ok := txpool.spends[key]; ok && txpool.Pool.Contains(spender) {
+			conflicts = append(conflicts, spender)
+		}
+
+		// Retrieve the output spent by the input
+		output, err := txpool.output(input)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		spent = append(spent, output)
+		invalue += output.Value
+	}
+
+	// Check that every input is signed by the key that locks the output it spends
+	if err := txn.Verify(spent); err != nil {
+		return 0, nil, err
+	}
+
+	// Check that the inputs cover the outputs
+	if invalue < outvalue {
+		return 0, nil, fmt.Errorf("transaction outputs of %v exceed its inputs of %v", outvalue, invalue)
+	}
+
+	// Return the fee of the transaction and its conflicts
+	return invalue - outvalue, conflicts, nil
+}
+
+// A method of TxPool that returns the output spent by a transaction input from
+// the outputs of a transaction in the pool or from the utxos of the chain
+func (txpool *TxPool) output(input TXI) (TXO, error) {
+	// Check if the input spends a transaction in the pool
+	parent, ok := txpool.Pool.Get(hex.EncodeToString(input.ID))
+	if !ok {
+		// Retrieve the output from the utxos of the chain
+		return txpool.chain.GetUTXO(input.ID, input.OutIndex)
+	}
+
+	// Check that the output index is within the outputs
+	if input.OutIndex < 0 || input.OutIndex >= len(parent.Txn.Outputs) {
+		return TXO{}, fmt.Errorf("transaction input spends a missing output %x:%d", input.ID, input.OutIndex)
+	}
+
+	return parent.Txn.Outputs[input.OutIndex], nil
+}
+
+// A method of TxPool that submits a transaction received from a peer to the pool.
+// A transaction that spends outputs of unknown transactions is held as an orphan and
+// ErrOrphan is returned. When a transaction is added, the orphans that spend it are
+// submitted again recursively. Returns the transactions that were added in order.
+func (txpool *TxPool) Submit(txn *Transaction, peer string) ([]*Transaction, error) {
+	// Add the transaction to the pool or hold it as an orphan
+	if err := txpool.submit(txn, peer); err != nil {
+		return nil, err
+	}
+
+	// Process the orphans of each added transaction
+	added := []*Transaction{txn}
+	for index := 0; index < len(added); index++ {
+		for _, orphan := range txpool.Orphans.Resolve(added[index].ID) {
+			// Submit the orphan again, it may be missing other parents
+			if err := txpool.submit(orphan.Item, orphan.Peer); err == nil {
+				added = append(added, orphan.Item)
+			}
+		}
+	}
+
+	return added, nil
+}
+
+// A method of TxPool that adds a transaction to the pool or holds it as an
+// orphan if it spends outputs of transactions that are not known
+func (txpool *TxPool) submit(txn *Transaction, peer string) error {
+	// Add the transaction to the pool
+	err := txpool.Add(txn)
+	if !errors.Is(err, ErrMissingInputs) {
+		return err
+	}
+
+	// Collect the unknown transactions that the transaction spends
+	var missing []utils.Hash
+	for _, input := range txn.Inputs {
+		key := append(append([]byte{}, utils.UTXOprefix...), input.ID...)
+		if _, ok := txpool.Get(input.ID); ok {
+			continue
+		}
+		if _, err := txpool.chain.State.GetKey(key); err == nil {
+			continue
+		}
+
+		missing = append(missing, input.ID)
+	}
+
+	// Hold the transaction as an orphan
+	if err := txpool.Orphans.Add(txn.ID, txn, peer, missing...); err != nil {
+		return err
+	}
+
+	return ErrOrphan
+}
+
+// A method of TxPool that returns the transaction in the pool for a transaction ID
+func (txpool *TxPool) Get(txid utils.Hash) (*PoolTxn, bool) {
+	return txpool.Pool.Get(hex.EncodeToString(txid))
+}
+
+// A method of TxPool that removes the transaction for a transaction ID from the pool
+func (txpool *TxPool) Remove(txid utils.Hash) {
+	// Acquire the lock on the pool
+	txpool.mutex.Lock()
+	defer txpool.mutex.Unlock()
+
+	// Remove the transaction
+	txpool.remove(hex.EncodeToString(txid))
+}
+
+// A method of TxPool that removes the transaction for a hex encoded transaction ID
+// and the outputs that it spends. Must be called while holding the lock.
+func (txpool *TxPool) remove(txid string) {
+	if entry, ok := txpool.Pool.Pop(txid); ok {
+		for _, input := range entry.Txn.Inputs {
+			// Only remove the spends that still belong to the transaction
+			key := outpoint(input.ID, input.OutIndex)
+			if txpool.spends[key] == txid {
+				delete(txpool.spends, key)
+			}
+		}
+	}
+}
+
+// A method of TxPool that evicts the transaction with the lowest fee rate from a full pool
+// until there is room for a pool transaction. The descendants of an evicted transaction are
+// evicted with it, so that every transaction left in the pool spends outputs that exist.
+// Returns an error if the transaction does not pay a higher fee rate than the evicted
+// transaction or spends an evicted transaction. Must be called while holding the lock.
+func (txpool *TxPool) makeroom(entry *PoolTxn) error {
+	for txpool.Pool.IsFull() {
+		// Retrieve the transaction with the lowest fee rate
+		victim, victimentry, ok := txpool.Pool.Victim()
+		if !ok || entry.FeeRate() <= victimentry.FeeRate() {
+			return fmt.Errorf("transaction rejected! error - pool is full")
+		}
+
+		// Collect the transaction and its descendants
+		evicted := txpool.descendants([]string{victim})
+
+		// Check that the transaction does not spend an evicted transaction
+		for _, txid := range evicted {
+			for _, input := range entry.Txn.Inputs {
+				if hex.EncodeToString(input.ID) == txid {
+					return fmt.Errorf("transaction rejected! error - pool is full")
+				}
+			}
+		}
+
+		// Evict the transactions with their descendants first
+		for _, txid := range evicted {
+			txpool.remove(txid)
+		}
+	}
+
+	return nil
+}
+
+// A method of TxPool that checks whether a pool transaction can replace the transactions
+// in the pool that it conflicts with. The conflicts and their descendants are replaced.
+// Returns the IDs of the replaced transactions with the descendants before their parents.
+// Must be called while holding the lock.
+func (txpool *TxPool) replaceable(entry *PoolTxn, conflicts []string) ([]string, error) {
+	// Collect the conflicts and their descendants
+	replaced := txpool.descendants(conflicts)
+	if len(replaced) > MaxReplacements {
+		return nil, fmt.Errorf("replacement would evict %v transactions", len(replaced))
+	}
+
+	// Accumulate the fees of the replaced transactions
+	fees := 0
+	for _, txid := range replaced {
+		// Check that the transaction does not spend a transaction that it replaces
+		for _, input := range entry.Txn.Inputs {
+			if hex.EncodeToString(input.ID) == txid {
+				return nil, fmt.Errorf("replacement spends the replaced transaction %v", txid)
+			}
+		}
+
+		if replacedentry, ok := txpool.Pool.Get(txid); ok {
+			fees += replacedentry.Fee
+		}
+	}
+
+	// Check that the fee is strictly higher than the fees of the replaced transactions
+	if entry.Fee <= fees {
+		return nil, fmt.Errorf("replacement fee of %v does not exceed the replaced fees of %v", entry.Fee, fees)
+	}
+
+	// Check that the fee rate is higher than the fee rate of each conflict
+	for _, txid := range conflicts {
+		if conflict, ok := txpool.Pool.Get(txid); ok && entry.FeeRate() <= conflict.FeeRate() {
+			return nil, fmt.Errorf("replacement fee rate does not exceed the fee rate of %v", txid)
+		}
+	}
+
+	return replaced, nil
+}
+
+// A method of TxPool that returns the IDs of a set of transactions in the pool and
+// all their descendants, with every descendant before the transaction it spends
+func (txpool *TxPool) descendants(txids []string) []string {
+	// Declare the collected transactions and the set of visited transactions
+	var collected []string
+	visited := make(map[string]bool)
+
+	// Declare a function that collects a transaction after its descendants
+	var visit func(txid string)
+	visit = func(txid string) {
+		if visited[txid] {
+			return
+		}
+		visited[txid] = true
+
+		// Retrieve the transaction from the pool
+		entry, ok := txpool.Pool.Get(txid)
+		if !ok {
+			return
+		}
+
+		// Visit the transactions that spend the outputs of the transaction
+		for index := range entry.Txn.Outputs {
+			if spender, ok := txpool.spends[outpoint(entry.Txn.ID, index)]; ok {
+				visit(spender)
+			}
+		}
+
+		collected = append(collected, txid)
+	}
+
+	for _, txid := range txids {
+		visit(txid)
+	}
+
+	return collected
+}
+
+// A method of TxPool that returns the number of transactions in the pool
+func (txpool *TxPool) Count() int {
+	// Acquire the read lock on the pool
+	txpool.mutex.RLock()
+	defer txpool.mutex.RUnlock()
+
+	return int(txpool.Pool.Count)
+}
+
+// A method of TxPool that returns the transactions in the pool in the order that
+// they entered it, which places every transaction after the transactions it spends
+func (txpool *TxPool) Transactions() []*PoolTxn {
+	// Acquire the read lock on the pool
+	txpool.mutex.RLock()
+	defer txpool.mutex.RUnlock()
+
+	return txpool.transactions()
+}
+
+// A method of TxPool that returns the transactions in the pool in the order
+// that they entered it. Must be called while holding the lock.
+func (txpool *TxPool) transactions() []*PoolTxn {
+	// Collect the entries of the pool
+	entries := make([]*PoolTxn, 0, txpool.Pool.Count)
+	txpool.Pool.Range(func(txid string, entry *PoolTxn) bool {
+		entries = append(entries, entry)
+		return true
+	})
+
+	// Sort the entries by the order they were added
+	sort.Slice(entries, func(i, j int) bool { return entries[i].sequence < entries[j].sequence })
+	return entries
+}
+
+// A method of TxPool that revalidates every transaction in the pool against the
+// current utxos of the chain and drops the transactions that are no longer valid,
+// such as those that were confirmed or double spent by a block. Returns the number
+// of transactions that were dropped.
+func (txpool *TxPool) Revalidate() int {
+	// Acquire the lock on the pool
+	txpool.mutex.Lock()
+	defer txpool.mutex.Unlock()
+
+	// Collect the transactions of the pool in order, while holding the lock
+	// so that no transaction added before the pool is emptied is lost
+	entries := txpool.transactions()
+
+	// Empty the pool and the spent outputs
+	txpool.Pool.Purge()
+	txpool.spends = make(map[string]string)
+
+	// Add the transactions back in order, dropping the invalid ones
+	return len(entries) - txpool.readd(entries)
+}
+
+// A method of TxPool that adds a set of entries to the pool in order and returns
+// the number of entries that were valid. Must be called while holding the lock.
+func (txpool *TxPool) readd(entries []*PoolTxn) int {
+	accepted := 0
+	for _, entry := range entries {
+		if err := txpool.add(entry); err == nil {
+			accepted++
+		}
+	}
+
+	return accepted
+}
+
+// A method of TxPool that writes the gob encoded transactions of the pool to a writer
+func (txpool *TxPool) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(txpool.Transactions())
+}
+
+// A method of TxPool that reads gob encoded transactions from a reader and adds them
+// to the pool after revalidating them against the current utxos of the chain.
+// Returns the number of transactions that were added and dropped.
+func (txpool *TxPool) Load(r io.Reader) (int, int, error) {
+	// Decode the transactions from the reader
+	var entries []*PoolTxn
+	if err := gob.NewDecoder(r).Decode(&entries); err != nil {
+		return 0, 0, fmt.Errorf("failed to read transaction pool! error - %v", err)
+	}
+
+	// Acquire the lock on the pool
+	txpool.mutex.Lock()
+	defer txpool.mutex.Unlock()
+
+	// Add the transactions in order, dropping the invalid ones
+	accepted := txpool.readd(entries)
+	return accepted, len(entries) - accepted, nil
+}
+
+// A method of TxPool that saves the transactions of the pool to a file.
+// The file is written to a temporary file first and then renamed over
+// the file, so that a crash while saving leaves the previous file intact.
+func (txpool *TxPool) SaveFile(path string) error {
+	// Create a temporary file in the directory of the file
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save transaction pool! error - %v", err)
+	}
+
+	// Remove the temporary file if it is not renamed
+	defer os.Remove(file.Name())
+
+	// Write the transactions to the temporary file and sync it
+	if err := txpool.Save(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save transaction pool! error - %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save transaction pool! error - %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to save transaction pool! error - %v", err)
+	}
+
+	// Rename the temporary file over the file
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to save transaction pool! error - %v", err)
+	}
+
+	return nil
+}
+
+// A method of TxPool that loads the transactions saved to a file into the pool.
+// A missing file is treated as an empty pool. Returns the number of transactions
+// that were added and dropped.
+func (txpool *TxPool) LoadFile(path string) (int, int, error) {
+	// Open the file
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load transaction pool! error - %v", err)
+	}
+
+	defer file.Close()
+
+	// Load the transactions from the file
+	return txpool.Load(file)
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+// A function that returns a new in-memory chain with a genesis reward for a wallet
+func testwalletchain(t *testing.T) (*BlockChain, *wallet.Wallet) {
+	w := wallet.NewWallet()
+	chain, err := NewBlockChainWithOptions(ChainOptions{InMemory: true, Params: testparams, Coinbase: *w.GenerateAddress(byte(0x00))})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	t.Cleanup(chain.CloseBuckets)
+	return chain, w
+}
+
+// A function that returns the coinbase transaction of the chain head
+func testcoinbase(t *testing.T, chain *BlockChain) *Transaction {
+	block, err := chain.GetBlock(chain.ChainHead)
+	if err != nil {
+		t.Fatalf("GetBlock() failed! %v", err)
+	}
+
+	return block.TXList[0]
+}
+
+// A function that returns a transaction signed by a wallet that spends an output of the wallet with a set of output values
+func testspend(w *wallet.Wallet, txid []byte, index int, values ...int) *Transaction {
+	address := *w.GenerateAddress(byte(0x00))
+	txn := &Transaction{Inputs: TXIList{{ID: txid, OutIndex: index, PublicKey: w.PublicKey}}}
+	for _, value := range values {
+		txn.Outputs = append(txn.Outputs, *NewTXO(value, address))
+	}
+
+	// Sign the input for the output of the wallet that it spends
+	if err := txn.Sign(w.PrivateKey, TXOList{*NewTXO(0, address)}); err != nil {
+		panic(err)
+	}
+
+	return txn
+}
+
+func Test_TxPoolValidation(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	txpool := NewTxPool(chain, DefaultTxPoolSize)
+
+	// A transaction that spends a utxo of the chain is accepted with its fee
+	parent := testspend(w, coinbase.ID, 0, 20)
+	if err := txpool.Add(parent); err != nil {
+		t.Fatalf("Add() failed! %v", err)
+	}
+	if entry, _ := txpool.Get(parent.ID); entry.Fee != 5 {
+		t.Fatalf("Add() failed! expected fee: 5, got: %v", entry.Fee)
+	}
+
+	// A transaction that spends a pooled transaction is accepted
+	child := testspend(w, parent.ID, 0, 19)
+	if err := txpool.Add(child); err != nil {
+		t.Fatalf("Add() failed! %v", err)
+	}
+
+	// A transaction with the key of the output but without a signature
+	unsigned := testspend(w, child.ID, 0, 10)
+	unsigned.Inputs[0].Signature = nil
+	unsigned.ID = unsigned.GenerateHash()
+
+	// A transaction with the key of the output but signed by another wallet
+	forged := testspend(wallet.NewWallet(), child.ID, 0, 10)
+	forged.Inputs[0].PublicKey = w.PublicKey
+	forged.ID = forged.GenerateHash()
+
+	// Invalid transactions are rejected
+	invalid := map[string]*Transaction{
+		"unsigned input":      unsigned,
+		"forged signature":    forged,
+		"low fee replacement": testspend(w, coinbase.ID, 0, 20, 1),
+		"missing output":      testspend(w, coinbase.ID, 1, 10),
+		"overspend":           testspend(w, child.ID, 0, 20),
+		"coinbase":            NewCoinbaseTransaction(testaddress(), 25),
+		"wrong key":           testspend(wallet.NewWallet(), child.ID, 0, 10),
+		"duplicate":           child,
+	}
+	for name, txn := range invalid {
+		if err := txpool.Add(txn); err == nil {
+			t.Fatalf("Add() failed! expected an error for a %v", name)
+		}
+	}
+
+	if txpool.Count() != 2 {
+		t.Fatalf("Count() failed! expected: 2, got: %v", txpool.Count())
+	}
+
+	// Transactions are ordered with their parents first
+	if txns := txpool.Transactions(); !bytes.Equal(txns[0].Txn.ID, parent.ID) || !bytes.Equal(txns[1].Txn.ID, child.ID) {
+		t.Fatalf("Transactions() failed! expected the parent before the child")
+	}
+
+	// Removing the parent leaves the child without an input
+	txpool.Remove(parent.ID)
+	if dropped := txpool.Revalidate(); dropped != 1 || txpool.Count() != 0 {
+		t.Fatalf("Revalidate() failed! expected: 1 dropped, got: %v", dropped)
+	}
+}
+
+func Test_TxPoolConcurrentRevalidate(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	txpool := NewTxPool(chain, DefaultTxPoolSize)
+
+	// Revalidate the pool while a chain of spends is added to it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			txpool.Revalidate()
+			txpool.Count()
+		}
+	}()
+
+	// No transaction is lost by a revalidation, so every spend finds its parent
+	txid := coinbase.ID
+	for value := 24; value > 4; value-- {
+		txn := testspend(w, txid, 0, value)
+		if err := txpool.Add(txn); err != nil {
+			t.Fatalf("Add() failed! %v", err)
+		}
+
+		txid = txn.ID
+	}
+
+	<-done
+	if txpool.Count() != 20 {
+		t.Fatalf("Revalidate() failed! expected: 20, got: %v", txpool.Count())
+	}
+}
+
+func Test_TxPoolPersistence(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	path := filepath.Join(t.TempDir(), "mempool.data")
+
+	// Save a pool with a chain of two transactions
+	txpool := NewTxPool(chain, DefaultTxPoolSize)
+	parent := testspend(w, coinbase.ID, 0, 20)
+	child := testspend(w, parent.ID, 0, 19)
+	txpool.Add(parent)
+	txpool.Add(child)
+
+	if err := txpool.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() failed! %v", err)
+	}
+
+	// Loading a missing file gives an empty pool
+	if added, dropped, err := NewTxPool(chain, DefaultTxPoolSize).LoadFile(path + ".missing"); err != nil || added != 0 || dropped != 0 {
+		t.Fatalf("LoadFile() failed! expected an empty pool, got: %v %v %v", added, dropped, err)
+	}
+
+	// The saved transactions are reloaded
+	reloaded := NewTxPool(chain, DefaultTxPoolSize)
+	if added, dropped, err := reloaded.LoadFile(path); err != nil || added != 2 || dropped != 0 {
+		t.Fatalf("LoadFile() failed! expected: 2 added, got: %v added, %v dropped, %v", added, dropped, err)
+	}
+
+	// A block that double spends the parent invalidates both transactions
+	address := testaddress()
+	chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward), testspend(w, coinbase.ID, 0, 25)}, address)
+
+	reloaded = NewTxPool(chain, DefaultTxPoolSize)
+	if added, dropped, err := reloaded.LoadFile(path); err != nil || added != 0 || dropped != 2 {
+		t.Fatalf("LoadFile() failed! expected: 2 dropped, got: %v added, %v dropped, %v", added, dropped, err)
+	}
+}
+
+func Test_TxPoolEviction(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	second := testreward(t, chain, w)
+	txpool := NewTxPool(chain, 2)
+
+	// Fill the pool with a parent with a fee of 1 and a child with a fee of 20
+	parent := testspend(w, coinbase.ID, 0, 24)
+	child := testspend(w, parent.ID, 0, 4)
+	txpool.Add(parent)
+	txpool.Add(child)
+
+	// A full pool rejects a transaction with a lower fee rate than the lowest
+	if err := txpool.Add(testspend(w, second.ID, 0, 25)); err == nil {
+		t.Fatalf("Add() failed! expected an error for a lower fee rate in a full pool")
+	}
+
+	// A full pool evicts the transaction with the lowest fee rate along with its child
+	other := testspend(w, second.ID, 0, 20)
+	if err := txpool.Add(other); err != nil {
+		t.Fatalf("Add() failed! %v", err)
+	}
+	if _, ok := txpool.Get(parent.ID); ok || txpool.Count() != 1 {
+		t.Fatalf("Add() failed! expected the parent to be evicted")
+	}
+	if _, ok := txpool.Get(child.ID); ok {
+		t.Fatalf("Add() failed! expected the child to be evicted with the parent")
+	}
+
+	// The pool is left without transactions that spend evicted transactions
+	if dropped := txpool.Revalidate(); dropped != 0 {
+		t.Fatalf("Revalidate() failed! expected: 0, got: %v", dropped)
+	}
+
+	// The outputs spent by the evicted transactions can be spent again
+	if err := txpool.Add(testspend(w, coinbase.ID, 0, 20)); err != nil {
+		t.Fatalf("Add() failed! %v", err)
+	}
+}
+
+// A function that adds a block with a coinbase for a wallet to a chain and returns the coinbase
+func testreward(t *testing.T, chain *BlockChain, w *wallet.Wallet) *Transaction {
+	coinbase := NewCoinbaseTransaction(*w.GenerateAddress(byte(0x00)), testparams.Reward)
+	chain.AddBlock([]*Transaction{coinbase}, testaddress())
+	return coinbase
+}
+
+func Test_TxPoolReplacement(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	txpool := NewTxPool(chain, DefaultTxPoolSize)
+
+	// Pool a parent with a fee of 5 and a child with a fee of 1
+	parent := testspend(w, coinbase.ID, 0, 20)
+	child := testspend(w, parent.ID, 0, 19)
+	txpool.Add(parent)
+	txpool.Add(child)
+
+	// A replacement must pay more than the parent and child together
+	if err := txpool.Add(testspend(w, coinbase.ID, 0, 19)); err == nil {
+		t.Fatalf("Add() failed! expected an error for a replacement with an equal fee")
+	}
+
+	// A replacement with a strictly higher fee evicts the parent and child
+	replacement := testspend(w, coinbase.ID, 0, 18)
+	if err := txpool.Add(replacement); err != nil {
+		t.Fatalf("Add() failed! %v", err)
+	}
+
+	_, hasparent := txpool.Get(parent.ID)
+	_, haschild := txpool.Get(child.ID)
+	if hasparent || haschild || txpool.Count() != 1 {
+		t.Fatalf("Add() failed! expected the replaced transactions to be removed")
+	}
+
+	// The outputs spent by the replaced transactions can be spent again
+	if err := txpool.Add(testspend(w, replacement.ID, 0, 17)); err != nil {
+		t.Fatalf("Add() failed! %v", err)
+	}
+}
@@ -3,74 +3,73 @@ package core
 import (
 	"bytes"
 	"encoding/hex"
+	"fmt"
 
-	"github.com/dgraph-io/badger"
+	"github.com/manishmeganathan/weave/persistence"
 	"github.com/manishmeganathan/weave/utils"
 	"github.com/sirupsen/logrus"
 )
 
-// A method of BlockChain that accumulates all unspent transactions on
-// the chain and returns them as a map transaction ID to TXOList.
-func (chain *BlockChain) AccumulateUTX0S() map[string]TXOList {
-	// Define a slice of UTXOs
+// A method of BlockChain that accumulates all unspent transactions on the chain
+// and returns them as a map transaction ID to TXOList. The utxo layer is replayed
+// from the blocks of the chain (see replayUTXOS), so the chain must not be pruned.
+// Returns an error if the blocks of the chain cannot be replayed.
+func (chain *BlockChain) AccumulateUTX0S() (map[string]TXOList, error) {
+	// Collect the hashes of the blocks of the chain
+	hashes, err := chain.blockhashes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Replay the utxo layer from the blocks
+	replayed, err := chain.replayUTXOS(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the utxos of the replayed utxo layer
 	utxos := make(map[string]TXOList)
-	// Define a map to store spent transaction outputs
-	spenttxos := make(map[string][]int)
-
-	// Get an iterator for the blockchain and iterate over its block
-	iter := NewIterator(chain)
-	for {
-		// Get a block from the iterator
-		block := iter.Next()
-
-		// Iterate over the transactions in the block
-		for _, tx := range block.TXList {
-			// Encode the transaction hash into a string
-			txid := hex.EncodeToString(tx.ID)
-
-		Outputs:
-			// Iterate over the transaction's outputs
-			for outindex, output := range tx.Outputs {
-				// Check if the transaction outputs have been spent
-				if spenttxos[txid] != nil {
-					// Iterate over the index of the spent transaction outputs
-					for _, spentout := range spenttxos[txid] {
-						// Check if the spent transaction output is the current transaction output
-						if spentout == outindex {
-							// Break from loop and check next transaction output
-							continue Outputs
-						}
-					}
-				}
+	_ = replayed.IteratePrefix(utils.UTXOprefix, func(key, value []byte) error {
+		var txolist TXOList
+		txolist.Deserialize(value)
 
-				// Retrieve the transaction output list from the map for the transaction ID
-				txolist := utxos[txid]
-				// Add the output to the list
-				txolist = append(txolist, output)
-				// Update the list on the map
-				utxos[txid] = txolist
-			}
+		utxos[hex.EncodeToString(key[len(utils.UTXOprefix):])] = txolist
+		return nil
+	})
 
-			// Check if the transaction is a coinbase transaction
-			if !tx.IsCoinbase() {
-				// Iterate over the transaction's inputs
-				for _, input := range tx.Inputs {
-					// Encode the ID of the transaction input (hash of the reference transaction output)
-					inputtxid := hex.EncodeToString(input.ID)
-					// Add the output index of the transaction input to spent transactions map
-					spenttxos[inputtxid] = append(spenttxos[inputtxid], input.OutIndex)
-				}
-			}
+	// Return the accumulated unspent transactions list
+	return utxos, nil
+}
+
+// A method of BlockChain that replays the utxo layer from the blocks with the given hashes,
+// which are in order of height from the genesis block. Every block is applied with applyUTXOS
+// to an in-memory store, exactly as it was when the block was connected, so that the outputs
+// spent by an input are found by their index in the compacted utxo list of the transaction.
+// Returns the replayed utxo layer or an error if a block cannot be read or applied.
+func (chain *BlockChain) replayUTXOS(hashes []utils.Hash) (*persistence.MemoryStore, error) {
+	// Create an in-memory store for the replayed utxo layer
+	replayed := persistence.NewMemoryStore()
+
+	// Apply the blocks in order of height
+	for height, hash := range hashes {
+		// Retrieve the block at the height
+		block, err := chain.GetBlock(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay block at height %v! error - %v", height, err)
 		}
 
-		// Check if the block is the genesis block and break from the loop
-		if block.BlockHeight == 0 {
-			break
+		// Apply the transactions of the block to the replayed utxo layer
+		err = replayed.Batch(func(batch persistence.Batch) error {
+			return applyUTXOS(batch, block)
+		})
+
+		// Handle any potential error
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay block at height %v! error - %v", height, err)
 		}
 	}
 
-	// Return the accumulated unspent transactions list
-	return utxos
+	return replayed, nil
 }
 
 // A method of BlockChain that collects the spendable transaction outputs
@@ -81,46 +80,30 @@ func (chain *BlockChain) CollectSpendableUTXOS(publickeyhash []byte, amount int)
 	// Declare an accumulation integer
 	accumulated := 0
 
-	// Define a View transaction on the database
-	_ = chain.State.Client.View(func(txn *badger.Txn) error {
-		// Start a database iterator with the default options
-		dbiterator := txn.NewIterator(badger.DefaultIteratorOptions)
-		// Defer the closing of the database
-		defer dbiterator.Close()
-
-		// Iterate over the database elements that are utxo items
-		for dbiterator.Seek(utils.UTXOprefix); dbiterator.ValidForPrefix(utils.UTXOprefix); dbiterator.Next() {
-			// Retrieve an item from the database iterator
-			item := dbiterator.Item()
-
-			// Retrieve the key of the item
-			key := item.Key()
-			// Trime the key to not have the utxo prefix
-			key = bytes.TrimPrefix(key, utils.UTXOprefix)
-			// Encode the key into the transaction ID
-			txnid := hex.EncodeToString(key)
-
-			// Declare a transaction output list
-			var outputs TXOList
-			// Retrieve the value of the item and
-			// deserialize it into the output list
-			_ = item.Value(func(val []byte) error {
-				outputs.Deserialize(val)
-				return nil
-			})
-
-			// Iterate over the transaction output list
-			for outindex, output := range outputs {
-				// Checl if the transaction output is locked by the public key
-				// and ensure that accumulation has not reached the amount target
-				if output.CheckLock(publickeyhash) && accumulated < amount {
-					// Add the value of the output into the accumulation
-					accumulated += output.Value
-					// Add the transaction output's ID and index to the map
-					unspenttxos[txnid] = append(unspenttxos[txnid], outindex)
-				}
+	// Iterate over the state elements that are utxo items
+	_ = chain.State.IteratePrefix(utils.UTXOprefix, func(key, value []byte) error {
+		// Trime the key to not have the utxo prefix
+		key = bytes.TrimPrefix(key, utils.UTXOprefix)
+		// Encode the key into the transaction ID
+		txnid := hex.EncodeToString(key)
+
+		// Declare a transaction output list and
+		// deserialize the value into the output list
+		var outputs TXOList
+		outputs.Deserialize(value)
+
+		// Iterate over the transaction output list
+		for outindex, output := range outputs {
+			// Checl if the transaction output is locked by the public key
+			// and ensure that accumulation has not reached the amount target
+			if output.CheckLock(publickeyhash) && accumulated < amount {
+				// Add the value of the output into the accumulation
+				accumulated += output.Value
+				// Add the transaction output's ID and index to the map
+				unspenttxos[txnid] = append(unspenttxos[txnid], outindex)
 			}
 		}
+
 		// Return a nil error
 		return nil
 	})
@@ -135,36 +118,22 @@ func (chain *BlockChain) FetchUTXOS(publickeyhash []byte) TXOList {
 	// Declare a transaction output list
 	var utxos TXOList
 
-	// Define a View transaction on the database
-	_ = chain.State.Client.View(func(txn *badger.Txn) error {
-		// Start a database iterator with the default options
-		dbiterator := txn.NewIterator(badger.DefaultIteratorOptions)
-		// Defer the closing of the database
-		defer dbiterator.Close()
-
-		// Iterate over the database elements that are utxo items
-		for dbiterator.Seek(utils.UTXOprefix); dbiterator.ValidForPrefix(utils.UTXOprefix); dbiterator.Next() {
-			// Retrieve the iterator item
-			item := dbiterator.Item()
-			// Declare a transaction output list
-			var txolist TXOList
-
-			// Retrieve the value of the item and deserialize
-			// into a transaction output list
-			_ = item.Value(func(val []byte) error {
-				txolist.Deserialize(val)
-				return nil
-			})
-
-			// Iterate over the transaction output list
-			for _, output := range txolist {
-				// Check if the transaction output is locked by the public key
-				if output.CheckLock(publickeyhash) {
-					// Add the transaction output to the list
-					utxos = append(utxos, output)
-				}
+	// Iterate over the state elements that are utxo items
+	_ = chain.State.IteratePrefix(utils.UTXOprefix, func(key, value []byte) error {
+		// Declare a transaction output list and
+		// deserialize the value into the output list
+		var txolist TXOList
+		txolist.Deserialize(value)
+
+		// Iterate over the transaction output list
+		for _, output := range txolist {
+			// Check if the transaction output is locked by the public key
+			if output.CheckLock(publickeyhash) {
+				// Add the transaction output to the list
+				utxos = append(utxos, output)
 			}
 		}
+
 		// Return a nil error
 		return nil
 	})
@@ -173,25 +142,39 @@ func (chain *BlockChain) FetchUTXOS(publickeyhash []byte) TXOList {
 	return utxos
 }
 
+// A method of BlockChain that returns the unspent output of the utxo layer for a transaction
+// ID and output index. Returns an error wrapping ErrMissingInputs if the transaction has no
+// utxos, or an error if the output index is not within its utxos.
+func (chain *BlockChain) GetUTXO(txid utils.Hash, index int) (TXO, error) {
+	// Retrieve the utxos of the transaction from the chain state
+	key := append(append([]byte{}, utils.UTXOprefix...), txid...)
+	value, err := chain.State.GetKey(key)
+	if err != nil {
+		return TXO{}, fmt.Errorf("%w %x:%d", ErrMissingInputs, txid, index)
+	}
+
+	// Deserialize the value into the output list
+	var outputs TXOList
+	outputs.Deserialize(value)
+
+	// Check that the output index is within the outputs
+	if index < 0 || index >= len(outputs) {
+		return TXO{}, fmt.Errorf("transaction input spends a missing output %x:%d", txid, index)
+	}
+
+	return outputs[index], nil
+}
+
 // A method of BlockChain that counts the number
 // of unspent transactions stored on the database
 func (chain *BlockChain) CountUTXOS() int {
 	// Declare a counter integer
 	counter := 0
 
-	// Define a View transaction on the database
-	_ = chain.State.Client.View(func(txn *badger.Txn) error {
-		// Start a database iterator with the default options
-		dbiterator := txn.NewIterator(badger.DefaultIteratorOptions)
-		// Defer the closing of the database
-		defer dbiterator.Close()
-
-		// Iterate over the database elements that are utxo items
-		for dbiterator.Seek(utils.UTXOprefix); dbiterator.ValidForPrefix(utils.UTXOprefix); dbiterator.Next() {
-			// Increment the counter for each item
-			counter++
-		}
-		// Return nil error
+	// Iterate over the state elements that are utxo items
+	_ = chain.State.IteratePrefix(utils.UTXOprefix, func(key, value []byte) error {
+		// Increment the counter for each item
+		counter++
 		return nil
 	})
 
@@ -199,16 +182,27 @@ func (chain *BlockChain) CountUTXOS() int {
 	return counter
 }
 
-// A method of BlockChain that reindexes
-// all the utxo layer keys on the database.
-func (chain *BlockChain) ReindexUTXOS() {
+// A method of BlockChain that reindexes all the utxo layer keys on the database.
+// The utxo layer is replayed from the blocks of the chain before the stored utxo
+// layer is replaced. Returns an error if the chain is pruned, if its blocks cannot
+// be replayed or if the replayed utxo layer cannot be written.
+func (chain *BlockChain) ReindexUTXOS() error {
+	// Check that the chain has all its block bodies
+	if err := chain.RequireFullChain("reindex utxos"); err != nil {
+		return err
+	}
+
+	// Accumulate all the UTXOs on the blockchain
+	utxos, err := chain.AccumulateUTX0S()
+	if err != nil {
+		return fmt.Errorf("failed to reindex utxos! error - %v", err)
+	}
+
 	// Delete all the UTXOs stored on the database
 	chain.State.DeleteKeyPrefix(utils.UTXOprefix)
-	// Accumulate all the UTXOs on the blockchain
-	utxos := chain.AccumulateUTX0S()
 
-	// Define an Update transaction on the database
-	err := chain.State.Client.Update(func(txn *badger.Txn) error {
+	// Define a batch on the state bucket
+	err = chain.State.Batch(func(batch persistence.Batch) error {
 		// Iterate over the UTXOs map
 		for txid, txolist := range utxos {
 			// Decode the transaction ID
@@ -219,12 +213,11 @@ func (chain *BlockChain) ReindexUTXOS() {
 			}
 
 			// Construct the key by adding the UTXO key prefix
-			key = append(utils.UTXOprefix, key...)
+			key = append(append([]byte{}, utils.UTXOprefix...), key...)
 			// Add the TXOList to the database with the key
-			err = txn.Set(key, txolist.Serialize())
-			if err != nil {
-				// Log a fatal error
-				logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to reindex utxos.")
+			if err = batch.SetKey(key, txolist.Serialize()); err != nil {
+				// Return an error if any
+				return err
 			}
 		}
 
@@ -234,95 +227,121 @@ func (chain *BlockChain) ReindexUTXOS() {
 
 	// Handle any potential error
 	if err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to reindex utxos.")
+		return fmt.Errorf("failed to reindex utxos! error - %v", err)
 	}
+
+	return nil
 }
 
 // A method of BlockChain that updates the utxo layer keys
 // from the transaction of a Block, given the block.
 func (chain *BlockChain) UpdateUTXOS(block *Block) {
-	// Define an Update transaction on the database
-	err := chain.State.Client.Update(func(dbtxn *badger.Txn) error {
-		// Iterate over the transactions in the block
-		for _, txn := range block.TXList {
-			// Verify that transaction is not a coinbase
-			if !txn.IsCoinbase() {
-				// Iterate over the transaction inputs
-				for _, input := range txn.Inputs {
-					// Create an empty transaction output list
-					updatedouts := TXOList{}
-
-					// Create the input ID from the utxo
-					// prefix and ID of the transaction input
-					inputid := append(utils.UTXOprefix, input.ID...)
-
-					// Retrieve a utxo item from the database
-					item, err := dbtxn.Get(inputid)
-					if err != nil {
-						// Log a fatal error
-						logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to update utxos.")
-					}
+	// Define a batch on the state bucket
+	err := chain.State.Batch(func(batch persistence.Batch) error {
+		// Apply the transactions of the block to the utxo layer
+		return applyUTXOS(batch, block)
+	})
+
+	// Handle any potential error
+	if err != nil {
+		// Log a fatal error
+		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to update utxos.")
+	}
+}
 
-					// Declare a transaction output list
-					var outputs TXOList
-					// Retrieve the value of the utxo item and
-					// deserialize it into the output list
-					err = item.Value(func(val []byte) error {
-						outputs.Deserialize(val)
-						return nil
-					})
-					// Handle any potential errors
+// A function that applies the transactions of a Block to the utxo layer keys
+// within a given batch. The updates are only persisted when the batch is
+// committed, which allows block connection to be performed with the rest
+// of the chain state in a single atomic update.
+//
+// The output indexes of the inputs refer to the utxo lists as they were before
+// the block (or as they were created by an earlier transaction of the block),
+// so the spent outputs are collected for every list before it is compacted.
+// Returns an error if an input spends an output that does not exist or an
+// output that is already spent by the block.
+func applyUTXOS(batch persistence.Batch, block *Block) error {
+	// Declare the utxo lists touched by the block, their spent
+	// output indexes and the order in which they were touched
+	lists := make(map[string]TXOList)
+	spent := make(map[string]map[int]bool)
+	var order []string
+
+	// Iterate over the transactions in the block
+	for _, txn := range block.TXList {
+		// Verify that transaction is not a coinbase
+		if !txn.IsCoinbase() {
+			// Iterate over the transaction inputs
+			for _, input := range txn.Inputs {
+				// Create the input ID from the utxo
+				// prefix and ID of the transaction input
+				inputid := string(append(append([]byte{}, utils.UTXOprefix...), input.ID...))
+
+				// Retrieve the utxo item from the database if it has not been touched
+				outputs, ok := lists[inputid]
+				if !ok {
+					value, err := batch.GetKey([]byte(inputid))
 					if err != nil {
-						// Log a fatal error
-						logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to update utxos.")
+						// Return the error
+						return fmt.Errorf("utxo retrieval failed! error - %v", err)
 					}
 
-					// Iterate over the transaction output list
-					for outindex, output := range outputs {
-						// Update the transaction outputs for the transaction ID from the input
-						if outindex != input.OutIndex {
-							updatedouts = append(updatedouts, output)
-						}
-					}
+					// Deserialize the value into the output list
+					outputs.Deserialize(value)
+					lists[inputid] = outputs
+					spent[inputid] = make(map[int]bool)
+					order = append(order, inputid)
+				}
 
-					// Check if there are any transactions in the updated list
-					if len(updatedouts) == 0 {
-						// Delete all transaction outputs in the utxo item on the db
-						if err := dbtxn.Delete(inputid); err != nil {
-							// Log a fatal error
-							logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to update utxos.")
-						}
-					} else {
-						// Set the utxo item to the updated list of transaction outputs
-						if err := dbtxn.Set(inputid, updatedouts.Serialize()); err != nil {
-							// Log a fatal error
-							logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to update utxos.")
-						}
-					}
+				// Check that the output exists and has not been spent by the block
+				if input.OutIndex < 0 || input.OutIndex >= len(outputs) {
+					return fmt.Errorf("utxo %x:%d does not exist", input.ID, input.OutIndex)
+				}
+				if spent[inputid][input.OutIndex] {
+					return fmt.Errorf("utxo %x:%d is spent twice", input.ID, input.OutIndex)
 				}
+
+				// Mark the output as spent
+				spent[inputid][input.OutIndex] = true
 			}
+		}
+
+		// Create the utxo item key from the utxo prefix and transaction ID
+		txnid := string(append(append([]byte{}, utils.UTXOprefix...), txn.ID...))
+		if _, ok := lists[txnid]; !ok {
+			order = append(order, txnid)
+		}
+
+		// Accumulate the transaction outputs to a new list
+		lists[txnid] = append(TXOList{}, txn.Outputs...)
+		spent[txnid] = make(map[int]bool)
+	}
 
-			// Create a new transaction output list
-			newoutputs := TXOList{}
-			// Accumulate the transaction outputs to the list
-			newoutputs = append(newoutputs, txn.Outputs...)
-
-			// Create the utxo item key from the utxo prefix and transaction ID
-			txnid := append(utils.UTXOprefix, txn.ID...)
-			// Add the list of transaction outputs to the db
-			if err := dbtxn.Set(txnid, newoutputs.Serialize()); err != nil {
-				// Log a fatal error
-				logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to update utxos.")
+	// Iterate over the touched utxo items in order
+	for _, key := range order {
+		// Collect the outputs of the item that were not spent
+		updatedouts := TXOList{}
+		for outindex, output := range lists[key] {
+			if !spent[key][outindex] {
+				updatedouts = append(updatedouts, output)
 			}
 		}
-		// Return a nil error
-		return nil
-	})
 
-	// Handle any potential error
-	if err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to update utxos.")
+		// Check if there are any transactions in the updated list
+		if len(updatedouts) == 0 {
+			// Delete all transaction outputs in the utxo item on the db
+			if err := batch.DeleteKey([]byte(key)); err != nil {
+				// Return the error
+				return err
+			}
+		} else {
+			// Set the utxo item to the updated list of transaction outputs
+			if err := batch.SetKey([]byte(key), updatedouts.Serialize()); err != nil {
+				// Return the error
+				return err
+			}
+		}
 	}
+
+	// Return a nil error
+	return nil
 }
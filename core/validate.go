@@ -0,0 +1,173 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/merkle"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A method of BlockChain that validates a Block against the tip of the chain before it
+// is connected. Checks that the block extends the chain head at the next height, that
+// its header satisfies the proof of work for the chain difficulty, that its merkle root
+// and header history root are correct and that it starts with its only coinbase, which
+// claims no more than the block reward and the fees of the block. Every output of every
+// transaction (including the coinbase) must have a positive value. Every spend of the block
+// must be of an unspent output, at most once, and signed by the key that locks the output.
+func (chain *BlockChain) ValidateBlock(block *Block) error {
+	// Check that the block is at the next height
+	if block.BlockHeight != chain.ChainHeight {
+		return fmt.Errorf("block height %v does not extend chain height %v", block.BlockHeight, chain.ChainHeight)
+	}
+
+	// Check that the block links to the current chain head
+	if !bytes.Equal(block.Priori, chain.ChainHead) {
+		return fmt.Errorf("block priori does not match the chain head")
+	}
+
+	// Check the header and its proof of work
+	if err := ValidateHeader(&block.BlockHeader, block.BlockHash, chain.Params.Difficulty); err != nil {
+		return err
+	}
+
+	// Check that the block commits to the header history of the chain
+	history, err := chain.History.Root()
+	if err != nil {
+		return fmt.Errorf("failed to get header history root! error - %v", err)
+	}
+	if !bytes.Equal(block.HistoryRoot, history) {
+		return fmt.Errorf("block history root does not match the chain header history")
+	}
+
+	// Check that the transaction count matches the transaction list
+	if block.TXCount != len(block.TXList) || block.TXCount == 0 {
+		return fmt.Errorf("block has an invalid transaction count of %v", block.TXCount)
+	}
+
+	// Check the transactions of the block
+	for index, txn := range block.TXList {
+		// Check that only the first transaction is a coinbase
+		if txn.IsCoinbase() != (index == 0) {
+			return fmt.Errorf("block transaction %v has an invalid coinbase", index)
+		}
+
+		// Check that the transaction ID is the hash of the transaction
+		if !bytes.Equal(txn.ID, txn.GenerateHash()) {
+			return fmt.Errorf("block transaction %v does not match its ID", index)
+		}
+
+		// Check that every output of the transaction has a positive value
+		for _, output := range txn.Outputs {
+			if output.Value <= 0 {
+				return fmt.Errorf("block transaction %v has an output with an invalid value", index)
+			}
+		}
+	}
+
+	// Accumulate the fees of the block
+	fees, err := chain.blockfees(block)
+	if err != nil {
+		return err
+	}
+
+	// Check that the coinbase does not exceed the block reward and fees
+	reward := 0
+	for _, output := range block.TXList[0].Outputs {
+		reward += output.Value
+	}
+	if reward > chain.Params.Reward+fees {
+		return fmt.Errorf("block coinbase of %v exceeds the block reward and fees", reward)
+	}
+
+	// Rebuild the merkle tree of the transactions
+	items := make([]utils.GobEncodable, len(block.TXList))
+	for index, txn := range block.TXList {
+		items[index] = txn
+	}
+
+	merkletree := merkle.NewMerkleTree()
+	merkletree.BuildFull(items)
+	merkletree.BuildGroup.Wait()
+
+	// Check that the merkle root matches the transactions
+	if !bytes.Equal(block.MerkleRoot, merkletree.MerkleRoot) {
+		return fmt.Errorf("block merkle root does not match its transactions")
+	}
+
+	// Return a nil error
+	return nil
+}
+
+// A method of BlockChain that returns the combined fees of the transactions of a block.
+// Inputs are valued from the utxo layer of the chain or the outputs of earlier
+// transactions in the block and must be signed by the key that locks the output they
+// spend. Returns an error if a transaction spends more than its inputs, spends an output
+// that cannot be found or that is already spent by the block, or has an invalid signature.
+func (chain *BlockChain) blockfees(block *Block) (int, error) {
+	// Collect the outputs of the transactions of the block and the outputs they spend
+	outputs := make(map[string]TXOList)
+	spends := make(map[string]bool)
+	fees := 0
+
+	for index, txn := range block.TXList {
+		// Add the outputs of the transaction for the transactions after it
+		outputs[hex.EncodeToString(txn.ID)] = txn.Outputs
+		if txn.IsCoinbase() {
+			continue
+		}
+
+		// Accumulate the value of the inputs
+		invalue := 0
+		var txnspent TXOList
+		for _, input := range txn.Inputs {
+			// Check that the output is not spent twice by the block
+			key := outpoint(input.ID, input.OutIndex)
+			if spends[key] {
+				return 0, fmt.Errorf("block transaction %v spends output %v that is already spent", index, key)
+			}
+			spends[key] = true
+
+			// Retrieve the outputs of the transaction spent by the input
+			spent, ok := outputs[hex.EncodeToString(input.ID)]
+			if !ok {
+				key := append(append([]byte{}, utils.UTXOprefix...), input.ID...)
+				value, err := chain.State.GetKey(key)
+				if err != nil {
+					return 0, fmt.Errorf("block transaction %v spends a missing output", index)
+				}
+
+				spent.Deserialize(value)
+			}
+
+			// Check that the output index is within the outputs
+			if input.OutIndex < 0 || input.OutIndex >= len(spent) {
+				return 0, fmt.Errorf("block transaction %v spends a missing output", index)
+			}
+
+			txnspent = append(txnspent, spent[input.OutIndex])
+			invalue += spent[input.OutIndex].Value
+		}
+
+		// Check the signatures of the inputs
+		if err := txn.Verify(txnspent); err != nil {
+			return 0, fmt.Errorf("block transaction %v is invalid! error - %v", index, err)
+		}
+
+		// Accumulate the value of the outputs
+		outvalue := 0
+		for _, output := range txn.Outputs {
+			outvalue += output.Value
+		}
+
+		// Check that the inputs cover the outputs
+		if invalue < outvalue {
+			return 0, fmt.Errorf("block transaction %v spends more than its inputs", index)
+		}
+
+		fees += invalue - outvalue
+	}
+
+	return fees, nil
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+func Test_BlockSpends(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	reward := NewCoinbaseTransaction(testaddress(), testparams.Reward)
+
+	// A block that spends an output and the output of an earlier transaction of the block is valid
+	parent := testspend(w, coinbase.ID, 0, 20)
+	child := testspend(w, parent.ID, 0, 19)
+	if fees, err := chain.blockfees(&Block{TXList: []*Transaction{reward, parent, child}}); err != nil || fees != 6 {
+		t.Fatalf("blockfees() failed! expected: 6, got: %v %v", fees, err)
+	}
+
+	// A transaction with the key of the output but signed by another wallet
+	forged := testspend(wallet.NewWallet(), coinbase.ID, 0, 20)
+	forged.Inputs[0].PublicKey = w.PublicKey
+	forged.ID = forged.GenerateHash()
+
+	// Invalid spends are rejected
+	invalid := map[string][]*Transaction{
+		"double spend":     {reward, parent, testspend(w, coinbase.ID, 0, 10)},
+		"forged signature": {reward, forged},
+		"missing output":   {reward, testspend(w, coinbase.ID, 1, 10)},
+	}
+	for name, txns := range invalid {
+		if _, err := chain.blockfees(&Block{TXList: txns}); err == nil {
+			t.Fatalf("blockfees() failed! expected an error for a %v", name)
+		}
+	}
+
+	// The utxo layer rejects spends of outputs that do not exist or were already spent
+	for name, txns := range invalid {
+		if name == "forged signature" {
+			continue
+		}
+
+		err := chain.State.Batch(func(batch persistence.Batch) error {
+			return applyUTXOS(batch, &Block{TXList: txns})
+		})
+		if err == nil {
+			t.Fatalf("applyUTXOS() failed! expected an error for a %v", name)
+		}
+	}
+
+	// The rejected batches leave the utxo of the chain unspent
+	if utxos := chain.FetchUTXOS(w.GenerateAddress(byte(0x00)).PublicKeyHash); len(utxos) != 1 {
+		t.Fatalf("FetchUTXOS() failed! expected: 1, got: %v", len(utxos))
+	}
+}
+
+func Test_BlockOutputValues(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	address := testaddress()
+
+	// A coinbase that offsets an inflated output with a negative output
+	inflated := NewCoinbaseTransaction(address, testparams.Reward+1000)
+	inflated.Outputs = append(inflated.Outputs, *NewTXO(-1000, address))
+	inflated.ID = inflated.GenerateHash()
+
+	// Blocks with outputs that do not have a positive value are rejected
+	invalid := map[string][]*Transaction{
+		"negative output":          {NewCoinbaseTransaction(address, testparams.Reward), testspend(w, coinbase.ID, 0, 1000, -990)},
+		"zero output":              {NewCoinbaseTransaction(address, testparams.Reward), testspend(w, coinbase.ID, 0, 20, 0)},
+		"negative coinbase output": {inflated},
+	}
+	for name, txns := range invalid {
+		if err := chain.ValidateBlock(testnewblock(chain, txns)); err == nil {
+			t.Fatalf("ValidateBlock() failed! expected an error for a %v", name)
+		}
+	}
+
+	// A block with positive outputs is valid
+	valid := testnewblock(chain, []*Transaction{NewCoinbaseTransaction(address, testparams.Reward), testspend(w, coinbase.ID, 0, 10, 10)})
+	if err := chain.ValidateBlock(valid); err != nil {
+		t.Fatalf("ValidateBlock() failed! %v", err)
+	}
+}
@@ -0,0 +1,400 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/merkle"
+	"github.com/manishmeganathan/weave/persistence"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// A structure that represents the result of an integrity check of a chain database
+type IntegrityReport struct {
+	// Represents the hash of the chain head that was verified
+	ChainHead utils.Hash
+
+	// Represents the height of the chain after the verified chain head
+	ChainHeight int
+
+	// Represents the number of blocks that were verified
+	Blocks int
+
+	// Represents the problems that were found in the chain database
+	Problems []string
+
+	// Represents whether the block data is intact and derived state can be rebuilt from it
+	Recoverable bool
+
+	// Represents whether the derived state of the chain was rebuilt
+	Repaired bool
+}
+
+// A method of IntegrityReport that returns whether no problems were found
+func (report *IntegrityReport) OK() bool {
+	return len(report.Problems) == 0
+}
+
+// A method of IntegrityReport that records a problem in the chain database
+func (report *IntegrityReport) problem(format string, args ...interface{}) {
+	report.Problems = append(report.Problems, fmt.Sprintf(format, args...))
+}
+
+// A function that checks the integrity of the chain database from the config file with
+// the default params. The chain state is not required to be readable, which allows a
+// database with a missing chain head or height to be verified and optionally repaired.
+func VerifyBlockChain(repair bool) (*IntegrityReport, error) {
+	// Check if a blockchain db exists
+	if !persistence.CheckDatabase() {
+		return nil, fmt.Errorf("database does not exist")
+	}
+
+	// Create a null blockchain with the default params
+	chain := newchain(DefaultChainParams())
+	// Open the database clients for all buckets
+	chain.OpenBuckets()
+	// Defer the closing of the database clients
+	defer chain.CloseBuckets()
+
+	// Verify the chain
+	return chain.Verify(repair), nil
+}
+
+// A method of BlockChain that checks the integrity of its database.
+//
+// Every block header is checked for its hash and proof of work, and the chain
+// is walked from its head to the genesis checking the linkage and heights of
+// the blocks along with the merkle roots of their bodies. The derived state of
+// the chain (chain head and height, header history, utxo layer and filters) is
+// then recomputed from the blocks and compared to the stored state (the utxo layer
+// of a pruned chain cannot be recomputed and is not checked). The head is recovered
+// from the highest valid header if the stored head is unusable.
+//
+// If repair is set and the blocks are intact, the derived state is rebuilt from
+// the blocks and the chain is verified again. The chain does not need to have
+// been set up, but its stores must be open.
+func (chain *BlockChain) Verify(repair bool) *IntegrityReport {
+	// Verify the chain
+	report := chain.verify()
+
+	// Check if the chain should be repaired
+	if !repair || report.OK() {
+		return report
+	}
+
+	// Check if the chain can be repaired
+	if !report.Recoverable {
+		report.problem("chain cannot be repaired from its blocks")
+		return report
+	}
+
+	// Rebuild the derived state of the chain
+	if err := chain.rebuild(report); err != nil {
+		report.problem("chain could not be rebuilt: %v", err)
+		return report
+	}
+
+	// Verify the rebuilt chain again
+	repaired := chain.verify()
+	repaired.Repaired = true
+
+	// Return the report after the repair
+	return repaired
+}
+
+// A method of BlockChain that checks the integrity of its database and returns the report.
+// The chain head and height of the chain object are set to the verified chain head.
+func (chain *BlockChain) verify() *IntegrityReport {
+	// Create an empty report
+	report := &IntegrityReport{}
+
+	// Collect and check every header from the blocks bucket
+	headers := make(map[string]*HeaderEntry)
+	err := chain.Blocks.IteratePrefix(utils.Headerprefix, func(key, value []byte) error {
+		// Deserialize the header entry
+		entry := NullHeaderEntry()
+		entry.Deserialize(value)
+
+		// Check that the header is stored under its own hash
+		if !bytes.Equal(key[len(utils.Headerprefix):], entry.BlockHash) {
+			report.problem("header %x is stored under the wrong key", entry.BlockHash)
+			return nil
+		}
+
+		// Check the header and its proof of work
+		if err := ValidateHeader(&entry.BlockHeader, entry.BlockHash, chain.Params.Difficulty); err != nil {
+			report.problem("header %x at height %v is invalid: %v", entry.BlockHash, entry.BlockHeight, err)
+			return nil
+		}
+
+		// Add the valid header to the map
+		headers[hex.EncodeToString(entry.BlockHash)] = entry
+		return nil
+	})
+
+	// Handle any potential error
+	if err != nil {
+		report.problem("failed to read headers: %v", err)
+		return report
+	}
+
+	// Determine the head of the chain
+	head := chain.verifyhead(report, headers)
+	if head == nil {
+		report.problem("no valid chain head could be found")
+		return report
+	}
+
+	// Assign the verified chain head and height
+	report.ChainHead, report.ChainHeight = head.BlockHash, head.BlockHeight+1
+	chain.ChainHead, chain.ChainHeight = report.ChainHead, report.ChainHeight
+
+	// Get the pruned height of the chain
+	if value, err := chain.State.GetKey(utils.PrunedHeightKey); err == nil {
+		if chain.PrunedHeight, err = utils.IntDecode(value); err != nil {
+			report.problem("pruned height in state is corrupt")
+			return report
+		}
+	}
+
+	// Walk the chain from the head to the genesis
+	hashes := make([]utils.Hash, report.ChainHeight)
+	bodies := true
+	for entry := head; ; {
+		// Record the block hash at its height
+		hashes[entry.BlockHeight] = entry.BlockHash
+		report.Blocks++
+
+		// Check the body of the block if it has not been pruned
+		if entry.BlockHeight >= chain.PrunedHeight && !chain.verifybody(report, entry) {
+			bodies = false
+		}
+
+		// Check if the block is the genesis block
+		if entry.BlockHeight == 0 {
+			if len(entry.Priori) != 0 {
+				report.problem("genesis block %x has a priori", entry.BlockHash)
+				return report
+			}
+
+			break
+		}
+
+		// Retrieve the header of the previous block
+		priori, ok := headers[hex.EncodeToString(entry.Priori)]
+		if !ok {
+			report.problem("block at height %v links to a missing or invalid block", entry.BlockHeight)
+			return report
+		}
+
+		// Check that the previous block is at the height below
+		if priori.BlockHeight != entry.BlockHeight-1 {
+			report.problem("block at height %v links to a block at height %v", entry.BlockHeight, priori.BlockHeight)
+			return report
+		}
+
+		entry = priori
+	}
+
+	// The headers link from the head to the genesis and the bodies are intact
+	report.Recoverable = bodies
+
+	// Check the header history against the block hashes
+	chain.verifyhistory(report, hashes)
+
+	// Check the utxo layer and the filters if the bodies are available
+	if bodies && !chain.IsPruned() {
+		chain.verifyutxos(report, hashes)
+	}
+	if bodies {
+		for height := chain.PrunedHeight; height < report.ChainHeight; height++ {
+			if _, err := chain.GetBlockFilter(hashes[height]); err != nil {
+				report.problem("block at height %v has no filter", height)
+			}
+		}
+	}
+
+	// Return the report
+	return report
+}
+
+// A method of BlockChain that determines the head of the chain for an integrity check.
+// The stored chain head and height are used if they refer to a valid header, otherwise
+// the highest valid header is used as the recovered chain head.
+func (chain *BlockChain) verifyhead(report *IntegrityReport, headers map[string]*HeaderEntry) *HeaderEntry {
+	// Check the stored chain head
+	chainhead, err := chain.State.GetKey(utils.ChainHeadKey)
+	if err != nil {
+		report.problem("chain head is missing from state")
+	} else if head, ok := headers[hex.EncodeToString(chainhead)]; !ok {
+		report.problem("chain head %x is missing or invalid", chainhead)
+	} else {
+		// Check the stored chain height against the chain head
+		if chainheight, err := chain.State.GetKey(utils.ChainHeightKey); err != nil {
+			report.problem("chain height is missing from state")
+		} else if height, err := utils.IntDecode(chainheight); err != nil {
+			report.problem("chain height in state is corrupt")
+		} else if height != head.BlockHeight+1 {
+			report.problem("chain height %v does not match the chain head at height %v", height, head.BlockHeight)
+		}
+
+		return head
+	}
+
+	// Recover the chain head from the highest valid header
+	var head *HeaderEntry
+	for _, entry := range headers {
+		if head == nil || entry.BlockHeight > head.BlockHeight {
+			head = entry
+		}
+	}
+
+	return head
+}
+
+// A method of BlockChain that checks the body of a block for an integrity check.
+// Returns whether the body is present and matches the merkle root of its header.
+func (chain *BlockChain) verifybody(report *IntegrityReport, entry *HeaderEntry) bool {
+	// Retrieve the body of the block
+	body, err := chain.GetBody(entry.BlockHash)
+	if err != nil {
+		report.problem("block at height %v has no body", entry.BlockHeight)
+		return false
+	}
+
+	// Check the transaction count of the body
+	if body.TXCount != len(body.TXList) {
+		report.problem("block at height %v has an invalid transaction count", entry.BlockHeight)
+		return false
+	}
+
+	// Rebuild the merkle tree of the transactions
+	items := make([]utils.GobEncodable, len(body.TXList))
+	for index, txn := range body.TXList {
+		items[index] = txn
+	}
+
+	merkletree := merkle.NewMerkleTree()
+	merkletree.BuildFull(items)
+	merkletree.BuildGroup.Wait()
+
+	// Check that the merkle root matches the transactions
+	if !bytes.Equal(entry.MerkleRoot, merkletree.MerkleRoot) {
+		report.problem("block at height %v has a body that does not match its merkle root", entry.BlockHeight)
+		return false
+	}
+
+	return true
+}
+
+// A method of BlockChain that checks the stored header history
+// against the block hashes of the chain for an integrity check.
+func (chain *BlockChain) verifyhistory(report *IntegrityReport, hashes []utils.Hash) {
+	// Get the number of history leaves from the state bucket
+	value, err := chain.State.GetKey(utils.MMRLeavesKey)
+	if err != nil {
+		report.problem("header history leaves are missing from state")
+		return
+	}
+	if leaves, err := utils.IntDecode(value); err != nil || leaves != len(hashes) {
+		report.problem("header history does not have a leaf for every block")
+		return
+	}
+
+	// Build the expected header history in memory
+	expected := merkle.NewMMR(merkle.NewMemoryMMRStore(), 0)
+	for _, hash := range hashes {
+		expected.Append(hash)
+	}
+
+	// Compare the root of the stored header history to the expected root
+	stored, err := merkle.NewMMR(&historystore{store: chain.State}, uint64(len(hashes))).Root()
+	root, _ := expected.Root()
+	if err != nil || !bytes.Equal(stored, root) {
+		report.problem("header history does not match the blocks")
+	}
+}
+
+// A method of BlockChain that replays the utxo layer from the blocks with
+// the given hashes and compares it to the stored utxo layer.
+func (chain *BlockChain) verifyutxos(report *IntegrityReport, hashes []utils.Hash) {
+	// Replay the utxo layer from the blocks
+	replayed, err := chain.replayUTXOS(hashes)
+	if err != nil {
+		report.problem("utxo layer cannot be replayed from the blocks: %v", err)
+		return
+	}
+
+	// Compare the stored utxos against the replayed utxos
+	stored := 0
+	_ = chain.State.IteratePrefix(utils.UTXOprefix, func(key, value []byte) error {
+		stored++
+
+		expected, err := replayed.GetKey(key)
+		if err != nil || !bytes.Equal(expected, value) {
+			report.problem("utxo %x does not match the blocks", key[len(utils.UTXOprefix):])
+		}
+
+		return nil
+	})
+
+	// Check that no replayed utxos are missing from the stored utxos
+	count := 0
+	_ = replayed.IteratePrefix(utils.UTXOprefix, func(key, value []byte) error {
+		count++
+		return nil
+	})
+
+	if stored != count {
+		report.problem("utxo layer has %v entries but the blocks have %v", stored, count)
+	}
+}
+
+// A method of BlockChain that rebuilds the derived state of the chain from its blocks.
+// The chain head and height are set to the verified head and the header history, utxo
+// layer, filters and transaction index are reindexed. Pruned chains only have their header
+// history and the transactions of the blocks with a body reindexed. Returns an error if
+// the header history, chain head or utxo layer cannot be rebuilt.
+func (chain *BlockChain) rebuild(report *IntegrityReport) error {
+	// Log the repair of the chain
+	logrus.WithFields(logrus.Fields{"chainhead": fmt.Sprintf("%x", report.ChainHead), "height": report.ChainHeight}).Info("rebuilding chain state.")
+
+	// Set the verified chain head and height to the state bucket
+	err := chain.State.Batch(func(batch persistence.Batch) error {
+		if err := batch.SetKey(utils.ChainHeadKey, report.ChainHead); err != nil {
+			return err
+		}
+
+		return batch.SetKey(utils.ChainHeightKey, utils.IntEncode(report.ChainHeight))
+	})
+
+	// Handle any potential error
+	if err != nil {
+		return fmt.Errorf("failed to repair chain head! error - %v", err)
+	}
+
+	// Reindex the header history
+	if err := chain.ReindexHistory(); err != nil {
+		return err
+	}
+	// Reindex the transactions of the blocks with a body
+	if err := chain.ReindexTxns(); err != nil {
+		return err
+	}
+
+	// Reindex the utxo layer and filters if the chain has all its block bodies
+	if !chain.IsPruned() {
+		if err := chain.ReindexUTXOS(); err != nil {
+			return err
+		}
+
+		if err := chain.ReindexFilters(); err != nil {
+			return err
+		}
+	}
+
+	// Set the indexed height to the repaired chain height
+	return chain.setindexed()
+}
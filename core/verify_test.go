@@ -0,0 +1,81 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+func Test_VerifyChain(t *testing.T) {
+	t.Parallel()
+	chain := testchain(t)
+
+	address := testaddress()
+	for i := 0; i < 3; i++ {
+		chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+
+	if report := chain.Verify(false); !report.OK() || report.Blocks != 4 {
+		t.Fatalf("Verify() failed! expected no problems in 4 blocks, got: %v in %v blocks", report.Problems, report.Blocks)
+	}
+
+	// Remove the chain head and the utxo layer from the state
+	head := chain.ChainHead
+	chain.State.DeleteKey(utils.ChainHeadKey)
+	chain.State.DeleteKeyPrefix(utils.UTXOprefix)
+
+	if report := chain.Verify(false); report.OK() || !report.Recoverable {
+		t.Fatalf("Verify() failed! expected recoverable problems, got: %v", report.Problems)
+	}
+
+	report := chain.Verify(true)
+	if !report.OK() || !report.Repaired {
+		t.Fatalf("Verify() repair failed! got: %v", report.Problems)
+	}
+	if value, err := chain.State.GetKey(utils.ChainHeadKey); err != nil || string(value) != string(head) || chain.CountUTXOS() != 4 {
+		t.Fatalf("Verify() repair failed! chain head or utxos were not restored")
+	}
+
+	// A block with a missing body cannot be repaired
+	chain.Blocks.DeleteKey(bodykey(head))
+	if report := chain.Verify(true); report.OK() || report.Recoverable || report.Repaired {
+		t.Fatalf("Verify() failed! expected an unrecoverable problem, got: %v", report.Problems)
+	}
+}
+
+func Test_VerifyPartialSpends(t *testing.T) {
+	t.Parallel()
+	chain, w := testwalletchain(t)
+	coinbase := testcoinbase(t, chain)
+	address := testaddress()
+
+	// Split the genesis reward into outputs of different values
+	split := testspend(w, coinbase.ID, 0, 3, 4, 5)
+	chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward), split}, address)
+
+	// Spend two outputs of the split in separate blocks. The index of an input is into the
+	// compacted list of unspent outputs, so the second spend of index 1 spends the value 5
+	chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward), testspend(w, split.ID, 0, 3)}, address)
+	chain.AddBlock([]*Transaction{NewCoinbaseTransaction(address, testparams.Reward), testspend(w, split.ID, 1, 5)}, address)
+
+	if utxo, err := chain.GetUTXO(split.ID, 0); err != nil || utxo.Value != 4 {
+		t.Fatalf("GetUTXO() failed! expected: 4, got: %v (%v)", utxo.Value, err)
+	}
+	if _, err := chain.GetUTXO(split.ID, 1); err == nil {
+		t.Fatalf("GetUTXO() failed! expected a single unspent output")
+	}
+
+	// The utxo layer replayed from the blocks matches the stored utxo layer
+	if report := chain.Verify(false); !report.OK() {
+		t.Fatalf("Verify() failed! expected no problems, got: %v", report.Problems)
+	}
+
+	// The utxo layer is rebuilt with the outputs that are left by the spends
+	chain.State.DeleteKeyPrefix(utils.UTXOprefix)
+	if report := chain.Verify(true); !report.OK() || !report.Repaired {
+		t.Fatalf("Verify() repair failed! got: %v", report.Problems)
+	}
+	if utxo, err := chain.GetUTXO(split.ID, 0); err != nil || utxo.Value != 4 {
+		t.Fatalf("GetUTXO() failed! expected: 4 after the repair, got: %v (%v)", utxo.Value, err)
+	}
+}
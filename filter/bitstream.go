@@ -0,0 +1,76 @@
+package filter
+
+import "fmt"
+
+// A structure that represents a writer of a stream of bits
+type bitwriter struct {
+	// Represents the bytes written to the stream
+	stream []byte
+	// Represents the number of bits remaining in the last byte of the stream
+	remaining uint8
+}
+
+// A method of bitwriter that writes a single bit to the stream
+func (bw *bitwriter) writebit(bit bool) {
+	// Check if the last byte of the stream is full
+	if bw.remaining == 0 {
+		// Add a new byte to the stream
+		bw.stream = append(bw.stream, 0)
+		bw.remaining = 8
+	}
+
+	// Set the bit on the last byte of the stream
+	bw.remaining--
+	if bit {
+		bw.stream[len(bw.stream)-1] |= 1 << bw.remaining
+	}
+}
+
+// A method of bitwriter that writes the given number of low bits of a value to the stream
+func (bw *bitwriter) writebits(value uint64, count uint8) {
+	// Write the bits from the most significant bit
+	for count > 0 {
+		count--
+		bw.writebit(value&(1<<count) != 0)
+	}
+}
+
+// A structure that represents a reader of a stream of bits
+type bitreader struct {
+	// Represents the bytes of the stream
+	stream []byte
+	// Represents the position of the next bit to read
+	position uint64
+}
+
+// A method of bitreader that reads a single bit from the stream
+func (br *bitreader) readbit() (bool, error) {
+	// Check if the stream has been exhausted
+	if br.position >= uint64(len(br.stream))*8 {
+		return false, fmt.Errorf("bit stream exhausted")
+	}
+
+	// Read the bit from its byte
+	bit := br.stream[br.position/8]&(1<<(7-br.position%8)) != 0
+	br.position++
+
+	return bit, nil
+}
+
+// A method of bitreader that reads a value with the given number of bits from the stream
+func (br *bitreader) readbits(count uint8) (uint64, error) {
+	var value uint64
+	for ; count > 0; count-- {
+		bit, err := br.readbit()
+		if err != nil {
+			return 0, err
+		}
+
+		value <<= 1
+		if bit {
+			value |= 1
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,209 @@
+/*
+This module contains the implementation of compact block filters that are
+built as Golomb-coded sets (GCS). A filter is built over a set of items for a
+block and allows a light wallet to test whether any of its items are likely to
+be in the block without revealing its items to the full node that serves it.
+
+The construction is modelled on BIP158 with the same parameters P and M. Each item
+is hashed with a key derived from the block hash and mapped uniformly onto the range
+[0, N*M). The sorted values are delta encoded with Golomb-Rice coding using a parameter
+P. False positives occur at a rate of 1/M while false negatives never occur.
+
+Unlike BIP158, items are hashed with the double SHA3-256 hash of the key followed
+by the item instead of SipHash-2-4, and filters are gob encoded. The filters are
+therefore not compatible with BIP158 filters and are only matched by this module.
+
+References:
+https://github.com/bitcoin/bips/blob/master/bip-0158.mediawiki
+*/
+package filter
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+const (
+	// Represents the Golomb-Rice coding parameter of the filter
+	FilterP uint8 = 19
+	// Represents the inverse of the false positive rate of the filter
+	FilterM uint64 = 784931
+)
+
+// A structure that represents a compact block filter
+type Filter struct {
+	// Represents the number of items in the filter
+	N uint64
+
+	// Represents the Golomb-Rice coded data of the filter
+	Data []byte
+}
+
+// A constructor function that generates and returns a Filter for a given
+// key and set of items. The key is the hash of the block the filter is for.
+func NewFilter(key utils.Hash, items [][]byte) *Filter {
+	// Remove the duplicate items
+	unique := make(map[string]struct{})
+	for _, item := range items {
+		unique[string(item)] = struct{}{}
+	}
+
+	// Create the filter for the number of unique items
+	filter := &Filter{N: uint64(len(unique))}
+	// Determine the range of the hashed values
+	modulus := filter.N * FilterM
+
+	// Hash the items into the range of the filter
+	values := make([]uint64, 0, len(unique))
+	for item := range unique {
+		values = append(values, hashitem(key, []byte(item), modulus))
+	}
+
+	// Sort the hashed values
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	// Write the deltas of the sorted values with Golomb-Rice coding
+	writer := &bitwriter{}
+	var last uint64
+	for _, value := range values {
+		golombencode(writer, value-last)
+		last = value
+	}
+
+	// Assign the encoded data to the filter
+	filter.Data = writer.stream
+	// Return the filter
+	return filter
+}
+
+// A method of Filter that reports whether an item is likely in the filter for a given key.
+// A false result means the item is definitely not in the filter.
+func (filter *Filter) Match(key utils.Hash, item []byte) bool {
+	return filter.MatchAny(key, [][]byte{item})
+}
+
+// A method of Filter that reports whether any of the given items are likely in the filter
+// for a given key. A false result means that none of the items are in the filter.
+func (filter *Filter) MatchAny(key utils.Hash, items [][]byte) bool {
+	// An empty filter or query matches nothing
+	if filter.N == 0 || len(items) == 0 {
+		return false
+	}
+
+	// Hash the query items into the range of the filter
+	modulus := filter.N * FilterM
+	queries := make([]uint64, len(items))
+	for i, item := range items {
+		queries[i] = hashitem(key, item, modulus)
+	}
+
+	// Sort the hashed query values
+	sort.Slice(queries, func(i, j int) bool { return queries[i] < queries[j] })
+
+	// Walk the filter values and the query values together
+	reader := &bitreader{stream: filter.Data}
+	var value uint64
+	for i := uint64(0); i < filter.N; i++ {
+		// Decode the next value of the filter
+		delta, err := golombdecode(reader)
+		if err != nil {
+			return false
+		}
+		value += delta
+
+		// Skip the query values that are lower than the filter value
+		for len(queries) > 0 && queries[0] < value {
+			queries = queries[1:]
+		}
+
+		// Check if all query values have been passed
+		if len(queries) == 0 {
+			return false
+		}
+
+		// Check if the query value matches the filter value
+		if queries[0] == value {
+			return true
+		}
+	}
+
+	// Return false because no value matched
+	return false
+}
+
+// A method that returns the gob encoded data of the Filter
+func (filter *Filter) Serialize() utils.Gob {
+	// Encode the filter as a gob and return it
+	return utils.GobEncode(filter)
+}
+
+// A method that decodes a gob of bytes into the Filter struct
+func (filter *Filter) Deserialize(gobdata utils.Gob) {
+	// Decode the gob data into the filter
+	utils.GobDecode(gobdata, filter)
+}
+
+// A function that hashes an item with a key and maps it uniformly
+// onto the range [0, modulus) with a multiply and shift reduction.
+// The keyed hash is the Hash256 of the key followed by the item (not SipHash).
+func hashitem(key utils.Hash, item []byte, modulus uint64) uint64 {
+	// Use the first 16 bytes of the key
+	if len(key) > 16 {
+		key = key[:16]
+	}
+
+	// Generate the keyed hash of the item
+	hash := utils.Hash256(append(append([]byte{}, key...), item...))
+
+	// Collect the first 8 bytes of the hash as an integer
+	var value uint64
+	for _, b := range hash[:8] {
+		value = value<<8 | uint64(b)
+	}
+
+	// Reduce the value onto the range
+	high, _ := bits.Mul64(value, modulus)
+	return high
+}
+
+// A function that writes a value to a bit stream with Golomb-Rice coding.
+// The quotient is written in unary followed by the remainder in P bits.
+func golombencode(writer *bitwriter, value uint64) {
+	// Write the quotient in unary
+	for quotient := value >> FilterP; quotient > 0; quotient-- {
+		writer.writebit(true)
+	}
+	writer.writebit(false)
+
+	// Write the remainder
+	writer.writebits(value, FilterP)
+}
+
+// A function that reads a Golomb-Rice coded value from a bit stream
+func golombdecode(reader *bitreader) (uint64, error) {
+	// Read the quotient in unary
+	var quotient uint64
+	for {
+		bit, err := reader.readbit()
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode filter value: %w", err)
+		}
+
+		if !bit {
+			break
+		}
+		quotient++
+	}
+
+	// Read the remainder
+	remainder, err := reader.readbits(FilterP)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode filter value: %w", err)
+	}
+
+	// Return the value
+	return quotient<<FilterP | remainder, nil
+}
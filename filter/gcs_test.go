@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+func Test_FilterMatch(t *testing.T) {
+	key := utils.Hash256([]byte("block"))
+
+	var items [][]byte
+	for i := 0; i < 200; i++ {
+		items = append(items, []byte(fmt.Sprintf("item-%d", i)))
+	}
+
+	filter := NewFilter(key, items)
+	if filter.N != uint64(len(items)) {
+		t.Fatalf("filter item count failed! expected: %v, got: %v", len(items), filter.N)
+	}
+
+	// Every item in the filter must match
+	for _, item := range items {
+		if !filter.Match(key, item) {
+			t.Fatalf("filter match failed! item: %s", item)
+		}
+	}
+
+	// Items outside the filter should almost never match
+	falsepositives := 0
+	for i := 0; i < 1000; i++ {
+		if filter.Match(key, []byte(fmt.Sprintf("other-%d", i))) {
+			falsepositives++
+		}
+	}
+	if falsepositives > 1 {
+		t.Fatalf("filter false positive rate too high! got: %v/1000", falsepositives)
+	}
+
+}
+
+func Test_FilterMatchAny(t *testing.T) {
+	key := utils.Hash256([]byte("block"))
+	filter := NewFilter(key, [][]byte{[]byte("alpha"), []byte("beta"), []byte("gamma")})
+
+	tests := []struct {
+		input  [][]byte
+		output bool
+	}{
+		{[][]byte{[]byte("beta")}, true},
+		{[][]byte{[]byte("delta"), []byte("gamma")}, true},
+		{[][]byte{[]byte("delta"), []byte("epsilon")}, false},
+		{[][]byte{}, false},
+	}
+
+	for _, tt := range tests {
+		if match := filter.MatchAny(key, tt.input); match != tt.output {
+			t.Fatalf("MatchAny(%s) failed! expected: %v, got: %v", tt.input, tt.output, match)
+		}
+	}
+
+	// An empty filter matches nothing
+	if NewFilter(key, nil).Match(key, []byte("alpha")) {
+		t.Fatalf("empty filter matched an item!")
+	}
+}
@@ -0,0 +1,386 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// An interface for the node storage of a Merkle Mountain Range.
+// Nodes are addressed by their position in the MMR (post-order, zero-indexed).
+type MMRStore interface {
+	// A method that returns the hash of the node at a given position
+	GetNode(uint64) (utils.Hash, error)
+	// A method that sets the hash of the node at a given position
+	SetNode(uint64, utils.Hash) error
+}
+
+// A structure that represents an in-memory node store for a Merkle Mountain Range
+type MemoryMMRStore struct {
+	// Represents the mapping of node positions to node hashes
+	nodes map[uint64]utils.Hash
+	// Represents the synchronization lock for the store
+	mutex sync.RWMutex
+}
+
+// A constructor function that generates and returns an empty MemoryMMRStore
+func NewMemoryMMRStore() *MemoryMMRStore {
+	return &MemoryMMRStore{nodes: make(map[uint64]utils.Hash)}
+}
+
+// A method of MemoryMMRStore that returns the hash of the node at a given position
+func (store *MemoryMMRStore) GetNode(pos uint64) (utils.Hash, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	// Retrieve the node from the map
+	node, ok := store.nodes[pos]
+	if !ok {
+		// Return an error if the node does not exist
+		return nil, fmt.Errorf("mmr node %v does not exist", pos)
+	}
+
+	// Return the node
+	return node, nil
+}
+
+// A method of MemoryMMRStore that sets the hash of the node at a given position
+func (store *MemoryMMRStore) SetNode(pos uint64, hash utils.Hash) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// Set the node into the map
+	store.nodes[pos] = hash
+	return nil
+}
+
+// A structure that represents a Merkle Mountain Range (MMR).
+// An MMR is an append-only accumulator made of a list of perfect binary
+// merkle trees (mountains). Its root commits to every leaf that has been
+// appended and allows compact inclusion proofs against any previous size.
+type MMR struct {
+	// Represents the number of nodes in the MMR
+	Size uint64
+
+	// Represents the number of leaves in the MMR
+	Leaves uint64
+
+	// Represents the node storage of the MMR
+	store MMRStore
+}
+
+// A constructor function that generates and returns an MMR for
+// a given node store and the number of leaves already in the store.
+func NewMMR(store MMRStore, leaves uint64) *MMR {
+	return &MMR{Size: MMRSize(leaves), Leaves: leaves, store: store}
+}
+
+// A method of MMR that appends a leaf hash to the MMR and merges the
+// mountains of equal height that result from it. Returns the leaf index.
+func (mmr *MMR) Append(leaf utils.Hash) (uint64, error) {
+	// Set the leaf as the next node
+	pos := mmr.Size
+	if err := mmr.store.SetNode(pos, leaf); err != nil {
+		return 0, err
+	}
+
+	// Merge the mountains while the next position is a parent node
+	node, height := leaf, uint32(0)
+	for mmrHeight(pos+1) > height {
+		// Move to the parent position
+		pos++
+
+		// Retrieve the left sibling of the current node
+		left, err := mmr.store.GetNode(pos - parentOffset(height))
+		if err != nil {
+			return 0, err
+		}
+
+		// Generate the parent node and set it into the store
+		node = mmrMerge(left, node)
+		if err := mmr.store.SetNode(pos, node); err != nil {
+			return 0, err
+		}
+
+		height++
+	}
+
+	// Update the size and leaf count of the MMR
+	mmr.Size = pos + 1
+	mmr.Leaves++
+
+	// Return the index of the appended leaf
+	return mmr.Leaves - 1, nil
+}
+
+// A method of MMR that returns the root of the MMR in its current state
+func (mmr *MMR) Root() (utils.Hash, error) {
+	return mmr.RootAt(mmr.Leaves)
+}
+
+// A method of MMR that returns the root of the MMR as it was when
+// it had the given number of leaves. The root of an empty MMR is nil.
+func (mmr *MMR) RootAt(leaves uint64) (utils.Hash, error) {
+	// Check that the MMR has reached the given number of leaves
+	if leaves > mmr.Leaves {
+		return nil, fmt.Errorf("mmr has only %v leaves", mmr.Leaves)
+	}
+
+	// Collect the hashes of the peaks
+	peaks, err := mmr.collectPeaks(mmrPeaks(MMRSize(leaves)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Bag the peaks into the root
+	return bagPeaks(peaks), nil
+}
+
+// A method of MMR that generates an inclusion proof for the leaf at the
+// given index against the root of the MMR with the given number of leaves.
+func (mmr *MMR) GenerateProof(index, leaves uint64) (*MMRProof, error) {
+	// Check that the proof bounds are valid
+	if leaves > mmr.Leaves {
+		return nil, fmt.Errorf("mmr has only %v leaves", mmr.Leaves)
+	}
+	if index >= leaves {
+		return nil, fmt.Errorf("leaf %v is not in an mmr of %v leaves", index, leaves)
+	}
+
+	// Create the proof and determine the peaks of the MMR
+	proof := &MMRProof{LeafIndex: index, Leaves: leaves}
+	peaks := mmrPeaks(MMRSize(leaves))
+
+	// Climb the mountain of the leaf until a peak is reached
+	pos, height := LeafPosition(index), uint32(0)
+	for !containsPosition(peaks, pos) {
+		// Determine the sibling and the parent of the node
+		var sibling uint64
+		if mmrHeight(pos+1) > height {
+			// Node is a right child
+			sibling = pos - siblingOffset(height)
+			pos++
+		} else {
+			// Node is a left child
+			sibling = pos + siblingOffset(height)
+			pos += parentOffset(height)
+		}
+
+		// Retrieve the sibling and add it to the proof path
+		node, err := mmr.store.GetNode(sibling)
+		if err != nil {
+			return nil, err
+		}
+		proof.Siblings = append(proof.Siblings, node)
+
+		height++
+	}
+
+	// Collect all the other peaks of the MMR
+	for _, peak := range peaks {
+		if peak == pos {
+			continue
+		}
+
+		node, err := mmr.store.GetNode(peak)
+		if err != nil {
+			return nil, err
+		}
+		proof.Peaks = append(proof.Peaks, node)
+	}
+
+	// Return the proof
+	return proof, nil
+}
+
+// A method of MMR that collects the hashes of the nodes at the given peak positions
+func (mmr *MMR) collectPeaks(positions []uint64) ([]utils.Hash, error) {
+	peaks := make([]utils.Hash, 0, len(positions))
+	for _, pos := range positions {
+		node, err := mmr.store.GetNode(pos)
+		if err != nil {
+			return nil, err
+		}
+		peaks = append(peaks, node)
+	}
+
+	return peaks, nil
+}
+
+// A structure that represents an inclusion proof of a leaf in a Merkle Mountain Range
+type MMRProof struct {
+	// Represents the index of the leaf being proven
+	LeafIndex uint64
+
+	// Represents the number of leaves in the MMR that the proof is against
+	Leaves uint64
+
+	// Represents the sibling hashes from the leaf up to its peak
+	Siblings []utils.Hash
+
+	// Represents the hashes of all other peaks of the MMR (left to right)
+	Peaks []utils.Hash
+}
+
+// A method of MMRProof that verifies the proof for a given leaf hash and MMR root
+func (proof *MMRProof) Verify(leaf, root utils.Hash) bool {
+	// Check that the leaf lies within the MMR
+	if proof.LeafIndex >= proof.Leaves {
+		return false
+	}
+
+	// Determine the peaks of the MMR
+	peakpositions := mmrPeaks(MMRSize(proof.Leaves))
+	// Check that the number of peaks matches
+	if len(proof.Peaks) != len(peakpositions)-1 {
+		return false
+	}
+
+	// Climb the mountain of the leaf with the sibling path
+	node, pos, height := leaf, LeafPosition(proof.LeafIndex), uint32(0)
+	for _, sibling := range proof.Siblings {
+		// Check that the path has not climbed past a peak
+		if containsPosition(peakpositions, pos) {
+			return false
+		}
+
+		if mmrHeight(pos+1) > height {
+			// Node is a right child
+			node = mmrMerge(sibling, node)
+			pos++
+		} else {
+			// Node is a left child
+			node = mmrMerge(node, sibling)
+			pos += parentOffset(height)
+		}
+
+		height++
+	}
+
+	// Check that the path has ended on a peak
+	if !containsPosition(peakpositions, pos) {
+		return false
+	}
+
+	// Insert the computed peak among the other peaks
+	peaks := make([]utils.Hash, 0, len(peakpositions))
+	others := proof.Peaks
+	for _, peak := range peakpositions {
+		if peak == pos {
+			peaks = append(peaks, node)
+		} else {
+			peaks = append(peaks, others[0])
+			others = others[1:]
+		}
+	}
+
+	// Bag the peaks and compare with the root
+	return bytes.Equal(bagPeaks(peaks), root)
+}
+
+// A function that returns the number of MMR nodes for a given number of leaves
+func MMRSize(leaves uint64) uint64 {
+	return 2*leaves - uint64(bits.OnesCount64(leaves))
+}
+
+// A function that returns the node position of the leaf at a given index
+func LeafPosition(index uint64) uint64 {
+	return MMRSize(index)
+}
+
+// A function that merges two MMR nodes into their parent
+func mmrMerge(left, right utils.Hash) utils.Hash {
+	// Concatenate the left and right hashes
+	data := append(append([]byte{}, left...), right...)
+	// Hash256 the accumulated data
+	return utils.Hash256(data)
+}
+
+// A function that bags the peaks of an MMR into a single root, from right to left
+func bagPeaks(peaks []utils.Hash) utils.Hash {
+	// An empty MMR has no root
+	if len(peaks) == 0 {
+		return nil
+	}
+
+	root := peaks[len(peaks)-1]
+	for i := len(peaks) - 2; i >= 0; i-- {
+		root = mmrMerge(peaks[i], root)
+	}
+
+	return root
+}
+
+// A function that returns the height of the node at a given position
+func mmrHeight(pos uint64) uint32 {
+	// Work with the one-indexed position
+	pos++
+	// Jump left until the position is the peak of a perfect tree
+	for !allOnes(pos) {
+		pos -= (uint64(1) << (bits.Len64(pos) - 1)) - 1
+	}
+
+	return uint32(bits.Len64(pos) - 1)
+}
+
+// A function that returns the positions of the peaks for an MMR of a given size
+func mmrPeaks(size uint64) []uint64 {
+	if size == 0 {
+		return nil
+	}
+
+	// Find the height and position of the leftmost peak
+	height, pos := uint32(0), uint64(0)
+	for next := uint32(1); (uint64(1)<<(next+1))-2 < size; next++ {
+		height, pos = next, (uint64(1)<<(next+1))-2
+	}
+
+	// Collect the peaks to the right of the leftmost peak
+	peaks := []uint64{pos}
+	for height > 0 {
+		// Move to the right sibling of the peak
+		pos += siblingOffset(height)
+		// Descend into the left children until the position exists in the MMR
+		for pos > size-1 {
+			if height == 0 {
+				return peaks
+			}
+
+			pos -= parentOffset(height - 1)
+			height--
+		}
+
+		peaks = append(peaks, pos)
+	}
+
+	return peaks
+}
+
+// A function that returns the offset from a left child to its parent for a given height
+func parentOffset(height uint32) uint64 {
+	return uint64(2) << height
+}
+
+// A function that returns the offset between two siblings for a given height
+func siblingOffset(height uint32) uint64 {
+	return (uint64(2) << height) - 1
+}
+
+// A function that reports whether all the bits of a number are ones
+func allOnes(num uint64) bool {
+	return num != 0 && num&(num+1) == 0
+}
+
+// A function that reports whether a slice of positions contains a given position
+func containsPosition(positions []uint64, pos uint64) bool {
+	for _, p := range positions {
+		if p == pos {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+func Test_MMRSize(t *testing.T) {
+	tests := []struct {
+		input  uint64
+		output uint64
+	}{
+		{0, 0}, {1, 1}, {2, 3}, {3, 4}, {4, 7}, {5, 8}, {7, 11}, {8, 15}, {11, 19},
+	}
+
+	for _, tt := range tests {
+		if size := MMRSize(tt.input); size != tt.output {
+			t.Fatalf("MMRSize(%v) failed! expected: %v, got: %v", tt.input, tt.output, size)
+		}
+	}
+}
+
+func Test_MMRPeaks(t *testing.T) {
+	tests := []struct {
+		input  uint64
+		output []uint64
+	}{
+		{1, []uint64{0}},
+		{3, []uint64{2}},
+		{4, []uint64{2, 3}},
+		{11, []uint64{6, 9, 10}},
+		{19, []uint64{14, 17, 18}},
+	}
+
+	for _, tt := range tests {
+		peaks := mmrPeaks(tt.input)
+		if fmt.Sprint(peaks) != fmt.Sprint(tt.output) {
+			t.Fatalf("mmrPeaks(%v) failed! expected: %v, got: %v", tt.input, tt.output, peaks)
+		}
+	}
+}
+
+func Test_MMRRoot(t *testing.T) {
+	mmr := NewMMR(NewMemoryMMRStore(), 0)
+
+	// An empty MMR has a nil root
+	if root, err := mmr.Root(); err != nil || root != nil {
+		t.Fatalf("empty mmr root failed! expected: nil, got: %x (%v)", root, err)
+	}
+
+	leaves := make([]utils.Hash, 3)
+	for i := range leaves {
+		leaves[i] = utils.Hash256([]byte{byte(i)})
+		mmr.Append(leaves[i])
+	}
+
+	// The root of 3 leaves bags the peak of the first two with the third
+	expected := mmrMerge(mmrMerge(leaves[0], leaves[1]), leaves[2])
+	if root, _ := mmr.Root(); !bytes.Equal(root, expected) {
+		t.Fatalf("mmr root failed! expected: %x, got: %x", expected, root)
+	}
+}
+
+func Test_MMRProof(t *testing.T) {
+	mmr := NewMMR(NewMemoryMMRStore(), 0)
+
+	var leaves []utils.Hash
+	var roots []utils.Hash
+	for i := 0; i < 33; i++ {
+		leaf := utils.Hash256([]byte(fmt.Sprintf("leaf-%d", i)))
+		leaves = append(leaves, leaf)
+
+		if _, err := mmr.Append(leaf); err != nil {
+			t.Fatalf("mmr append failed! error: %v", err)
+		}
+
+		root, _ := mmr.Root()
+		roots = append(roots, root)
+	}
+
+	// Prove every leaf against every historical root that includes it
+	for size := uint64(1); size <= mmr.Leaves; size++ {
+		for index := uint64(0); index < size; index++ {
+			proof, err := mmr.GenerateProof(index, size)
+			if err != nil {
+				t.Fatalf("mmr proof generation failed! leaf: %v, size: %v, error: %v", index, size, err)
+			}
+
+			if !proof.Verify(leaves[index], roots[size-1]) {
+				t.Fatalf("mmr proof verification failed! leaf: %v, size: %v", index, size)
+			}
+
+			if proof.Verify(leaves[(index+1)%size], roots[size-1]) && size > 1 {
+				t.Fatalf("mmr proof verified for the wrong leaf! leaf: %v, size: %v", index, size)
+			}
+		}
+	}
+}
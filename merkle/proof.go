@@ -0,0 +1,87 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A structure that represents an inclusion proof of an item in a Merkle Tree
+type MerkleProof struct {
+	// Represents the index of the item in the Merkle Tree
+	Index int
+
+	// Represents the serialized data of the item that is paired with the proven item
+	Pair utils.Gob
+
+	// Represents the sibling node hashes from the base level up to the root
+	Path []utils.Hash
+}
+
+// A method of MerkleTree that generates an inclusion proof for the item at a given index.
+// The tree must have been built before a proof can be generated from it.
+func (mt *MerkleTree) GenerateProof(index int) (*MerkleProof, error) {
+	// Wait for the merkle builder to finish building
+	mt.BuildGroup.Wait()
+
+	// Check that the index is within the tree
+	if index < 0 || index >= mt.Count {
+		return nil, fmt.Errorf("item %v is not in a tree of %v items", index, mt.Count)
+	}
+
+	// Determine the item that the proven item is paired with
+	pairindex := index ^ 1
+	if pairindex >= mt.Count {
+		// The last item of an odd count is paired with itself
+		pairindex = index
+	}
+
+	// Create the proof with the serialized pair item
+	proof := &MerkleProof{Index: index, Pair: mt.Items[pairindex].Serialize()}
+
+	// Iterate over the levels of the tree below the root
+	position := index / 2
+	for _, level := range mt.levels[:len(mt.levels)-1] {
+		// Determine the sibling of the node on the level
+		sibling := position ^ 1
+		if sibling >= len(level) {
+			// The last node of an odd level is paired with itself
+			sibling = position
+		}
+
+		// Add the sibling node hash to the path
+		proof.Path = append(proof.Path, level[sibling])
+		// Move to the position of the parent node
+		position /= 2
+	}
+
+	// Return the proof
+	return proof, nil
+}
+
+// A method of MerkleProof that verifies the proof for a given item and merkle root
+func (proof *MerkleProof) Verify(item utils.GobEncodable, root utils.Hash) bool {
+	// Generate the base node for the item and its pair
+	var node *MerkleNode
+	if proof.Index%2 == 0 {
+		node = NewMerkleNode(item.Serialize(), proof.Pair, true)
+	} else {
+		node = NewMerkleNode(proof.Pair, item.Serialize(), true)
+	}
+
+	// Climb the tree with the sibling path
+	hash, position := node.Data, proof.Index/2
+	for _, sibling := range proof.Path {
+		if position%2 == 0 {
+			hash = NewMerkleNode(hash, sibling, false).Data
+		} else {
+			hash = NewMerkleNode(sibling, hash, false).Data
+		}
+
+		position /= 2
+	}
+
+	// Compare the computed root with the merkle root
+	return bytes.Equal(hash, root)
+}
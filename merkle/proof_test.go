@@ -0,0 +1,44 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A test item that implements the utils.GobEncodable interface
+type testitem struct {
+	Data string
+}
+
+func (item *testitem) Serialize() utils.Gob          { return utils.GobEncode(item) }
+func (item *testitem) Deserialize(gobdata utils.Gob) { utils.GobDecode(gobdata, item) }
+
+func Test_MerkleProof(t *testing.T) {
+	for count := 1; count <= 17; count++ {
+		items := make([]utils.GobEncodable, count)
+		for i := range items {
+			items[i] = &testitem{Data: fmt.Sprintf("item-%d", i)}
+		}
+
+		merkletree := NewMerkleTree()
+		merkletree.BuildFull(items)
+		merkletree.BuildGroup.Wait()
+
+		for index := 0; index < count; index++ {
+			proof, err := merkletree.GenerateProof(index)
+			if err != nil {
+				t.Fatalf("merkle proof generation failed! items: %v, index: %v, error: %v", count, index, err)
+			}
+
+			if !proof.Verify(items[index], merkletree.MerkleRoot) {
+				t.Fatalf("merkle proof verification failed! items: %v, index: %v", count, index)
+			}
+
+			if proof.Verify(&testitem{Data: "forged"}, merkletree.MerkleRoot) {
+				t.Fatalf("merkle proof verified a forged item! items: %v, index: %v", count, index)
+			}
+		}
+	}
+}
@@ -25,6 +25,9 @@ type MerkleTree struct {
 
 	// Represents the wait group for the tree builder tasks
 	BuildGroup *sync.WaitGroup
+
+	// Represents the node hashes of each level of the tree (base level first)
+	levels [][]utils.Hash
 }
 
 // A constructor function that generates and returns a null MerkleTree
@@ -95,10 +98,17 @@ func (mt *MerkleTree) Build() {
 	// Assign the item count
 	mt.Count = len(mt.Items)
 
-	// Collect the intial size of the base nodes collection
-	count := len(nodes)
-	// Run loop for half the number of leaf nodes
-	for i := 0; i < count/2; i++ {
+	// Record the base level of the tree
+	mt.recordLevel(nodes)
+
+	// Iterate until the level has been reduced to a single root node
+	for len(nodes) > 1 {
+		// Check if the level has an odd number of nodes
+		if len(nodes)%2 != 0 {
+			// Copy the last node. This ensures an even number of nodes on the level.
+			nodes = append(nodes, nodes[len(nodes)-1])
+		}
+
 		// Re/Set the level slice of Merkle Node
 		var level []MerkleNode
 
@@ -112,6 +122,8 @@ func (mt *MerkleTree) Build() {
 
 		// Set the full node list to the level nodes
 		nodes = level
+		// Record the level of the tree
+		mt.recordLevel(nodes)
 	}
 
 	// Check if the final node list has just one node
@@ -125,3 +137,13 @@ func (mt *MerkleTree) Build() {
 	/// Decrement the BuildGroup counter (completes build)
 	mt.BuildGroup.Done()
 }
+
+// A method of MerkleTree that records the node hashes of a level of the tree
+func (mt *MerkleTree) recordLevel(nodes []MerkleNode) {
+	level := make([]utils.Hash, len(nodes))
+	for i, node := range nodes {
+		level[i] = node.Data
+	}
+
+	mt.levels = append(mt.levels, level)
+}
@@ -0,0 +1,178 @@
+package miner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/manishmeganathan/weave/consensus"
+	"github.com/manishmeganathan/weave/core"
+	"github.com/sirupsen/logrus"
+)
+
+// A structure that represents an external miner that fetches work units from a
+// work server, searches their nonces and submits the solved nonces to the server
+type WorkClient struct {
+	// Represents the base URL of the work server
+	URL string
+
+	// Represents the number of nonces searched before checking for cancellation
+	BatchSize int
+
+	// Represents the HTTP client used for requests to the work server
+	client *http.Client
+}
+
+// A constructor function that generates and returns a WorkClient for a work server
+// address. The address may be given with or without the 'http://' scheme.
+func NewWorkClient(address string) *WorkClient {
+	// Add the scheme to the address if it is missing
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	return &WorkClient{
+		URL:       strings.TrimSuffix(address, "/"),
+		BatchSize: DefaultBatchSize,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// A method of WorkClient that fetches a work unit from the work server
+func (client *WorkClient) GetWork(ctx context.Context) (*WorkUnit, error) {
+	// Create the request for a work unit
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, client.URL+"/work", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the work unit from the response
+	unit := &WorkUnit{}
+	if err := client.do(request, unit); err != nil {
+		return nil, err
+	}
+
+	return unit, nil
+}
+
+// A method of WorkClient that submits a solved nonce for a work unit to the work server
+func (client *WorkClient) SubmitWork(ctx context.Context, id string, nonce int) (*WorkResult, error) {
+	// Encode the submission
+	body, err := json.Marshal(WorkSubmission{ID: id, Nonce: nonce})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the request for the submission
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, client.URL+"/submit", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	// Decode the result from the response
+	result := &WorkResult{}
+	if err := client.do(request, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// A method of WorkClient that sends a request to the work server and decodes the JSON
+// response into a value. Returns the error message of the server for failed requests.
+func (client *WorkClient) do(request *http.Request, value any) error {
+	// Send the request
+	response, err := client.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// Check the status of the response
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("work server returned %v: %v", response.Status, strings.TrimSpace(string(message)))
+	}
+
+	// Decode the response
+	return json.NewDecoder(response.Body).Decode(value)
+}
+
+// A method of WorkClient that searches the nonces of a work unit in batches. Returns the
+// solved nonce and true if a nonce in the range satisfies the target of the unit, or false
+// if the range is exhausted or the context is cancelled.
+func (client *WorkClient) Solve(ctx context.Context, unit *WorkUnit) (int, bool) {
+	// Decode the header of the work unit
+	header := &core.BlockHeader{ConsensusHeader: consensus.NewPOW()}
+	header.Deserialize(unit.Header)
+
+	// Set the target of the work unit on the proof of work
+	pow, ok := header.ConsensusHeader.(*consensus.POW)
+	if !ok {
+		return 0, false
+	}
+	pow.Target = new(big.Int).SetBytes(unit.Target)
+
+	// Search the range of the work unit in batches
+	for start := unit.NonceStart; start-unit.NonceStart < unit.NonceCount; start += client.BatchSize {
+		// Check if the search has been cancelled
+		if ctx.Err() != nil {
+			return 0, false
+		}
+
+		// Search the batch without exceeding the range
+		count := min(client.BatchSize, unit.NonceCount-(start-unit.NonceStart))
+		if _, ok := pow.MintRange(header, start, count); ok {
+			return pow.Nonce, true
+		}
+	}
+
+	return 0, false
+}
+
+// A method of WorkClient that mines until the context is cancelled. Work units are
+// fetched from the work server, solved and submitted in a loop. Failed requests are
+// logged and retried after a delay. Returns the error of the context.
+func (client *WorkClient) Mine(ctx context.Context) error {
+	for ctx.Err() == nil {
+		// Fetch a work unit
+		unit, err := client.GetWork(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logrus.WithFields(logrus.Fields{"error": err}).Warnln("failed to get work.")
+			}
+
+			// Wait before retrying
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+
+			continue
+		}
+
+		// Search the nonces of the work unit
+		nonce, ok := client.Solve(ctx, unit)
+		if !ok {
+			continue
+		}
+
+		// Submit the solved nonce
+		result, err := client.SubmitWork(ctx, unit.ID, nonce)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"error": err, "work": unit.ID}).Warnln("solved work was rejected.")
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{"height": result.Height, "hash": fmt.Sprintf("%x", result.Hash)}).Infoln("solved block.")
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,241 @@
+package miner
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/wallet"
+	"github.com/sirupsen/logrus"
+)
+
+// A set of constants that represent the defaults of a Miner
+const (
+	// Represents the default number of nonces searched before checking for new work
+	DefaultBatchSize = 1 << 14
+	// Represents the default time after which a block template is rebuilt with new transactions
+	DefaultRefreshInterval = 30 * time.Second
+)
+
+// A structure that represents a miner that mines blocks for the head of a chain
+// with the transactions of a pool. The miner restarts its work whenever the chain
+// head changes. The miner is a service that can be registered with a node.
+type Miner struct {
+	// Represents the function that is called with every mined block (for broadcasts).
+	// Set to the BroadcastBlock method of a network host to publish the mined blocks,
+	// blocks are only connected to the local chain if it is nil.
+	Broadcast func(block *core.Block)
+
+	// Represents the number of nonces searched before checking for new work
+	BatchSize int
+
+	// Represents the time after which a block template is rebuilt with new transactions
+	RefreshInterval time.Duration
+
+	// Represents the chain that is mined
+	chain *core.BlockChain
+	// Represents the pool that the transactions of the blocks are selected from
+	txpool *core.TxPool
+	// Represents the address that receives the rewards of the mined blocks
+	coinbase wallet.Address
+	// Represents the limits of the block templates
+	options core.TemplateOptions
+
+	// Represents the number of nonces in the range of each work unit for external miners
+	WorkRange int
+	// Represents the maximum number of work units held for submission by external miners
+	MaxWorkUnits int
+
+	// Represents the work units handed out to external miners by their ID
+	work map[string]*workentry
+	// Represents the block template of the work units that are handed out
+	worktemplate *core.BlockTemplate
+	// Represents the time that the work template was built
+	workbuilt time.Time
+	// Represents the first nonce of the next work unit of the work template
+	worknonce int
+	// Represents the sequence number of the next work unit
+	worksequence uint64
+
+	// Represents the synchronization lock for the chain
+	mutex sync.Mutex
+	// Represents the cancel function of the mining loop
+	cancel context.CancelFunc
+	// Represents the channel that is closed when the mining loop exits
+	done chan struct{}
+}
+
+// A constructor function that generates and returns a Miner for a chain and pool
+// that pays the rewards of its blocks to a coinbase address. The pool can be nil.
+func NewMiner(chain *core.BlockChain, txpool *core.TxPool, coinbase wallet.Address, options core.TemplateOptions) *Miner {
+	return &Miner{
+		BatchSize:       DefaultBatchSize,
+		RefreshInterval: DefaultRefreshInterval,
+		WorkRange:       DefaultWorkRange,
+		MaxWorkUnits:    DefaultMaxWorkUnits,
+		work:            make(map[string]*workentry),
+		chain:           chain,
+		txpool:          txpool,
+		coinbase:        coinbase,
+		options:         options,
+	}
+}
+
+// A method of Miner that returns the name of the service
+func (miner *Miner) Name() string {
+	return "miner"
+}
+
+// A method of Miner that starts the mining loop in the background
+func (miner *Miner) Start(ctx context.Context) error {
+	// Create the context of the mining loop
+	ctx, miner.cancel = context.WithCancel(ctx)
+	miner.done = make(chan struct{})
+
+	// Start the mining loop
+	go miner.run(ctx)
+	return nil
+}
+
+// A method of Miner that stops the mining loop and waits for it to exit
+func (miner *Miner) Stop(ctx context.Context) error {
+	// Cancel the mining loop
+	miner.cancel()
+
+	// Wait for the mining loop to exit or for the deadline
+	select {
+	case <-miner.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// A method of Miner that returns the lock on the chain of the miner. The lock must be
+// held by every other user of the chain in the process (such as the protocol handlers).
+func (miner *Miner) Locker() sync.Locker {
+	return &miner.mutex
+}
+
+// A method of Miner that submits a block to the chain. Blocks from other sources
+// (such as peers) must be submitted through the miner so that the chain is accessed
+// safely and the miner moves to the new chain head. The pool is revalidated after
+// blocks are connected. Returns the blocks that were connected.
+func (miner *Miner) SubmitBlock(block *core.Block, peer string) ([]*core.Block, error) {
+	// Acquire the lock on the chain
+	miner.mutex.Lock()
+	defer miner.mutex.Unlock()
+
+	return miner.submit(block, peer)
+}
+
+// A method of Miner that submits a block to the chain and revalidates the pool.
+// Must be called while holding the lock on the chain.
+func (miner *Miner) submit(block *core.Block, peer string) ([]*core.Block, error) {
+	// Submit the block to the chain
+	connected, err := miner.chain.SubmitBlock(block, peer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the transactions that were confirmed or invalidated by the blocks
+	if miner.txpool != nil {
+		miner.txpool.Revalidate()
+	}
+
+	return connected, nil
+}
+
+// A method of Miner that handles a mined block that was rejected by the chain. The pool is
+// revalidated to drop the transactions that made the block invalid and the work template is
+// discarded, so that the next templates are built without them. Must be called while holding
+// the lock on the chain.
+func (miner *Miner) rejected() {
+	if miner.txpool != nil {
+		miner.txpool.Revalidate()
+	}
+
+	miner.worktemplate = nil
+}
+
+// A method of Miner that builds a block template for the chain head
+func (miner *Miner) Template() (*core.BlockTemplate, error) {
+	// Acquire the lock on the chain
+	miner.mutex.Lock()
+	defer miner.mutex.Unlock()
+
+	return miner.chain.NewBlockTemplate(miner.txpool, miner.coinbase, miner.options)
+}
+
+// A method of Miner that reports whether a block template no longer extends the chain head
+func (miner *Miner) Stale(template *core.BlockTemplate) bool {
+	// Acquire the lock on the chain
+	miner.mutex.Lock()
+	defer miner.mutex.Unlock()
+
+	return !bytes.Equal(template.Block.Priori, miner.chain.ChainHead)
+}
+
+// A method of Miner that runs the mining loop until the context is cancelled.
+// A template is built for the chain head and its nonces are searched in batches.
+// The template is rebuilt when the chain head changes or the template is too old.
+func (miner *Miner) run(ctx context.Context) {
+	defer close(miner.done)
+
+	for ctx.Err() == nil {
+		// Build a template for the chain head
+		template, err := miner.Template()
+		if err != nil {
+			// Log the error and stop mining
+			logrus.WithFields(logrus.Fields{"error": err}).Errorln("failed to build block template.")
+			return
+		}
+
+		// Mine the template until it is solved or there is new work
+		block := miner.mine(ctx, template)
+		if block == nil {
+			continue
+		}
+
+		// Submit the mined block to the chain
+		if _, err := miner.SubmitBlock(block, ""); err != nil {
+			logrus.WithFields(logrus.Fields{"error": err}).Warnln("mined block was rejected.")
+
+			// Drop the transactions that made the block invalid
+			miner.mutex.Lock()
+			miner.rejected()
+			miner.mutex.Unlock()
+
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{"height": block.BlockHeight, "hash": block.BlockHash, "txns": block.TXCount}).Infoln("mined block.")
+
+		// Broadcast the mined block
+		if miner.Broadcast != nil {
+			miner.Broadcast(block)
+		}
+	}
+}
+
+// A method of Miner that searches the nonces of a template in batches. Returns the
+// solved block, or nil if the context was cancelled or the template is out of date.
+func (miner *Miner) mine(ctx context.Context, template *core.BlockTemplate) *core.Block {
+	built := time.Now()
+
+	for nonce := 0; nonce >= 0; nonce += miner.BatchSize {
+		// Check if the miner has been stopped or the template is out of date
+		if ctx.Err() != nil || miner.Stale(template) || time.Since(built) > miner.RefreshInterval {
+			return nil
+		}
+
+		// Search the next batch of nonces
+		if block, ok := template.Mine(nonce, miner.BatchSize); ok {
+			return block
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package miner
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+// Represents the chain params used for tests (low difficulty for fast mining)
+var testparams = core.ChainParams{Difficulty: 8, Reward: 25}
+
+// A function that returns a new in-memory chain for tests
+func testchain(t *testing.T) *core.BlockChain {
+	address := *wallet.NewWallet().GenerateAddress(byte(0x00))
+	chain, err := core.NewBlockChainWithOptions(core.ChainOptions{InMemory: true, Params: testparams, Coinbase: address})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	t.Cleanup(chain.CloseBuckets)
+	return chain
+}
+
+func Test_Miner(t *testing.T) {
+	chain := testchain(t)
+	txpool := core.NewTxPool(chain, core.DefaultTxPoolSize)
+	miner := NewMiner(chain, txpool, *wallet.NewWallet().GenerateAddress(byte(0x00)), core.DefaultTemplateOptions())
+
+	// Collect the broadcast blocks
+	var mutex sync.Mutex
+	broadcast := make([]*core.Block, 0)
+	miner.Broadcast = func(block *core.Block) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		broadcast = append(broadcast, block)
+	}
+
+	if err := miner.Start(context.Background()); err != nil {
+		t.Fatalf("Start() failed! %v", err)
+	}
+
+	// Wait for a few blocks to be mined
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		mutex.Lock()
+		count := len(broadcast)
+		mutex.Unlock()
+
+		if count >= 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Miner failed! expected: 3 blocks, got: %v", count)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := miner.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() failed! %v", err)
+	}
+
+	// Every broadcast block is on the chain
+	mutex.Lock()
+	defer mutex.Unlock()
+	if chain.ChainHeight != len(broadcast)+1 {
+		t.Fatalf("Miner failed! expected height: %v, got: %v", len(broadcast)+1, chain.ChainHeight)
+	}
+}
+
+func Test_MinerStale(t *testing.T) {
+	chain := testchain(t)
+	miner := NewMiner(chain, nil, *wallet.NewWallet().GenerateAddress(byte(0x00)), core.DefaultTemplateOptions())
+
+	// A template goes stale when another block is submitted
+	template, err := miner.Template()
+	if err != nil {
+		t.Fatalf("Template() failed! %v", err)
+	}
+
+	other, _ := miner.Template()
+	block, ok := other.Mine(0, 1<<20)
+	if !ok {
+		t.Fatalf("Mine() failed! no nonce found")
+	}
+
+	if _, err := miner.SubmitBlock(block, "peer"); err != nil {
+		t.Fatalf("SubmitBlock() failed! %v", err)
+	}
+	if !miner.Stale(template) {
+		t.Fatalf("Stale() failed! expected the template to be stale")
+	}
+
+	// Mining a stale template gives up without a block
+	if block := miner.mine(context.Background(), template); block != nil {
+		t.Fatalf("mine() failed! expected no block for a stale template")
+	}
+}
@@ -0,0 +1,190 @@
+package miner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/sirupsen/logrus"
+)
+
+// A set of constants that represent the defaults of the work units of a Miner
+const (
+	// Represents the default number of nonces in the range of a WorkUnit
+	DefaultWorkRange = 1 << 20
+	// Represents the default number of work units that are held for submission
+	DefaultMaxWorkUnits = 1024
+)
+
+// A set of errors returned when solved work is submitted
+var (
+	// Represents the error returned when a work unit is unknown or has been dropped
+	ErrUnknownWork = errors.New("unknown work unit")
+	// Represents the error returned when a work unit no longer extends the chain head
+	ErrStaleWork = errors.New("work unit is stale")
+)
+
+// A structure that represents a unit of mining work that is handed out to an external
+// miner. The header of a block template is encoded with a zero nonce and every unit of
+// a template is given a separate range of nonces, so that several miners can work on the
+// same template without repeating work. A miner decodes the header, searches the nonces
+// of its range for a header hash below the target and submits the nonce with the unit ID.
+//
+// Work units do not carry an extranonce. The nonce of a header is a 64-bit integer, so the
+// nonce ranges of a template do not run out before the template is rebuilt, and a template
+// is rebuilt with a new nonce space if they do.
+type WorkUnit struct {
+	// Represents the ID of the work unit
+	ID string `json:"id"`
+
+	// Represents the gob encoded header of the block template with a zero nonce
+	Header []byte `json:"header"`
+
+	// Represents the big endian target that the header hash must be below
+	Target []byte `json:"target"`
+
+	// Represents the height of the block template
+	Height int `json:"height"`
+
+	// Represents the first nonce of the range of the work unit
+	NonceStart int `json:"noncestart"`
+
+	// Represents the number of nonces in the range of the work unit
+	NonceCount int `json:"noncecount"`
+}
+
+// A structure that represents a work unit that has been handed out with its template
+type workentry struct {
+	// Represents the block template of the work unit
+	template *core.BlockTemplate
+	// Represents the first nonce of the range of the work unit
+	start int
+	// Represents the number of nonces in the range of the work unit
+	count int
+	// Represents the sequence number of the work unit
+	sequence uint64
+}
+
+// A method of Miner that hands out a unit of work for the chain head to an external miner.
+// Units share a block template until the chain head changes or the template is older than
+// the refresh interval, and each unit is given the next range of nonces of its template.
+// At most MaxWorkUnits units are held for submission and the oldest unit is dropped for a new one.
+func (miner *Miner) GetWork() (*WorkUnit, error) {
+	// Acquire the lock on the chain
+	miner.mutex.Lock()
+	defer miner.mutex.Unlock()
+
+	// Check if the work template must be rebuilt
+	if miner.worktemplate == nil || !bytes.Equal(miner.worktemplate.Block.Priori, miner.chain.ChainHead) ||
+		time.Since(miner.workbuilt) > miner.RefreshInterval || miner.worknonce+miner.WorkRange < 0 {
+
+		// Build a template for the chain head
+		template, err := miner.chain.NewBlockTemplate(miner.txpool, miner.coinbase, miner.options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build block template! error - %v", err)
+		}
+
+		miner.worktemplate = template
+		miner.workbuilt = time.Now()
+		miner.worknonce = 0
+
+		// Drop the work units that no longer extend the chain head
+		for id, entry := range miner.work {
+			if !bytes.Equal(entry.template.Block.Priori, miner.chain.ChainHead) {
+				delete(miner.work, id)
+			}
+		}
+	}
+
+	// Assign the next range of nonces of the template to the work unit
+	entry := &workentry{template: miner.worktemplate, start: miner.worknonce, count: miner.WorkRange, sequence: miner.worksequence}
+	miner.worknonce += miner.WorkRange
+
+	// Drop the oldest work units to make room for the work unit
+	for len(miner.work) > 0 && len(miner.work) >= miner.MaxWorkUnits {
+		miner.dropoldest()
+	}
+
+	// Record the work unit by its ID
+	id := strconv.FormatUint(miner.worksequence, 16)
+	miner.worksequence++
+	miner.work[id] = entry
+
+	// Create the work unit with the encoded header and target
+	block := entry.template.Block
+	return &WorkUnit{
+		ID:         id,
+		Header:     block.BlockHeader.Serialize(),
+		Target:     entry.template.Target().Bytes(),
+		Height:     block.BlockHeight,
+		NonceStart: entry.start,
+		NonceCount: entry.count,
+	}, nil
+}
+
+// A method of Miner that drops the oldest work unit that has been handed out.
+// Must be called while holding the lock of the miner.
+func (miner *Miner) dropoldest() {
+	// Find the work unit with the lowest sequence number
+	var oldest string
+	var sequence uint64
+	for id, entry := range miner.work {
+		if oldest == "" || entry.sequence < sequence {
+			oldest, sequence = id, entry.sequence
+		}
+	}
+
+	// Drop the work unit
+	delete(miner.work, oldest)
+}
+
+// A method of Miner that accepts a solved nonce for a work unit from an external miner.
+// The solved block is submitted to the chain and broadcast. Returns the solved block or
+// an error if the unit is unknown or stale or if the nonce does not solve the template.
+func (miner *Miner) SubmitWork(id string, nonce int) (*core.Block, error) {
+	// Acquire the lock on the chain
+	miner.mutex.Lock()
+	defer miner.mutex.Unlock()
+
+	// Retrieve the work unit
+	entry, ok := miner.work[id]
+	if !ok {
+		return nil, ErrUnknownWork
+	}
+
+	// Check that the work unit still extends the chain head
+	if !bytes.Equal(entry.template.Block.Priori, miner.chain.ChainHead) {
+		delete(miner.work, id)
+		return nil, ErrStaleWork
+	}
+
+	// Check that the nonce is in the range of the work unit
+	if nonce < entry.start || nonce-entry.start >= entry.count {
+		return nil, fmt.Errorf("nonce %v is outside the range of the work unit", nonce)
+	}
+
+	// Solve the template with the nonce
+	block, err := entry.template.Solve(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Submit the solved block to the chain
+	if _, err := miner.submit(block, ""); err != nil {
+		// Drop the transactions that made the block invalid
+		miner.rejected()
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{"height": block.BlockHeight, "hash": block.BlockHash, "txns": block.TXCount, "work": id}).Infoln("external miner solved block.")
+
+	// Broadcast the solved block
+	if miner.Broadcast != nil {
+		miner.Broadcast(block)
+	}
+
+	return block, nil
+}
@@ -0,0 +1,105 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+func Test_GetWork(t *testing.T) {
+	chain := testchain(t)
+	miner := NewMiner(chain, nil, *wallet.NewWallet().GenerateAddress(byte(0x00)), core.DefaultTemplateOptions())
+
+	// Work units of the same template are given separate ranges
+	first, err := miner.GetWork()
+	if err != nil {
+		t.Fatalf("GetWork() failed! %v", err)
+	}
+	second, _ := miner.GetWork()
+
+	if first.ID == second.ID || second.NonceStart != first.NonceStart+first.NonceCount {
+		t.Fatalf("GetWork() failed! expected consecutive ranges, got: %v and %v", first.NonceStart, second.NonceStart)
+	}
+	if string(first.Header) != string(second.Header) || first.Height != chain.ChainHeight {
+		t.Fatalf("GetWork() failed! expected the same template for the chain head")
+	}
+
+	// Unknown units and nonces outside the range are rejected
+	if _, err := miner.SubmitWork("unknown", 0); !errors.Is(err, ErrUnknownWork) {
+		t.Fatalf("SubmitWork() failed! expected: %v, got: %v", ErrUnknownWork, err)
+	}
+	if _, err := miner.SubmitWork(second.ID, first.NonceStart); err == nil {
+		t.Fatalf("SubmitWork() failed! expected an error for a nonce outside the range")
+	}
+
+	// A solved unit is connected to the chain
+	client := NewWorkClient("")
+	nonce, ok := client.Solve(context.Background(), first)
+	if !ok {
+		t.Fatalf("Solve() failed! no nonce found")
+	}
+
+	block, err := miner.SubmitWork(first.ID, nonce)
+	if err != nil {
+		t.Fatalf("SubmitWork() failed! %v", err)
+	}
+	if chain.ChainHeight != block.BlockHeight+1 {
+		t.Fatalf("SubmitWork() failed! expected height: %v, got: %v", block.BlockHeight+1, chain.ChainHeight)
+	}
+
+	// The other unit of the template is stale
+	if _, err := miner.SubmitWork(second.ID, second.NonceStart); !errors.Is(err, ErrStaleWork) {
+		t.Fatalf("SubmitWork() failed! expected: %v, got: %v", ErrStaleWork, err)
+	}
+
+	// The oldest units are dropped when the units reach the limit
+	miner.MaxWorkUnits = 2
+	oldest, _ := miner.GetWork()
+	for i := 0; i < 2; i++ {
+		miner.GetWork()
+	}
+	if len(miner.work) != 2 {
+		t.Fatalf("GetWork() failed! expected: 2 units, got: %v", len(miner.work))
+	}
+	if _, err := miner.SubmitWork(oldest.ID, oldest.NonceStart); !errors.Is(err, ErrUnknownWork) {
+		t.Fatalf("SubmitWork() failed! expected: %v, got: %v", ErrUnknownWork, err)
+	}
+}
+
+func Test_WorkClient(t *testing.T) {
+	chain := testchain(t)
+	miner := NewMiner(chain, nil, *wallet.NewWallet().GenerateAddress(byte(0x00)), core.DefaultTemplateOptions())
+
+	// Serve the work of the miner
+	server := httptest.NewServer(NewWorkHandler(miner))
+	defer server.Close()
+
+	// Fetch, solve and submit a work unit over HTTP
+	client := NewWorkClient(server.URL)
+	unit, err := client.GetWork(context.Background())
+	if err != nil {
+		t.Fatalf("GetWork() failed! %v", err)
+	}
+
+	nonce, ok := client.Solve(context.Background(), unit)
+	if !ok {
+		t.Fatalf("Solve() failed! no nonce found")
+	}
+
+	result, err := client.SubmitWork(context.Background(), unit.ID, nonce)
+	if err != nil {
+		t.Fatalf("SubmitWork() failed! %v", err)
+	}
+	if string(result.Hash) != string(chain.ChainHead) {
+		t.Fatalf("SubmitWork() failed! expected the solved block to be the chain head")
+	}
+
+	// Resubmitting the unit is rejected
+	if _, err := client.SubmitWork(context.Background(), unit.ID, nonce); err == nil {
+		t.Fatalf("SubmitWork() failed! expected an error for a stale unit")
+	}
+}
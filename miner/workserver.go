@@ -0,0 +1,111 @@
+package miner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/manishmeganathan/weave/node"
+	"github.com/sirupsen/logrus"
+)
+
+// Represents the default local address that work is served on to external miners
+const DefaultWorkAddress = "127.0.0.1:7015"
+
+// A structure that represents the request body of a solved work submission
+type WorkSubmission struct {
+	// Represents the ID of the solved work unit
+	ID string `json:"id"`
+
+	// Represents the nonce that solves the work unit
+	Nonce int `json:"nonce"`
+}
+
+// A structure that represents the response body of an accepted work submission
+type WorkResult struct {
+	// Represents the hash of the solved block
+	Hash []byte `json:"hash"`
+
+	// Represents the height of the solved block
+	Height int `json:"height"`
+}
+
+// A function that returns an HTTP handler that serves the work of a miner to external
+// miners. Work units are handed out with 'GET /work' and solved nonces are submitted
+// as a WorkSubmission with 'POST /submit'. Errors are returned as plain text.
+func NewWorkHandler(miner *Miner) http.Handler {
+	mux := http.NewServeMux()
+
+	// Hand out a work unit
+	mux.HandleFunc("GET /work", func(w http.ResponseWriter, r *http.Request) {
+		unit, err := miner.GetWork()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(unit)
+	})
+
+	// Accept a solved work unit
+	mux.HandleFunc("POST /submit", func(w http.ResponseWriter, r *http.Request) {
+		// Decode the submission
+		var submission WorkSubmission
+		if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+			http.Error(w, fmt.Sprintf("invalid submission! error - %v", err), http.StatusBadRequest)
+			return
+		}
+
+		// Submit the solved work to the miner
+		block, err := miner.SubmitWork(submission.ID, submission.Nonce)
+		switch {
+		case errors.Is(err, ErrUnknownWork), errors.Is(err, ErrStaleWork):
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(WorkResult{Hash: block.BlockHash, Height: block.BlockHeight})
+	})
+
+	return mux
+}
+
+// A constructor function that generates and returns a Service that serves the work
+// of a miner to external miners over HTTP on a local address. The work is served
+// independently of the mining loop, so the miner does not need to be started.
+func NewWorkService(miner *Miner, address string) node.Service {
+	server := &http.Server{Handler: NewWorkHandler(miner)}
+
+	start := func(ctx context.Context) error {
+		// Listen on the address
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+
+		// Serve the work in the background
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logrus.WithFields(logrus.Fields{"error": err}).Errorln("work server failed.")
+			}
+		}()
+
+		logrus.WithFields(logrus.Fields{"address": listener.Addr()}).Infoln("serving work to external miners.")
+		return nil
+	}
+
+	stop := func(ctx context.Context) error {
+		// Shutdown the server within the deadline
+		return server.Shutdown(ctx)
+	}
+
+	return node.NewService("work", start, stop)
+}
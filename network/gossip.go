@@ -0,0 +1,169 @@
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/protos"
+	"github.com/manishmeganathan/weave/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// A function that converts the result of a gossip validation into its pubsub result
+func validationresult(validation wire.Validation) pubsub.ValidationResult {
+	switch validation {
+	case wire.ValidationAccept:
+		return pubsub.ValidationAccept
+	case wire.ValidationIgnore:
+		return pubsub.ValidationIgnore
+	default:
+		return pubsub.ValidationReject
+	}
+}
+
+// A method of NodeHost that joins the gossip topics for blocks and transactions. Validators
+// are registered for the topics, so that invalid messages are rejected before they are
+// relayed, and the messages that are delivered are fed into the chain and the pool.
+func (node *NodeHost) JoinTopics() error {
+	// Register the validator of the block topic
+	blockvalidator := func(ctx context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		// Accept the blocks published by the node itself
+		if from == node.Host.ID() {
+			return pubsub.ValidationAccept
+		}
+
+		// Validate the block and attach it to the message for delivery
+		block, validation := node.Handler.ValidateBlockMessage(msg.GetData())
+		msg.ValidatorData = block
+		return validationresult(validation)
+	}
+
+	if err := node.PubSub.RegisterTopicValidator(wire.BlockTopic, pubsub.ValidatorEx(blockvalidator)); err != nil {
+		return fmt.Errorf("failed to register block validator! error - %v", err)
+	}
+
+	// Register the validator of the transaction topic
+	txnvalidator := func(ctx context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		// Accept the transactions published by the node itself
+		if from == node.Host.ID() {
+			return pubsub.ValidationAccept
+		}
+
+		// Validate the transaction, which adds it to the pool
+		_, validation := node.Handler.ValidateTxnMessage(msg.GetData(), msg.ReceivedFrom.String())
+		return validationresult(validation)
+	}
+
+	if err := node.PubSub.RegisterTopicValidator(wire.TxnTopic, pubsub.ValidatorEx(txnvalidator)); err != nil {
+		return fmt.Errorf("failed to register transaction validator! error - %v", err)
+	}
+
+	// Join the block topic
+	blocktopic, err := node.PubSub.Join(wire.BlockTopic)
+	if err != nil {
+		return fmt.Errorf("failed to join block topic! error - %v", err)
+	}
+
+	// Join the transaction topic
+	txntopic, err := node.PubSub.Join(wire.TxnTopic)
+	if err != nil {
+		return fmt.Errorf("failed to join transaction topic! error - %v", err)
+	}
+
+	// Subscribe to the block topic
+	blocksub, err := blocktopic.Subscribe()
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to block topic! error - %v", err)
+	}
+
+	// Subscribe to the transaction topic, the transactions are added to the pool by its
+	// validator but the subscription is required to receive and relay the messages
+	txnsub, err := txntopic.Subscribe()
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to transaction topic! error - %v", err)
+	}
+
+	node.blocktopic = blocktopic
+	node.txntopic = txntopic
+
+	// Feed the delivered blocks into the chain
+	go node.handleBlocks(blocksub)
+	// Drain the delivered transactions
+	go node.handleTxns(txnsub)
+
+	return nil
+}
+
+// A method of NodeHost that submits the blocks delivered on the block topic to the chain.
+// Meant to be started as a go routine, returns when the host context is cancelled.
+func (node *NodeHost) handleBlocks(sub *pubsub.Subscription) {
+	defer sub.Cancel()
+
+	for {
+		// Wait for the next delivered message
+		msg, err := sub.Next(node.Ctx)
+		if err != nil {
+			return
+		}
+
+		// Ignore the blocks published by the node itself
+		block, ok := msg.ValidatorData.(*core.Block)
+		if msg.ReceivedFrom == node.Host.ID() || !ok {
+			continue
+		}
+
+		// Submit the block to the chain
+		connected, err := node.Handler.SubmitBlock(block, msg.ReceivedFrom.String())
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"peer": msg.ReceivedFrom, "hash": block.BlockHash, "error": err}).Debugln("gossiped block was not connected.")
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{"peer": msg.ReceivedFrom, "height": block.BlockHeight, "connected": len(connected)}).Infoln("connected gossiped block.")
+	}
+}
+
+// A method of NodeHost that drains the messages delivered on the transaction topic.
+// Meant to be started as a go routine, returns when the host context is cancelled.
+func (node *NodeHost) handleTxns(sub *pubsub.Subscription) {
+	defer sub.Cancel()
+
+	for {
+		// Wait for the next delivered message
+		if _, err := sub.Next(node.Ctx); err != nil {
+			return
+		}
+	}
+}
+
+// A method of NodeHost that publishes an entity on a gossip topic
+func (node *NodeHost) publish(topic *pubsub.Topic, entity *protos.Entity) error {
+	// Check that the node has joined the topics
+	if topic == nil {
+		return fmt.Errorf("gossip topics have not been joined")
+	}
+
+	// Encode and publish the entity
+	data, err := wire.EncodeEntity(entity)
+	if err != nil {
+		return err
+	}
+
+	return topic.Publish(node.Ctx, data)
+}
+
+// A method of NodeHost that publishes a block on the block topic. The signature
+// matches the broadcast hook of a Miner, so that mined blocks are published.
+func (node *NodeHost) BroadcastBlock(block *core.Block) {
+	if err := node.publish(node.blocktopic, wire.BlockEntity(block)); err != nil {
+		logrus.WithFields(logrus.Fields{"hash": block.BlockHash, "error": err}).Warnln("failed to broadcast block.")
+	}
+}
+
+// A method of NodeHost that publishes a transaction on the transaction topic
+func (node *NodeHost) BroadcastTxn(txn *core.Transaction) error {
+	return node.publish(node.txntopic, wire.TxnEntity(txn))
+}
@@ -0,0 +1,69 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/protos"
+	"github.com/manishmeganathan/weave/wallet"
+	"github.com/manishmeganathan/weave/wire"
+)
+
+func Test_GossipBlocks(t *testing.T) {
+	chain, _ := testwalletchain(t)
+	remote := testnodehost(t, chain, nil)
+
+	// Create a chain with the same genesis block on the local host
+	genesis, _ := chain.GetBlock(chain.ChainHead)
+	peerchain, err := core.NewBlockChainWithOptions(core.ChainOptions{InMemory: true, Params: testparams, Genesis: genesis})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+	t.Cleanup(peerchain.CloseBuckets)
+	local := testnodehost(t, peerchain, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// Connect the hosts directly
+	if err := local.Host.Connect(ctx, remote.AddrInfo()); err != nil {
+		t.Fatalf("Connect() failed! %v", err)
+	}
+
+	// Wait for the remote host to be in the mesh of the block topic
+	for len(local.blocktopic.ListPeers()) == 0 || len(remote.blocktopic.ListPeers()) == 0 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("JoinTopics() failed! expected the hosts to peer on the block topic")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	// A block mined and broadcast by the remote host is connected to the local chain
+	address := *wallet.NewWallet().GenerateAddress(byte(0x00))
+	block := chain.AddBlock([]*core.Transaction{core.NewCoinbaseTransaction(address, testparams.Reward)}, address)
+
+	// The block is delivered asynchronously, so wait for its status on the local chain. The block
+	// is published again while waiting, since messages published before the hosts have formed
+	// the mesh of the topic can be lost. Blocks that are already on the chain are ignored.
+	query := &protos.Query{Type: protos.Query_STATUS, Body: &protos.Query_Status{Status: &protos.StatusQuery{Type: protos.Entitytype_BLOCK, Entityhash: block.BlockHash}}}
+	for {
+		remote.BroadcastBlock(block)
+
+		response, err := local.Handler.HandleQuery(query)
+		if err != nil {
+			t.Fatalf("HandleQuery() failed! %v", err)
+		}
+		if response.GetStatusresponse().GetStatus() == wire.StatusConfirmed {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			t.Fatalf("BroadcastBlock() failed! expected the block to be connected to the peer chain")
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/protos"
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/manishmeganathan/weave/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// A structure that represents the client of a light node. A LightClient runs a light host
+// that does not serve the weave protocol, syncs the headers of the chain from the full
+// nodes that it is connected to and verifies transactions with proofs from those nodes.
+type LightClient struct {
+	// Represents the light host of the client
+	Host *NodeHost
+
+	// Represents the header chain of the client
+	Headers *core.HeaderChain
+
+	// Represents the full nodes that the client connects to directly when started
+	peers []peer.AddrInfo
+
+	// Represents the interval between header syncs (0 disables periodic syncs)
+	interval time.Duration
+
+	// Represents the lock that serializes access to the header chain
+	mutex sync.Mutex
+
+	// Represents the channels for stopping the periodic syncs
+	stop, done chan struct{}
+}
+
+// A constructor function that generates and returns a LightClient for a header chain.
+// The client connects to the given peers when it is started and syncs headers from its
+// peers at every interval (if positive). Returns an error if the host cannot be set up.
+func NewLightClient(headers *core.HeaderChain, config HostConfig, peers []peer.AddrInfo, interval time.Duration) (*LightClient, error) {
+	// Create a light host without a protocol handler
+	host, err := NewNodeHost(nil, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LightClient{Host: host, Headers: headers, peers: peers, interval: interval}, nil
+}
+
+// A method of LightClient that returns the name of the light client service
+func (client *LightClient) Name() string {
+	return "light"
+}
+
+// A method of LightClient that starts the light client service. The host is started,
+// connected to the peers of the client and headers are synced at every interval.
+// Returns an error if the host cannot be started or no peer could be connected to.
+func (client *LightClient) Start(ctx context.Context) error {
+	// Start the light host
+	if err := client.Host.Start(ctx); err != nil {
+		return err
+	}
+
+	// Connect to the peers of the client
+	connected := 0
+	for _, peerinfo := range client.peers {
+		if err := client.Host.Host.Connect(ctx, peerinfo); err != nil {
+			logrus.WithFields(logrus.Fields{"peer": peerinfo.ID, "error": err}).Warnln("failed to connect to peer.")
+			continue
+		}
+
+		connected++
+	}
+
+	// Check that a peer was connected to if any were given
+	if len(client.peers) > 0 && connected == 0 {
+		return fmt.Errorf("failed to connect to any of the %v peers", len(client.peers))
+	}
+
+	// Check if headers are synced periodically
+	if client.interval <= 0 {
+		return nil
+	}
+
+	// Sync headers from the peers at every interval
+	client.stop, client.done = make(chan struct{}), make(chan struct{})
+	go func(stop, done chan struct{}) {
+		defer close(done)
+
+		ticker := time.NewTicker(client.interval)
+		defer ticker.Stop()
+
+		for {
+			// Sync the headers from the connected peers
+			if added, err := client.Sync(client.Host.Ctx); err != nil {
+				logrus.WithFields(logrus.Fields{"error": err}).Warnln("failed to sync headers.")
+			} else if added > 0 {
+				logrus.WithFields(logrus.Fields{"added": added, "height": client.Height()}).Infoln("synced headers.")
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}(client.stop, client.done)
+
+	return nil
+}
+
+// A method of LightClient that stops the light client service. The periodic
+// syncs are stopped and the host is closed. The header chain is left open.
+func (client *LightClient) Stop(ctx context.Context) error {
+	// Stop the periodic syncs if they were started
+	if client.stop != nil {
+		close(client.stop)
+		// Cancel the host context to end an ongoing sync
+		client.Host.cancel()
+
+		select {
+		case <-client.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		client.stop = nil
+	}
+
+	// Stop the light host
+	return client.Host.Stop(ctx)
+}
+
+// A method of LightClient that returns the height of its header chain
+func (client *LightClient) Height() int {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	return client.Headers.ChainHeight
+}
+
+// A method of LightClient that returns the connected peers that serve the weave protocol.
+// The peers of the client are returned first, followed by the peers that were discovered.
+func (client *LightClient) Peers() []peer.ID {
+	peers := []peer.ID{}
+	host := client.Host.Host
+
+	// Collect the connected peers of the client
+	for _, peerinfo := range client.peers {
+		if len(host.Network().ConnsToPeer(peerinfo.ID)) > 0 {
+			peers = append(peers, peerinfo.ID)
+		}
+	}
+
+	// Collect the other connected peers that support the weave protocol
+	for _, peerid := range host.Network().Peers() {
+		if supported, err := host.Peerstore().SupportsProtocols(peerid, string(weaveprotocol)); err != nil || len(supported) == 0 {
+			continue
+		}
+
+		if !containspeer(client.peers, peerid) {
+			peers = append(peers, peerid)
+		}
+	}
+
+	return peers
+}
+
+// A function that checks if a peer is in a list of peer address information
+func containspeer(peers []peer.AddrInfo, peerid peer.ID) bool {
+	for _, peerinfo := range peers {
+		if peerinfo.ID == peerid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A method of LightClient that syncs headers from the weave peers that the host is connected to.
+// The peers are tried in turn until headers are synced from one of them. Returns the number
+// of headers that were added and an error if the headers could not be synced from any peer.
+func (client *LightClient) Sync(ctx context.Context) (int, error) {
+	// Retrieve the connected weave peers
+	peers := client.Peers()
+	if len(peers) == 0 {
+		return 0, fmt.Errorf("no connected peers to sync headers from")
+	}
+
+	var err error
+	for _, peerid := range peers {
+		// Sync the headers from the peer
+		var added int
+		if added, err = client.SyncHeaders(ctx, peerid); err == nil {
+			return added, nil
+		}
+
+		logrus.WithFields(logrus.Fields{"peer": peerid, "error": err}).Debugln("failed to sync headers from peer.")
+	}
+
+	return 0, err
+}
+
+// A method of LightClient that syncs headers from a peer. The headers above the header chain
+// are queried from the peer in batches of upto wire.MaxHeaders and added to the header chain
+// in order, which validates their proof of work and linkage. Returns the number of headers that
+// were added and an error if the peer fails to answer or answers with an invalid header.
+func (client *LightClient) SyncHeaders(ctx context.Context, peerid peer.ID) (int, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	added := 0
+	for {
+		// Query the headers above the header chain
+		query := &protos.Query{Type: protos.Query_HEADERS, Body: &protos.Query_Headers{Headers: &protos.HeadersQuery{
+			Startheight: uint32(client.Headers.ChainHeight),
+			Count:       wire.MaxHeaders,
+		}}}
+
+		response, err := client.Host.Query(ctx, peerid, query)
+		if err != nil {
+			return added, err
+		}
+
+		// Add the headers to the header chain in order
+		headers := response.GetHeadersresponse().GetHeaders()
+		for _, header := range headers {
+			entry, err := wire.DecodeHeader(header)
+			if err != nil {
+				return added, err
+			}
+
+			if err := client.Headers.AddHeader(entry); err != nil {
+				return added, err
+			}
+
+			added++
+		}
+
+		// Check if the peer has no more headers
+		if len(headers) < wire.MaxHeaders {
+			return added, nil
+		}
+	}
+}
+
+// A method of LightClient that verifies that a transaction is on the chain with a proof
+// from a peer. The proof is verified against the header of its block, which must already
+// be on the header chain. Returns the transaction and the hash of its block and an error
+// if the peer fails to answer or the transaction is not included in the block.
+func (client *LightClient) ProveTxn(ctx context.Context, peerid peer.ID, txid utils.Hash) (*core.Transaction, utils.Hash, error) {
+	// Query the inclusion proof of the transaction
+	query := &protos.Query{Type: protos.Query_PROOF, Body: &protos.Query_Proof{Proof: &protos.ProofQuery{Txnhash: txid}}}
+	response, err := client.Host.Query(ctx, peerid, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode the transaction and its proof
+	txn, blockhash, proof, err := wire.DecodeProof(response.GetProofresponse())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check that the peer proved the requested transaction
+	if !bytes.Equal(txn.ID, txid) {
+		return nil, nil, fmt.Errorf("peer proved a different transaction")
+	}
+
+	// Verify the proof against the header chain
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if err := client.Headers.VerifyInclusion(txn, blockhash, proof); err != nil {
+		return nil, nil, err
+	}
+
+	return txn, blockhash, nil
+}
@@ -0,0 +1,72 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+func Test_LightClient(t *testing.T) {
+	chain, _ := testwalletchain(t)
+	remote := testnodehost(t, chain, nil)
+
+	// Mine some blocks on the chain of the full node
+	address := *wallet.NewWallet().GenerateAddress(byte(0x00))
+	for i := 0; i < 3; i++ {
+		chain.AddBlock([]*core.Transaction{core.NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	}
+
+	// Create a light client that connects to the full node
+	headers := core.NewMemoryHeaderChain(testparams)
+	client, err := NewLightClient(headers, HostConfig{ListenAddr: "/ip4/127.0.0.1/tcp/0"}, []peer.AddrInfo{remote.AddrInfo()}, 0)
+	if err != nil {
+		t.Fatalf("NewLightClient() failed! %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Start() failed! %v", err)
+	}
+	defer client.Stop(context.Background())
+
+	// The headers of the full node are synced to the header chain
+	added, err := client.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() failed! %v", err)
+	}
+	if added != chain.ChainHeight || client.Height() != chain.ChainHeight || string(headers.ChainHead) != string(chain.ChainHead) {
+		t.Fatalf("Sync() failed! expected: %v headers, got: %v", chain.ChainHeight, added)
+	}
+
+	// The headers mined after a sync are added by the next sync
+	block := chain.AddBlock([]*core.Transaction{core.NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	if added, err := client.SyncHeaders(ctx, remote.Host.ID()); err != nil || added != 1 {
+		t.Fatalf("SyncHeaders() failed! expected: 1 header, got: %v (%v)", added, err)
+	}
+
+	// A transaction is verified with a proof from the full node
+	txn, blockhash, err := client.ProveTxn(ctx, remote.Host.ID(), block.TXList[0].ID)
+	if err != nil {
+		t.Fatalf("ProveTxn() failed! %v", err)
+	}
+	if string(txn.ID) != string(block.TXList[0].ID) || string(blockhash) != string(block.BlockHash) {
+		t.Fatalf("ProveTxn() failed! expected: block %x, got: %x", block.BlockHash, blockhash)
+	}
+
+	// A transaction in a block whose header has not been synced is not verified
+	unsynced := chain.AddBlock([]*core.Transaction{core.NewCoinbaseTransaction(address, testparams.Reward+1)}, address)
+	if _, _, err := client.ProveTxn(ctx, remote.Host.ID(), unsynced.TXList[0].ID); err == nil {
+		t.Fatalf("ProveTxn() failed! expected an error for a block that is not on the header chain")
+	}
+
+	// A transaction that is not on the chain of the full node is not proved
+	if _, _, err := client.ProveTxn(ctx, remote.Host.ID(), []byte{1, 2, 3}); err == nil {
+		t.Fatalf("ProveTxn() failed! expected an error for an unknown transaction")
+	}
+}
@@ -1,19 +1,17 @@
 package network
 
 import (
-	"bufio"
 	"context"
 	"crypto/rand"
 	"fmt"
-	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	libp2p "github.com/libp2p/go-libp2p"
 	connmgr "github.com/libp2p/go-libp2p-connmgr"
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	host "github.com/libp2p/go-libp2p-core/host"
-	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/routing"
 	discovery "github.com/libp2p/go-libp2p-discovery"
@@ -22,12 +20,39 @@ import (
 	tls "github.com/libp2p/go-libp2p-tls"
 	yamux "github.com/libp2p/go-libp2p-yamux"
 	tcp "github.com/libp2p/go-tcp-transport"
+	"github.com/manishmeganathan/weave/wire"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/sirupsen/logrus"
 )
 
 const service = "manishmeganathan/weave"
 
+// Represents the service advertised by nodes that have pruned old block bodies.
+// Such nodes can serve headers, filters and recent blocks but not the full chain.
+const prunedservice = service + "/pruned"
+
+// A structure that represents the configuration of a NodeHost
+type HostConfig struct {
+	// Represents the multiaddress that the host listens on
+	ListenAddr string
+
+	// Represents whether the host joins the public network. The DHT is bootstrapped
+	// from the default bootstrap peers of libp2p, NAT traversal and relays are enabled
+	// and the peers of the weave service are discovered. Peers must be connected to
+	// directly if the host does not join the public network (such as in tests).
+	Bootstrap bool
+
+	// Represents whether the node prunes its block bodies, which is advertised
+	// to peers so that full blocks are not requested from the node
+	Pruned bool
+}
+
+// A constructor function that generates and returns the default HostConfig,
+// which listens on a random port of every interface and joins the public network
+func DefaultHostConfig() HostConfig {
+	return HostConfig{ListenAddr: "/ip4/0.0.0.0/tcp/0", Bootstrap: true}
+}
+
 type NodeHost struct {
 	// Represents the host context
 	Ctx context.Context
@@ -39,26 +64,47 @@ type NodeHost struct {
 	Discovery *discovery.RoutingDiscovery
 	// Represents the PubSub Handler
 	PubSub *pubsub.PubSub
+	// Represents the handler of the weave protocol messages
+	Handler *wire.Handler
+
+	// Represents the configuration of the host
+	config HostConfig
+
+	// Represents the gossip topic for new blocks
+	blocktopic *pubsub.Topic
+	// Represents the gossip topic for new transactions
+	txntopic *pubsub.Topic
+
+	// Represents the cancel function of the host context
+	cancel context.CancelFunc
 }
 
-func NewNodeHost() *NodeHost {
-	// Setup a background context
-	ctx := context.Background()
+// A constructor function that generates and returns a NodeHost for a configuration.
+// Messages of the weave protocol are handled by the given handler, which answers
+// queries from the chain and pool of the node. The host listens for peers and joins
+// the gossip topics but is connected to the public network when it is started.
+// A host with a nil handler is a light host, which only sends queries to its peers.
+// Returns an error if the host, its DHT or its gossip topics cannot be set up.
+func NewNodeHost(handler *wire.Handler, config HostConfig) (*NodeHost, error) {
+	// Setup a cancellable background context
+	ctx, cancel := context.WithCancel(context.Background())
 
 	// Setup a P2P Host Node
-	nodehost, kaddht := setupNodeHost(ctx)
-	// Bootstrap the Kad DHT
-	bootstrapDHT(ctx, nodehost, kaddht)
+	nodehost, kaddht, err := setupNodeHost(ctx, config)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 
 	// Create a peer discovery service using the Kad DHT
 	routingdiscovery := discovery.NewRoutingDiscovery(kaddht)
 	// Create a PubSub handler with the routing discovery
-	pubsubhandler := setupPubSub(ctx, nodehost, routingdiscovery)
-
-	// Debug log
-	// logrus.Debugln("Created the P2P Host and the Kademlia DHT.")
-
-	nodehost.SetStreamHandler("/chat/1.0.0", handleStream)
+	pubsubhandler, err := pubsub.NewGossipSub(ctx, nodehost, pubsub.WithDiscovery(routingdiscovery))
+	if err != nil {
+		cancel()
+		nodehost.Close()
+		return nil, fmt.Errorf("failed to create pubsub handler! error - %v", err)
+	}
 
 	node := &NodeHost{
 		Ctx:       ctx,
@@ -66,109 +112,155 @@ func NewNodeHost() *NodeHost {
 		KadDHT:    kaddht,
 		Discovery: routingdiscovery,
 		PubSub:    pubsubhandler,
+		Handler:   handler,
+		config:    config,
+		cancel:    cancel,
+	}
+
+	// Check if the host serves the weave protocol (light hosts have no handler)
+	if handler != nil {
+		// Handle the streams of the weave protocol
+		nodehost.SetStreamHandler(weaveprotocol, node.handleWeaveStream)
+
+		// Join the gossip topics for blocks and transactions
+		if err := node.JoinTopics(); err != nil {
+			node.Stop(context.Background())
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
+// A method of NodeHost that returns the name of the network service
+func (node *NodeHost) Name() string {
+	return "network"
+}
+
+// A method of NodeHost that starts the network service. A host that joins the public
+// network bootstraps its DHT, advertises the weave service and connects to the peers
+// that are discovered. Returns an error if the context is cancelled while starting.
+func (node *NodeHost) Start(ctx context.Context) error {
+	// Check if the host joins the public network
+	if !node.config.Bootstrap {
+		return nil
+	}
+
+	// Bootstrap the Kad DHT
+	if err := bootstrapDHT(ctx, node.Host, node.KadDHT); err != nil {
+		return err
 	}
 
-	node.AdvertiseConnect()
-	return node
+	// Connect to the peers of the weave service
+	return node.AdvertiseConnect(ctx)
+}
+
+// A method of NodeHost that stops the network service. The host context is
+// cancelled, which ends peer discovery, and the DHT and the host are closed.
+func (node *NodeHost) Stop(ctx context.Context) error {
+	// Cancel the host context
+	node.cancel()
+
+	// Close the Kad DHT
+	if err := node.KadDHT.Close(); err != nil {
+		return err
+	}
 
+	// Close the libp2p host
+	return node.Host.Close()
 }
 
-func setupNodeHost(ctx context.Context) (host.Host, *dht.IpfsDHT) {
+// A method of NodeHost that returns the address information of the host,
+// which can be used by other hosts to connect to it directly.
+func (node *NodeHost) AddrInfo() peer.AddrInfo {
+	return peer.AddrInfo{ID: node.Host.ID(), Addrs: node.Host.Addrs()}
+}
+
+// A function that generates a libp2p host with a Kademlia DHT for a configuration
+func setupNodeHost(ctx context.Context, config HostConfig) (host.Host, *dht.IpfsDHT, error) {
 	// Set up the host identity options
 	prvkey, _, err := crypto.GenerateKeyPairWithReader(crypto.ECDSA, 2048, rand.Reader)
-	identity := libp2p.Identity(prvkey)
-	// Handle any potential error
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to generate p2p host identity!")
+		return nil, nil, fmt.Errorf("failed to generate p2p host identity! error - %v", err)
 	}
+	identity := libp2p.Identity(prvkey)
 
 	// Set up TLS secured TCP transport and options
 	tlstransport, err := tls.New(prvkey)
-	security := libp2p.Security(tls.ID, tlstransport)
-	transport := libp2p.Transport(tcp.NewTCPTransport)
-	// Handle any potential error
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to generate p2p host security and transport configurations!")
+		return nil, nil, fmt.Errorf("failed to generate p2p host security configuration! error - %v", err)
 	}
+	security := libp2p.Security(tls.ID, tlstransport)
+	transport := libp2p.Transport(tcp.NewTCPTransport)
 
 	// Set up host listener address options
-	muladdr, err := multiaddr.NewMultiaddr("/ip4/0.0.0.0/tcp/0")
-	listen := libp2p.ListenAddrs(muladdr)
-	// Handle any potential error
+	muladdr, err := multiaddr.NewMultiaddr(config.ListenAddr)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("Failed to generate p2p host address listener configuration!")
+		return nil, nil, fmt.Errorf("invalid p2p host listen address! error - %v", err)
 	}
+	listen := libp2p.ListenAddrs(muladdr)
 
 	// Set up the stream multiplexer and connection manager options
 	muxer := libp2p.Muxer("/yamux/1.0.0", yamux.DefaultTransport)
 	conn := libp2p.ConnectionManager(connmgr.NewConnManager(100, 400, time.Minute))
 
-	// Setup NAT traversal and relay options
-	nat := libp2p.NATPortMap()
-	relay := libp2p.EnableAutoRelay()
-
 	// Declare a KadDHT
 	var kaddht *dht.IpfsDHT
 	// Setup a routing configuration with the KadDHT
 	routing := libp2p.Routing(func(h host.Host) (routing.PeerRouting, error) {
-		kaddht = setupKadDHT(ctx, h)
+		var err error
+		kaddht, err = setupKadDHT(ctx, h, config)
 		return kaddht, err
 	})
 
-	opts := libp2p.ChainOptions(identity, listen, security, transport, muxer, conn, nat, routing, relay)
+	opts := []libp2p.Option{identity, listen, security, transport, muxer, conn, routing}
+	// Setup NAT traversal and relay options for a host on the public network
+	if config.Bootstrap {
+		opts = append(opts, libp2p.NATPortMap(), libp2p.EnableAutoRelay())
+	}
 
 	// Construct a new libP2P host with the created options
-	libhost, err := libp2p.New(ctx, opts)
-	// Handle any potential error
+	libhost, err := libp2p.New(ctx, opts...)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to create the p2p host!")
+		return nil, nil, fmt.Errorf("failed to create the p2p host! error - %v", err)
 	}
 
 	// Return the created host and the kademlia DHT
-	return libhost, kaddht
+	return libhost, kaddht, nil
 }
 
-// A function that generates a Kademlia DHT object and returns it
-func setupKadDHT(ctx context.Context, nodehost host.Host) *dht.IpfsDHT {
+// A function that generates a Kademlia DHT object for a configuration and returns it.
+// The DHT is given the default bootstrap peers if the host joins the public network.
+func setupKadDHT(ctx context.Context, nodehost host.Host, config HostConfig) (*dht.IpfsDHT, error) {
 	// Create DHT server mode option
-	dhtmode := dht.Mode(dht.ModeServer)
-	// Rertieve the list of boostrap peer addresses
-	bootstrappeers := dht.GetDefaultBootstrapPeerAddrInfos()
+	options := []dht.Option{dht.Mode(dht.ModeServer)}
 	// Create the DHT bootstrap peers option
-	dhtpeers := dht.BootstrapPeers(bootstrappeers...)
-
-	// // Trace log
-	// logrus.Traceln("Generated DHT Configuration.")
+	if config.Bootstrap {
+		options = append(options, dht.BootstrapPeers(dht.GetDefaultBootstrapPeerAddrInfos()...))
+	}
 
 	// Start a Kademlia DHT on the host in server mode
-	kaddht, err := dht.New(ctx, nodehost, dhtmode, dhtpeers)
-	// Handle any potential error
+	kaddht, err := dht.New(ctx, nodehost, options...)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to create the kademlia DHT!")
+		return nil, fmt.Errorf("failed to create the kademlia DHT! error - %v", err)
 	}
 
 	// Return the KadDHT
-	return kaddht
+	return kaddht, nil
 }
 
 // A function that bootstraps a given Kademlia DHT to satisfy the IPFS router
 // interface and connects to all the bootstrap peers provided by libp2p
-func bootstrapDHT(ctx context.Context, nodehost host.Host, kaddht *dht.IpfsDHT) {
+func bootstrapDHT(ctx context.Context, nodehost host.Host, kaddht *dht.IpfsDHT) error {
 	// Bootstrap the DHT to satisfy the IPFS Router interface
 	if err := kaddht.Bootstrap(ctx); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"error": err.Error(),
-		}).Fatalln("Failed to Bootstrap the Kademlia!")
+		return fmt.Errorf("failed to bootstrap the kademlia DHT! error - %v", err)
 	}
 
-	// Trace log
-	// logrus.Traceln("Set the Kademlia DHT into Bootstrap Mode.")
-
 	// Declare a WaitGroup
 	var wg sync.WaitGroup
-	// Declare counters for the number of bootstrap peers
-	var connectedbootpeers int
-	var totalbootpeers int
+	// Declare a counter for the number of connected bootstrap peers
+	var connectedbootpeers int64
 
 	// Iterate over the default bootstrap peers provided by libp2p
 	for _, peeraddr := range dht.DefaultBootstrapPeers {
@@ -182,14 +274,9 @@ func bootstrapDHT(ctx context.Context, nodehost host.Host, kaddht *dht.IpfsDHT)
 			// Defer the waitgroup decrement
 			defer wg.Done()
 			// Attempt to connect to the bootstrap peer
-			if err := nodehost.Connect(ctx, *peerinfo); err != nil {
-				// Increment the total bootstrap peer count
-				totalbootpeers++
-			} else {
+			if err := nodehost.Connect(ctx, *peerinfo); err == nil {
 				// Increment the connected bootstrap peer count
-				connectedbootpeers++
-				// Increment the total bootstrap peer count
-				totalbootpeers++
+				atomic.AddInt64(&connectedbootpeers, 1)
 			}
 		}()
 	}
@@ -198,56 +285,57 @@ func bootstrapDHT(ctx context.Context, nodehost host.Host, kaddht *dht.IpfsDHT)
 	wg.Wait()
 
 	// Log the number of bootstrap peers connected
-	logrus.Debugf("Connected to %d out of %d Bootstrap Peers.", connectedbootpeers, totalbootpeers)
-}
-
-// A function that generates a PubSub Handler object and returns it
-// Requires a node host and a routing discovery service.
-func setupPubSub(ctx context.Context, nodehost host.Host, routingdiscovery *discovery.RoutingDiscovery) *pubsub.PubSub {
-	// Create a new PubSub service which uses a GossipSub router
-	pubsubhandler, err := pubsub.NewGossipSub(ctx, nodehost, pubsub.WithDiscovery(routingdiscovery))
-	// Handle any potential error
-	if err != nil {
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to create pubsub handler")
-	}
-
-	// Return the PubSub handler
-	return pubsubhandler
+	logrus.Debugf("Connected to %d out of %d Bootstrap Peers.", connectedbootpeers, len(dht.DefaultBootstrapPeers))
+	return nil
 }
 
 // A method of P2P to connect to service peers.
 // This method uses the Advertise() functionality of the Peer Discovery Service
 // to advertise the service and then disovers all peers advertising the same.
 // The peer discovery is handled by a go-routine that will read from a channel
-// of peer address information until the peer channel closes
-func (node *NodeHost) AdvertiseConnect() {
-	// Advertise the availabilty of the service on this node
-	ttl, err := node.Discovery.Advertise(node.Ctx, service)
-	// Debug log
-	// logrus.Debugln("Advertised the PeerChat Service.")
-	// Sleep to give time for the advertisment to propogate
-	time.Sleep(time.Second * 5)
-	// Debug log
-	logrus.Debugf("Service Time-to-Live is %s", ttl)
+// of peer address information until the peer channel closes. The advertisment
+// lasts for the lifetime of the host but the given context bounds the wait for
+// it to propogate. Returns an error if the wait is cancelled or discovery fails.
+func (node *NodeHost) AdvertiseConnect(ctx context.Context) error {
+	// Check if the host serves the weave protocol (light hosts are not advertised)
+	if node.Handler != nil {
+		// Advertise the availabilty of the service on this node
+		ttl, err := node.Discovery.Advertise(node.Ctx, service)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"error": err}).Warn("failed to advertise service.")
+		}
+		// Wait to give time for the advertisment to propogate
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second * 5):
+		}
+		// Debug log
+		logrus.Debugf("Service Time-to-Live is %s", ttl)
+	}
+
+	// Check if the node runs in block pruning mode
+	if node.config.Pruned {
+		// Advertise the limited service of the pruned node
+		if _, err := node.Discovery.Advertise(node.Ctx, prunedservice); err != nil {
+			logrus.WithFields(logrus.Fields{"error": err}).Warn("failed to advertise pruned service.")
+		}
+	}
 
 	// Find all peers advertising the same service
 	peerchan, err := node.Discovery.FindPeers(node.Ctx, service)
-	// Handle any potential error
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"error": err}).Fatalln("P2P Peer Discovery Failed!")
+		return fmt.Errorf("p2p peer discovery failed! error - %v", err)
 	}
-	// Trace log
-	// logrus.Traceln("Discovered PeerChat Service Peers.")
 
 	// Connect to peers as they are discovered
-	go handlePeerDiscovery(node.Host, peerchan)
-	// Trace log
-	// logrus.Traceln("Started Peer Connection Handler.")
+	go handlePeerDiscovery(node.Ctx, node.Host, peerchan)
+	return nil
 }
 
 // A function that connects the given host to all peers recieved from a
 // channel of peer address information. Meant to be started as a go routine.
-func handlePeerDiscovery(nodehost host.Host, peerchan <-chan peer.AddrInfo) {
+func handlePeerDiscovery(ctx context.Context, nodehost host.Host, peerchan <-chan peer.AddrInfo) {
 	// Iterate over the peer channel
 	for peer := range peerchan {
 		// Ignore if the discovered peer is the host itself
@@ -256,73 +344,8 @@ func handlePeerDiscovery(nodehost host.Host, peerchan <-chan peer.AddrInfo) {
 		}
 
 		// Connect to the peer
-		nodehost.Connect(context.Background(), peer)
-
-		stream, err := nodehost.NewStream(context.Background(), peer.ID, "/chat/1.0.0")
-		if err != nil {
-			fmt.Println("panic!panic!")
-		} else {
-			rw := bufio.NewReadWriter(bufio.NewReader(stream), bufio.NewWriter(stream))
-
-			go writeData(rw)
-			go readData(rw)
-		}
-
-	}
-}
-
-func handleStream(stream network.Stream) {
-	// logger.Info("Got a new stream!")
-
-	// Create a buffer stream for non blocking read and write.
-	rw := bufio.NewReadWriter(bufio.NewReader(stream), bufio.NewWriter(stream))
-
-	go readData(rw)
-	go writeData(rw)
-
-	// 'stream' will stay open until you close it (or the other side closes it).
-}
-
-func readData(rw *bufio.ReadWriter) {
-	for {
-		str, err := rw.ReadString('\n')
-		if err != nil {
-			fmt.Println("Error reading from buffer")
-			panic(err)
-		}
-
-		if str == "" {
-			return
-		}
-		if str != "\n" {
-			// Green console colour: 	\x1b[32m
-			// Reset console colour: 	\x1b[0m
-			fmt.Printf("\x1b[32m%s\x1b[0m> ", str)
-		}
-
-	}
-}
-
-func writeData(rw *bufio.ReadWriter) {
-	stdReader := bufio.NewReader(os.Stdin)
-
-	for {
-		fmt.Print("> ")
-		sendData, err := stdReader.ReadString('\n')
-		if err != nil {
-			fmt.Println("Error reading from stdin")
-			panic(err)
-		}
-
-		_, err = rw.WriteString(fmt.Sprintf("%s\n", sendData))
-		if err != nil {
-			fmt.Println("Error writing to buffer")
-			panic(err)
-		}
-		err = rw.Flush()
-		if err != nil {
-			fmt.Println("Error flushing buffer")
-			panic(err)
+		if err := nodehost.Connect(ctx, peer); err != nil {
+			logrus.WithFields(logrus.Fields{"peer": peer.ID, "error": err}).Debugln("failed to connect to peer.")
 		}
 	}
 }
@@ -0,0 +1,53 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/node"
+	"github.com/manishmeganathan/weave/protos"
+	"github.com/manishmeganathan/weave/wire"
+)
+
+func Test_NodeHostLifecycle(t *testing.T) {
+	chain, _ := testwalletchain(t)
+	host, err := NewNodeHost(wire.NewHandler(chain, nil, nil), HostConfig{ListenAddr: "/ip4/127.0.0.1/tcp/0"})
+	if err != nil {
+		t.Fatalf("NewNodeHost() failed! %v", err)
+	}
+
+	// Run the host as a service of a node
+	weavenode := node.NewNode()
+	weavenode.Register(host)
+	if err := weavenode.Start(context.Background()); err != nil {
+		t.Fatalf("Node.Start() failed! %v", err)
+	}
+
+	peerchain, _ := testwalletchain(t)
+	peer := testnodehost(t, peerchain, core.NewTxPool(peerchain, core.DefaultTxPoolSize))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The running host answers the queries of a peer
+	query := &protos.Query{Type: protos.Query_STATE, Body: &protos.Query_State{State: &protos.StateQuery{}}}
+	if err := peer.Host.Connect(ctx, host.AddrInfo()); err != nil {
+		t.Fatalf("Connect() failed! %v", err)
+	}
+	if _, err := peer.Query(ctx, host.Host.ID(), query); err != nil {
+		t.Fatalf("Query() failed! %v", err)
+	}
+
+	// Stopping the node closes the host and cancels its context
+	if err := weavenode.Stop(context.Background()); err != nil {
+		t.Fatalf("Node.Stop() failed! %v", err)
+	}
+	if host.Ctx.Err() == nil {
+		t.Fatalf("Node.Stop() failed! expected the host context to be cancelled")
+	}
+	if _, err := peer.Query(ctx, host.Host.ID(), query); err == nil {
+		t.Fatalf("Query() failed! expected an error for a stopped host")
+	}
+}
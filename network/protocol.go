@@ -0,0 +1,128 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/manishmeganathan/weave/protos"
+	"github.com/manishmeganathan/weave/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// Represents the ID of the weave stream protocol
+const weaveprotocol = protocol.ID(wire.ProtocolID)
+
+// Represents the time after which an idle weave protocol stream is closed
+const streamtimeout = 2 * time.Minute
+
+// A method of NodeHost that handles a weave protocol stream opened by a peer. Messages are
+// read from the stream in a loop and dispatched to the protocol handler of the node, and the
+// responses to queries are written back on the stream. The stream is reset when a message
+// cannot be read or handled, and closed when the peer closes it or it is idle.
+func (node *NodeHost) handleWeaveStream(stream network.Stream) {
+	// Retrieve the ID of the remote peer
+	remote := stream.Conn().RemotePeer().String()
+	reader := bufio.NewReader(stream)
+
+	for {
+		// Set the deadline for the next message
+		stream.SetDeadline(time.Now().Add(streamtimeout))
+
+		// Read a message from the stream
+		msg, err := wire.ReadMessage(reader)
+		if err != nil {
+			// Close the stream if the peer has closed it
+			if errors.Is(err, io.EOF) {
+				stream.Close()
+				return
+			}
+
+			logrus.WithFields(logrus.Fields{"peer": remote, "error": err}).Debugln("failed to read weave message.")
+			stream.Reset()
+			return
+		}
+
+		// Handle the message
+		response, err := node.Handler.HandleMessage(msg, remote)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"peer": remote, "type": msg.GetType(), "error": err}).Debugln("failed to handle weave message.")
+			stream.Reset()
+			return
+		}
+
+		// Write the response to the stream
+		if response != nil {
+			response.Peerid = node.Host.ID().String()
+			if err := wire.WriteMessage(stream, response); err != nil {
+				logrus.WithFields(logrus.Fields{"peer": remote, "error": err}).Debugln("failed to write weave message.")
+				stream.Reset()
+				return
+			}
+		}
+	}
+}
+
+// A method of NodeHost that sends a query to a peer on a new weave protocol stream and
+// returns the response of the peer. Returns an error if the peer fails to answer the query.
+func (node *NodeHost) Query(ctx context.Context, peerid peer.ID, query *protos.Query) (*protos.Response, error) {
+	// Open a weave protocol stream to the peer
+	stream, err := node.Host.NewStream(ctx, peerid, weaveprotocol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream to %v! error - %v", peerid, err)
+	}
+	defer stream.Close()
+
+	// Set the deadline of the query from the context
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetDeadline(deadline)
+	} else {
+		stream.SetDeadline(time.Now().Add(streamtimeout))
+	}
+
+	// Write the query to the stream
+	msg := &protos.Message{Type: protos.Message_QUERY, Peerid: node.Host.ID().String(), Message: &protos.Message_Query{Query: query}}
+	if err := wire.WriteMessage(stream, msg); err != nil {
+		stream.Reset()
+		return nil, err
+	}
+
+	// Read the response from the stream
+	response, err := wire.ReadMessage(bufio.NewReader(stream))
+	if err != nil {
+		stream.Reset()
+		return nil, fmt.Errorf("peer %v did not answer the query! error - %v", peerid, err)
+	}
+
+	// Check that the message is a response
+	if response.GetType() != protos.Message_RESPONSE || response.GetResponse() == nil {
+		return nil, fmt.Errorf("peer %v answered with a message of type %v", peerid, response.GetType())
+	}
+
+	return response.GetResponse(), nil
+}
+
+// A method of NodeHost that sends an entity to a peer on a new weave protocol stream
+func (node *NodeHost) SendEntity(ctx context.Context, peerid peer.ID, entity *protos.Entity) error {
+	// Open a weave protocol stream to the peer
+	stream, err := node.Host.NewStream(ctx, peerid, weaveprotocol)
+	if err != nil {
+		return fmt.Errorf("failed to open stream to %v! error - %v", peerid, err)
+	}
+	defer stream.Close()
+
+	// Write the entity to the stream
+	msg := &protos.Message{Type: protos.Message_ENTITY, Peerid: node.Host.ID().String(), Message: &protos.Message_Entity{Entity: entity}}
+	if err := wire.WriteMessage(stream, msg); err != nil {
+		stream.Reset()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/protos"
+	"github.com/manishmeganathan/weave/wallet"
+	"github.com/manishmeganathan/weave/wire"
+)
+
+// Represents the chain params used for tests (low difficulty for fast mining)
+var testparams = core.ChainParams{Difficulty: 8, Reward: 25}
+
+// A function that returns a new in-memory chain with a genesis reward for a wallet
+func testwalletchain(t *testing.T) (*core.BlockChain, *wallet.Wallet) {
+	w := wallet.NewWallet()
+	chain, err := core.NewBlockChainWithOptions(core.ChainOptions{InMemory: true, Params: testparams, Coinbase: *w.GenerateAddress(byte(0x00))})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	t.Cleanup(chain.CloseBuckets)
+	return chain, w
+}
+
+// A function that returns a host on the loopback interface that does not join the public network
+func testnodehost(t *testing.T, chain *core.BlockChain, txpool *core.TxPool) *NodeHost {
+	config := HostConfig{ListenAddr: "/ip4/127.0.0.1/tcp/0"}
+	node, err := NewNodeHost(wire.NewHandler(chain, txpool, nil), config)
+	if err != nil {
+		t.Fatalf("NewNodeHost() failed! %v", err)
+	}
+
+	t.Cleanup(func() { node.Stop(context.Background()) })
+	return node
+}
+
+func Test_ProtocolStreams(t *testing.T) {
+	chain, w := testwalletchain(t)
+	txpool := core.NewTxPool(chain, core.DefaultTxPoolSize)
+	remote := testnodehost(t, chain, txpool)
+
+	peerchain, _ := testwalletchain(t)
+	local := testnodehost(t, peerchain, core.NewTxPool(peerchain, core.DefaultTxPoolSize))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Connect the hosts directly
+	if err := local.Host.Connect(ctx, remote.AddrInfo()); err != nil {
+		t.Fatalf("Connect() failed! %v", err)
+	}
+
+	// A query is answered by the remote host from its chain
+	query := &protos.Query{Type: protos.Query_STATE, Body: &protos.Query_State{State: &protos.StateQuery{}}}
+	response, err := local.Query(ctx, remote.Host.ID(), query)
+	if err != nil {
+		t.Fatalf("Query() failed! %v", err)
+	}
+	if state := response.GetStateresponse(); state == nil || int(state.GetChainheight()) != chain.ChainHeight {
+		t.Fatalf("Query() failed! expected: chain height %v, got: %v", chain.ChainHeight, response)
+	}
+
+	// A transaction entity sent to the remote host is added to its pool
+	genesis, _ := chain.GetBlock(chain.ChainHead)
+	address := *w.GenerateAddress(byte(0x00))
+	txn := &core.Transaction{Inputs: core.TXIList{{ID: genesis.TXList[0].ID, OutIndex: 0, PublicKey: w.PublicKey}}}
+	txn.Outputs = core.TXOList{*core.NewTXO(20, address)}
+	if err := txn.Sign(w.PrivateKey, core.TXOList{*core.NewTXO(0, address)}); err != nil {
+		t.Fatalf("Sign() failed! %v", err)
+	}
+
+	if err := local.SendEntity(ctx, remote.Host.ID(), wire.TxnEntity(txn)); err != nil {
+		t.Fatalf("SendEntity() failed! %v", err)
+	}
+
+	// The entity is handled asynchronously by the stream handler of the remote host
+	for txpool.Count() != 1 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("SendEntity() failed! expected the transaction in the remote pool")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
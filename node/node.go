@@ -0,0 +1,179 @@
+/*
+This module contains the lifecycle manager of a Weave node that
+owns the start and stop of its services (chain, mempool, network)
+and shuts them down gracefully in order when the node is stopped.
+*/
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Represents the default time allowed for the services of a node to stop
+const DefaultStopTimeout = 30 * time.Second
+
+// An interface that defines a service that is managed by a Node
+type Service interface {
+	// A method that returns the name of the service
+	Name() string
+
+	// A method that starts the service. The context is cancelled when
+	// the node is stopped and should be used to end background work.
+	Start(ctx context.Context) error
+
+	// A method that stops the service and releases its resources.
+	// The context carries the deadline for the service to stop.
+	Stop(ctx context.Context) error
+}
+
+// A structure that represents the lifecycle manager of a node.
+// Services are started in the order that they are registered and stopped
+// in the reverse order, so that a service is stopped before the services
+// that it depends on (such as the network before the chain databases).
+type Node struct {
+	// Represents the registered services of the node
+	services []Service
+
+	// Represents the services that have been started
+	started []Service
+
+	// Represents the cancel function of the context of the running services
+	cancel context.CancelFunc
+
+	// Represents the synchronization lock for the node
+	mutex sync.Mutex
+}
+
+// A constructor function that generates and returns a new Node with no services
+func NewNode() *Node {
+	return &Node{}
+}
+
+// A method of Node that registers a service with the node.
+// Services must be registered before the node is started.
+func (node *Node) Register(service Service) {
+	// Acquire the lock on the node
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+
+	// Add the service to the node
+	node.services = append(node.services, service)
+}
+
+// A method of Node that starts all the registered services in order. If a service
+// fails to start, the services that were started are stopped in reverse order and
+// the error is returned. The services are given a context that is derived from the
+// given context and that is cancelled when the node is stopped.
+func (node *Node) Start(ctx context.Context) error {
+	// Acquire the lock on the node
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+
+	// Check if the node is already running
+	if node.cancel != nil {
+		return errors.New("node is already running")
+	}
+
+	// Create the context of the running services
+	runctx, cancel := context.WithCancel(ctx)
+	node.cancel = cancel
+
+	// Iterate over the registered services
+	for _, service := range node.services {
+		// Start the service
+		if err := service.Start(runctx); err != nil {
+			// Stop the services that have been started
+			stopctx, stopcancel := context.WithTimeout(context.Background(), DefaultStopTimeout)
+			defer stopcancel()
+			node.stop(stopctx)
+
+			return fmt.Errorf("failed to start %v service! error - %v", service.Name(), err)
+		}
+
+		// Log the start of the service
+		logrus.WithFields(logrus.Fields{"service": service.Name()}).Info("service has started.")
+		node.started = append(node.started, service)
+	}
+
+	// Return a nil error
+	return nil
+}
+
+// A method of Node that stops all the started services in reverse order.
+// The context of the running services is cancelled first. Every service is
+// stopped even if some of them fail, and the errors are joined and returned.
+func (node *Node) Stop(ctx context.Context) error {
+	// Acquire the lock on the node
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+
+	return node.stop(ctx)
+}
+
+// A method of Node that stops the started services. Expects the lock to be held.
+func (node *Node) stop(ctx context.Context) error {
+	// Check if the node is running
+	if node.cancel == nil {
+		return nil
+	}
+
+	// Cancel the context of the running services
+	node.cancel()
+	node.cancel = nil
+
+	// Iterate over the started services in reverse order
+	var errs []error
+	for index := len(node.started) - 1; index >= 0; index-- {
+		service := node.started[index]
+
+		// Stop the service
+		if err := service.Stop(ctx); err != nil {
+			logrus.WithFields(logrus.Fields{"service": service.Name(), "error": err}).Error("service failed to stop.")
+			errs = append(errs, fmt.Errorf("failed to stop %v service! error - %w", service.Name(), err))
+			continue
+		}
+
+		// Log the stop of the service
+		logrus.WithFields(logrus.Fields{"service": service.Name()}).Info("service has stopped.")
+	}
+
+	// Reset the started services
+	node.started = nil
+	// Return the errors
+	return errors.Join(errs...)
+}
+
+// A method of Node that starts the node and blocks until the given context is
+// cancelled, after which the node is stopped within the given stop timeout.
+// Use SignalContext to run the node until the process is interrupted.
+func (node *Node) Run(ctx context.Context, timeout time.Duration) error {
+	// Start the node
+	if err := node.Start(ctx); err != nil {
+		return err
+	}
+
+	// Wait for the context to be cancelled
+	<-ctx.Done()
+	logrus.Info("node is shutting down.")
+
+	// Stop the node within the timeout
+	stopctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return node.Stop(stopctx)
+}
+
+// A function that returns a context derived from the given context
+// that is cancelled when the process receives an interrupt or a
+// termination signal, along with a function to release the signals.
+func SignalContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
@@ -0,0 +1,133 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+// A function that returns a service that records its start and stop into a log
+func testservice(name string, log *[]string, starterr error) Service {
+	return NewService(name,
+		func(ctx context.Context) error {
+			if starterr != nil {
+				return starterr
+			}
+
+			*log = append(*log, "start "+name)
+			return nil
+		},
+		func(ctx context.Context) error {
+			*log = append(*log, "stop "+name)
+			return nil
+		})
+}
+
+func Test_NodeLifecycle(t *testing.T) {
+	log := []string{}
+	node := NewNode()
+	node.Register(testservice("chain", &log, nil))
+	node.Register(testservice("mempool", &log, nil))
+	node.Register(testservice("network", &log, nil))
+
+	if err := node.Start(context.Background()); err != nil {
+		t.Fatalf("Node.Start() failed! %v", err)
+	}
+	if err := node.Start(context.Background()); err == nil {
+		t.Fatalf("Node.Start() failed! expected an error for a running node")
+	}
+	if err := node.Stop(context.Background()); err != nil {
+		t.Fatalf("Node.Stop() failed! %v", err)
+	}
+
+	expected := []string{"start chain", "start mempool", "start network", "stop network", "stop mempool", "stop chain"}
+	if !reflect.DeepEqual(log, expected) {
+		t.Fatalf("Node lifecycle failed! expected: %v, got: %v", expected, log)
+	}
+
+	// Stopping a stopped node does nothing
+	if err := node.Stop(context.Background()); err != nil || len(log) != len(expected) {
+		t.Fatalf("Node.Stop() repeat failed! %v", err)
+	}
+}
+
+func Test_NodeStartFailure(t *testing.T) {
+	log := []string{}
+	node := NewNode()
+	node.Register(testservice("chain", &log, nil))
+	node.Register(testservice("network", &log, errors.New("no peers")))
+	node.Register(testservice("miner", &log, nil))
+
+	if err := node.Start(context.Background()); err == nil {
+		t.Fatalf("Node.Start() failed! expected an error")
+	}
+
+	expected := []string{"start chain", "stop chain"}
+	if !reflect.DeepEqual(log, expected) {
+		t.Fatalf("Node.Start() rollback failed! expected: %v, got: %v", expected, log)
+	}
+}
+
+func Test_NodeRun(t *testing.T) {
+	var servicectx context.Context
+	stopped := false
+
+	node := NewNode()
+	node.Register(NewService("worker",
+		func(ctx context.Context) error { servicectx = ctx; return nil },
+		func(ctx context.Context) error { stopped = true; return nil }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	if err := node.Run(ctx, time.Second); err != nil {
+		t.Fatalf("Node.Run() failed! %v", err)
+	}
+	if !stopped || servicectx.Err() == nil {
+		t.Fatalf("Node.Run() failed! expected the service to be stopped and its context cancelled")
+	}
+}
+
+func Test_TxPoolService(t *testing.T) {
+	// Create an in-memory chain and an empty pool
+	address := *wallet.NewWallet().GenerateAddress(byte(0x00))
+	chain, err := core.NewBlockChainWithOptions(core.ChainOptions{InMemory: true, Params: core.ChainParams{Difficulty: 8, Reward: 25}, Coinbase: address})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+	defer chain.CloseBuckets()
+
+	path := filepath.Join(t.TempDir(), "mempool.data")
+	service := NewTxPoolService(core.NewTxPool(chain, core.DefaultTxPoolSize), path, time.Millisecond)
+
+	// Starting without a saved pool succeeds
+	if err := service.Start(context.Background()); err != nil {
+		t.Fatalf("Start() failed! %v", err)
+	}
+
+	// Stopping the service saves the pool
+	if err := service.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() failed! %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Stop() failed! expected the pool to be saved, got: %v", err)
+	}
+
+	// The service can be restarted and stopped again
+	if err := service.Start(context.Background()); err != nil {
+		t.Fatalf("Start() restart failed! %v", err)
+	}
+	if err := service.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() restart failed! %v", err)
+	}
+}
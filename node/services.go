@@ -0,0 +1,122 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/sirupsen/logrus"
+)
+
+// A structure that represents a Service built from a set of functions
+type funcservice struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+// A constructor function that generates and returns a Service with a given
+// name and start and stop functions. A nil function does nothing.
+func NewService(name string, start, stop func(ctx context.Context) error) Service {
+	return &funcservice{name: name, start: start, stop: stop}
+}
+
+// A method of funcservice that returns the name of the service
+func (service *funcservice) Name() string {
+	return service.name
+}
+
+// A method of funcservice that starts the service
+func (service *funcservice) Start(ctx context.Context) error {
+	if service.start == nil {
+		return nil
+	}
+
+	return service.start(ctx)
+}
+
+// A method of funcservice that stops the service
+func (service *funcservice) Stop(ctx context.Context) error {
+	if service.stop == nil {
+		return nil
+	}
+
+	return service.stop(ctx)
+}
+
+// A constructor function that generates and returns a Service for an opened
+// BlockChain. The chain is opened by its constructor and the service closes the
+// database buckets of the chain when it is stopped. The chain service should be
+// registered first, so that it is stopped after the services that use the chain.
+func NewChainService(chain *core.BlockChain) Service {
+	return NewService("chain", nil, func(ctx context.Context) error {
+		// Close the database buckets of the chain
+		chain.CloseBuckets()
+		return nil
+	})
+}
+
+// A constructor function that generates and returns a Service for a TxPool that
+// is persisted to a file. The saved transactions are loaded and revalidated against
+// the chain when the service is started. The pool is saved at every interval (if
+// positive) and when the service is stopped. The service should be registered after
+// the chain service, so that the pool is saved before the chain is closed.
+func NewTxPoolService(txpool *core.TxPool, path string, interval time.Duration) Service {
+	// Declare the channels for stopping the periodic save of each run of the service
+	var stop, done chan struct{}
+
+	start := func(ctx context.Context) error {
+		// Create the channels for stopping the periodic save (a restarted
+		// service cannot reuse the channels closed by its previous stop)
+		stop, done = make(chan struct{}), make(chan struct{})
+
+		// Load the saved transactions into the pool
+		added, dropped, err := txpool.LoadFile(path)
+		if err != nil {
+			// Log the error and start with an empty pool
+			logrus.WithFields(logrus.Fields{"error": err}).Warnln("failed to load saved transaction pool.")
+		} else {
+			logrus.WithFields(logrus.Fields{"added": added, "dropped": dropped}).Infoln("loaded saved transaction pool.")
+		}
+
+		// Start a goroutine that saves the pool at every interval
+		go func(stop, done chan struct{}) {
+			defer close(done)
+
+			// Check if the pool should be saved periodically
+			if interval <= 0 {
+				<-stop
+				return
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					// Save the pool to the file
+					if err := txpool.SaveFile(path); err != nil {
+						logrus.WithFields(logrus.Fields{"error": err}).Warnln("failed to save transaction pool.")
+					}
+				}
+			}
+		}(stop, done)
+
+		return nil
+	}
+
+	return NewService("txpool", start, func(ctx context.Context) error {
+		// Stop the periodic save and wait for it to exit (if the service was started)
+		if stop != nil {
+			close(stop)
+			<-done
+			stop, done = nil, nil
+		}
+
+		// Save the pool to the file
+		return txpool.SaveFile(path)
+	})
+}
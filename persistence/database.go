@@ -4,13 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"runtime"
-	"syscall"
+	"path/filepath"
+	"sync"
+	"time"
 
-	"github.com/dgraph-io/badger"
+	"github.com/dgraph-io/badger/v4"
 	"github.com/manishmeganathan/weave/utils"
 	"github.com/sirupsen/logrus"
-	"github.com/vrecan/death/v3"
 )
 
 // A type alias that represents a type of database bucket
@@ -18,11 +18,14 @@ type Bucket string
 
 // A set of constants that represent valids types of database buckets
 const (
-	STATE  Bucket = "state"
-	BLOCKS Bucket = "blocks"
+	STATE   Bucket = "state"
+	BLOCKS  Bucket = "blocks"
+	HEADERS Bucket = "headers"
+	INDEX   Bucket = "index"
 )
 
-// A struct that represents the client for a database bucket
+// A struct that represents the client for a database bucket.
+// A DatabaseBucket is the Badger implementation of a Store.
 type DatabaseBucket struct {
 	// Represents the BadgerDB client for the bucket
 	Client *badger.DB
@@ -30,6 +33,11 @@ type DatabaseBucket struct {
 	Bucket Bucket
 	// Represents the whether the client is open
 	IsOpen bool
+
+	// Represents the stop channel of the maintenance loop
+	stop chan struct{}
+	// Represents the wait group of the maintenance loop
+	maintenance sync.WaitGroup
 }
 
 // A function to check if the database exists locally.
@@ -43,6 +51,15 @@ func CheckDatabase() bool {
 	// Get the Config data
 	config := utils.ReadConfigFile()
 
+	// Upgrade the buckets from the legacy Badger format (or complete an interrupted upgrade)
+	_, indexdir := bucketlocation(config.DB.Root, INDEX, config.DB.Index.File, config.DB.Index.Directory)
+	for _, directory := range []string{config.DB.State.Directory, config.DB.Blocks.Directory, indexdir} {
+		if err := upgradebucket(directory); err != nil {
+			// Log a fatal error
+			logrus.WithFields(logrus.Fields{"error": err, "bucket": directory}).Fatalln("failed to upgrade database bucket.")
+		}
+	}
+
 	// Retrieve the file status for the database manifest files for the buckets
 	_, err_state := os.Stat(config.DB.State.File)
 	_, err_blocks := os.Stat(config.DB.Blocks.File)
@@ -56,6 +73,8 @@ func CheckDatabase() bool {
 		utils.CreateDirectory(config.DB.State.Directory)
 		// Create an empty blocks db directory if it does not exist
 		utils.CreateDirectory(config.DB.Blocks.Directory)
+		// Create an empty index db directory if it does not exist
+		utils.CreateDirectory(indexdir)
 
 		// Return false because some file does not exist
 		return false
@@ -65,10 +84,53 @@ func CheckDatabase() bool {
 	return true
 }
 
+// A function to check if the headers database exists locally.
+// Checks if the headers bucket exists by confirming the existence
+// of its MANIFEST file. If it does not exist, the directory for
+// the bucket is created. Used by light nodes that only store headers.
+func CheckHeaderDatabase() bool {
+	// Get the Config data
+	config := utils.ReadConfigFile()
+	// Get the location of the headers bucket
+	file, directory := bucketlocation(config.DB.Root, HEADERS, config.DB.Headers.File, config.DB.Headers.Directory)
+
+	// Upgrade the headers bucket from the legacy Badger format
+	if err := upgradebucket(directory); err != nil {
+		// Log a fatal error
+		logrus.WithFields(logrus.Fields{"error": err, "bucket": directory}).Fatalln("failed to upgrade database bucket.")
+	}
+
+	// Check if the headers bucket does not exist
+	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+		// Create an empty headers db directory if it does not exist
+		utils.CreateDirectory(directory)
+
+		// Return false because the file does not exist
+		return false
+	}
+
+	// Return true because the bucket file exists
+	return true
+}
+
+// A function that returns the manifest file and directory of a bucket given its configured
+// location. Config files generated before a bucket was added have no configuration for
+// it, so the location of an unconfigured bucket is derived from the db root directory.
+func bucketlocation(root string, bucket Bucket, file, directory string) (string, string) {
+	// Check if the bucket is configured
+	if directory != "" {
+		return file, directory
+	}
+
+	// Derive the bucket location from the db root
+	directory = filepath.Join(root, string(bucket))
+	return filepath.Join(directory, "MANIFEST"), directory
+}
+
 // A constructor function that generates and returns
 // a new Database bucket object that has been opened
 // The bucket argument is the type of bucket to open
-// Valid options are the STATE and BLOCKS constants.
+// Valid options are the STATE, BLOCKS, HEADERS and INDEX constants.
 func NewDatabaseBucket(bucket Bucket) *DatabaseBucket {
 	// Get the Config data
 	config := utils.ReadConfigFile()
@@ -87,6 +149,18 @@ func NewDatabaseBucket(bucket Bucket) *DatabaseBucket {
 		// Set the Badger DB options for the blocks bucket
 		opts = badger.DefaultOptions(config.DB.Blocks.Directory)
 
+	// The chain headers bucket
+	case HEADERS:
+		// Set the Badger DB options for the headers bucket
+		_, directory := bucketlocation(config.DB.Root, HEADERS, config.DB.Headers.File, config.DB.Headers.Directory)
+		opts = badger.DefaultOptions(directory)
+
+	// The chain index bucket
+	case INDEX:
+		// Set the Badger DB options for the index bucket
+		_, directory := bucketlocation(config.DB.Root, INDEX, config.DB.Index.File, config.DB.Index.Directory)
+		opts = badger.DefaultOptions(directory)
+
 	// Invalid type
 	default:
 		// Log the fatal error
@@ -101,13 +175,41 @@ func NewDatabaseBucket(bucket Bucket) *DatabaseBucket {
 	// Open the database
 	db.Open(opts)
 
-	// Setup database to close at application death
-	go db.safedeath()
+	// Start the maintenance loop of the bucket
+	db.StartMaintenance(config.DB.GCRatio, time.Duration(config.DB.GCInterval)*time.Minute)
 
 	// Return the database
 	return db
 }
 
+// A constructor function that generates and returns a new Database bucket object
+// that has been opened in a given directory. Unlike NewDatabaseBucket, the location
+// of the bucket is not read from the config file and failures are returned as errors.
+func OpenBadgerStore(bucket Bucket, directory string) (*DatabaseBucket, error) {
+	// Create the bucket directory if it does not exist
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database bucket directory! error - %v", err)
+	}
+
+	// Upgrade the bucket from the legacy Badger format
+	if err := upgradebucket(directory); err != nil {
+		return nil, err
+	}
+
+	// Set the Badger DB options for the bucket and switch off the Badger Logger
+	opts := badger.DefaultOptions(directory)
+	opts.Logger = nil
+
+	// Open the Badger DB bucket with the options
+	client, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database bucket! error - %v", err)
+	}
+
+	// Return the database bucket
+	return &DatabaseBucket{Client: client, Bucket: bucket, IsOpen: true}, nil
+}
+
 // A method of DatabaseBucket that opens the BadgerDB
 // client for the db bucket with the given badger DB options
 func (db *DatabaseBucket) Open(opts badger.Options) {
@@ -127,8 +229,17 @@ func (db *DatabaseBucket) Open(opts badger.Options) {
 	logrus.Infof("database %v bucket client has been opened\n", db.Bucket)
 }
 
-// A method of DatabaseBucket that closes the BadgerDB client for the bucket
+// A method of DatabaseBucket that closes the BadgerDB client for the bucket.
+// Does nothing if the bucket is not open.
 func (db *DatabaseBucket) Close() {
+	// Check if the bucket is open
+	if !db.IsOpen {
+		return
+	}
+
+	// Stop the maintenance loop of the bucket
+	db.StopMaintenance()
+
 	// log the closing of the database
 	logrus.Infof("database %v bucket client has been closed\n", db.Bucket)
 	// Close the client
@@ -140,25 +251,6 @@ func (db *DatabaseBucket) Close() {
 	db.IsOpen = false
 }
 
-// A method of DatabaseBucket that closes the connection of the
-// BadgerDB client for the bucket when the runtime closes abruptly
-func (db *DatabaseBucket) safedeath() {
-	// Setup death signals
-	demise := death.NewDeath(syscall.SIGINT, syscall.SIGTERM, os.Interrupt, os.Kill)
-
-	// Lambda function that executes when a death signal is triggered
-	demise.WaitForDeathWithFunc(func() {
-		defer os.Exit(1)
-		defer runtime.Goexit()
-
-		// Log the death of the database client
-		logrus.Infoln("connection to database has terminated due to application death")
-
-		// Close the database client
-		db.Close()
-	})
-}
-
 // A method of DatabaseBucket that retrieves the value for
 // a given key from the BadgerDB client for the bucket
 func (db *DatabaseBucket) GetKey(key []byte) ([]byte, error) {
@@ -167,48 +259,137 @@ func (db *DatabaseBucket) GetKey(key []byte) ([]byte, error) {
 
 	// Define a view transaction on the database bucket
 	err := db.Client.View(func(txn *badger.Txn) error {
-		// Get the item with the key from the DB
-		item, err := txn.Get(key)
-		// Return any potential error
-		if err != nil {
-			return fmt.Errorf("failed to GET database item! error - %v", err)
-		}
+		// Get the value of the key from the transaction
+		var err error
+		value, err = (&badgertxn{txn: txn}).GetKey(key)
+		return err
+	})
 
-		// Retrieve the value of the item
-		if err = item.Value(func(val []byte) error {
-			// Set the value
-			value = val
-			return nil
+	// Return the value and any error generated
+	return value, err
+}
+
+// A method of DatabaseBucket that calls a function for every key-value
+// pair with a given prefix from the BadgerDB client for the bucket
+func (db *DatabaseBucket) IteratePrefix(prefix []byte, fn func(key, value []byte) error) error {
+	// Define a view transaction on the database bucket
+	return db.Client.View(func(txn *badger.Txn) error {
+		// Iterate over the keys with the prefix on the transaction
+		return (&badgertxn{txn: txn}).IteratePrefix(prefix, fn)
+	})
+}
 
-			// Return any potential error
+// A method of DatabaseBucket that calls a function with a batch that is backed by
+// an update transaction on the BadgerDB client. The transaction is committed if the
+// function returns a nil error, otherwise it is discarded with all its writes.
+func (db *DatabaseBucket) Batch(fn func(batch Batch) error) error {
+	// Define an update transaction on the database bucket
+	return db.Client.Update(func(txn *badger.Txn) error {
+		return fn(&badgertxn{txn: txn})
+	})
+}
+
+// A method of DatabaseBucket that returns a snapshot that
+// is backed by a read-only transaction on the BadgerDB client
+func (db *DatabaseBucket) Snapshot() Snapshot {
+	return &badgertxn{txn: db.Client.NewTransaction(false)}
+}
+
+// A structure that represents a transaction on the BadgerDB client of a bucket.
+// Used as a Batch for update transactions and as a Snapshot for view transactions.
+type badgertxn struct {
+	// Represents the BadgerDB transaction
+	txn *badger.Txn
+}
+
+// A method of badgertxn that retrieves the value for a given key on the transaction
+func (bt *badgertxn) GetKey(key []byte) ([]byte, error) {
+	// Get the item with the key from the transaction
+	item, err := bt.txn.Get(key)
+	// Return any potential error
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, fmt.Errorf("failed to GET database item! error - %w", ErrKeyNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to GET database item! error - %v", err)
+	}
+
+	// Retrieve a copy of the value of the item (the value is only valid during the transaction)
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GET database value! error - %v", err)
+	}
+
+	// Return the value
+	return value, nil
+}
+
+// A method of badgertxn that sets the value for a given key on the transaction
+func (bt *badgertxn) SetKey(key, value []byte) error {
+	// Add the key-value pair to the transaction
+	if err := bt.txn.Set(key, value); err != nil {
+		return fmt.Errorf("failed to SET database key! error - %v", err)
+	}
+
+	// Return the nil error
+	return nil
+}
+
+// A method of badgertxn that deletes the entry for a given key on the transaction
+func (bt *badgertxn) DeleteKey(key []byte) error {
+	// Delete the key from the transaction
+	if err := bt.txn.Delete(key); err != nil {
+		return fmt.Errorf("failed to DELETE database key! error - %v", err)
+	}
+
+	// Return the nil error
+	return nil
+}
+
+// A method of badgertxn that calls a function for every key-value pair with a given prefix
+func (bt *badgertxn) IteratePrefix(prefix []byte, fn func(key, value []byte) error) error {
+	// Start a database iterator with the default options
+	dbiterator := bt.txn.NewIterator(badger.DefaultIteratorOptions)
+	// Defer the closing of the iterator
+	defer dbiterator.Close()
+
+	// Iterate over the database items with the prefix
+	for dbiterator.Seek(prefix); dbiterator.ValidForPrefix(prefix); dbiterator.Next() {
+		// Retrieve the item from the iterator
+		item := dbiterator.Item()
+
+		// Call the function with the key and value of the item
+		if err := item.Value(func(val []byte) error {
+			return fn(item.Key(), val)
 		}); err != nil {
-			return fmt.Errorf("failed to GET database value! error - %v", err)
+			return err
 		}
+	}
 
-		// Return the nil error
-		return nil
-	})
+	// Return the nil error
+	return nil
+}
 
-	// Return the value and any error generated
-	return value, err
+// A method of badgertxn that discards the transaction
+func (bt *badgertxn) Discard() {
+	bt.txn.Discard()
 }
 
 // A method of DatabaseBucket that sets the value for a given key-value pair
 func (db *DatabaseBucket) SetKey(key, value []byte) error {
 	// Define an update transaction on the database bucket
-	err := db.Client.Update(func(txn *badger.Txn) error {
-		// Add the key-value pair to the database
-		if err := txn.Set(key, value); err != nil {
-			// Return any potential error
-			return fmt.Errorf("failed to SET database key! error - %v", err)
-		}
-
-		// Return the nil error
-		return nil
+	return db.Client.Update(func(txn *badger.Txn) error {
+		// Add the key-value pair to the transaction
+		return (&badgertxn{txn: txn}).SetKey(key, value)
 	})
+}
 
-	// Return any error generated
-	return err
+// A method of DatabaseBucket that deletes the entry for a given key
+func (db *DatabaseBucket) DeleteKey(key []byte) error {
+	// Define an update transaction on the database bucket
+	return db.Client.Update(func(txn *badger.Txn) error {
+		// Delete the key from the transaction
+		return (&badgertxn{txn: txn}).DeleteKey(key)
+	})
 }
 
 // A method of DatabaseBucket that deletes all entries
@@ -0,0 +1,303 @@
+package persistence
+
+import (
+	"container/heap"
+	"container/list"
+	"time"
+)
+
+// An interface that represents the eviction policy of a memory pool.
+// The pool notifies the policy of every change while it holds its lock,
+// so a policy is never called concurrently and needs no lock of its own.
+type EvictionPolicy[K comparable, V any] interface {
+	// Called when an object is added to the pool or replaced
+	Added(key K, object V)
+	// Called when an object is read from the pool
+	Accessed(key K)
+	// Called when an object is removed from the pool
+	Removed(key K)
+	// Called when every object is removed from the pool
+	Reset()
+
+	// Returns the key of the object that should be evicted next.
+	// Returns false if the policy never evicts objects.
+	Victim() (K, bool)
+	// Returns whether an object should be admitted into a full pool by evicting the victim
+	Admit(object V) bool
+	// Returns the keys of the objects that have expired
+	Expired() []K
+}
+
+// A struct that represents an eviction policy which never evicts objects.
+// A full pool rejects new objects, which is the default of a memory pool.
+type rejectpolicy[K comparable, V any] struct{}
+
+func (rejectpolicy[K, V]) Added(K, V)   {}
+func (rejectpolicy[K, V]) Accessed(K)   {}
+func (rejectpolicy[K, V]) Removed(K)    {}
+func (rejectpolicy[K, V]) Reset()       {}
+func (rejectpolicy[K, V]) Admit(V) bool { return false }
+func (rejectpolicy[K, V]) Expired() []K { return nil }
+
+func (rejectpolicy[K, V]) Victim() (K, bool) {
+	var key K
+	return key, false
+}
+
+// A struct that represents an eviction policy which evicts the least recently used object
+type LRUPolicy[K comparable, V any] struct {
+	// Represents the keys of the pool from the most to the least recently used
+	order *list.List
+	// Represents the elements of the order for each key
+	elements map[K]*list.Element
+}
+
+// A constructor function that creates a new least recently used eviction policy
+func NewLRUPolicy[K comparable, V any]() *LRUPolicy[K, V] {
+	return &LRUPolicy[K, V]{order: list.New(), elements: make(map[K]*list.Element)}
+}
+
+// A method of LRUPolicy that marks an added object as the most recently used
+func (policy *LRUPolicy[K, V]) Added(key K, object V) {
+	policy.Accessed(key)
+}
+
+// A method of LRUPolicy that marks an accessed object as the most recently used
+func (policy *LRUPolicy[K, V]) Accessed(key K) {
+	// Move the key to the front if it is already tracked
+	if element, ok := policy.elements[key]; ok {
+		policy.order.MoveToFront(element)
+		return
+	}
+
+	// Track the key at the front
+	policy.elements[key] = policy.order.PushFront(key)
+}
+
+// A method of LRUPolicy that stops tracking a removed object
+func (policy *LRUPolicy[K, V]) Removed(key K) {
+	if element, ok := policy.elements[key]; ok {
+		policy.order.Remove(element)
+		delete(policy.elements, key)
+	}
+}
+
+// A method of LRUPolicy that stops tracking every object
+func (policy *LRUPolicy[K, V]) Reset() {
+	policy.order.Init()
+	policy.elements = make(map[K]*list.Element)
+}
+
+// A method of LRUPolicy that returns the least recently used object as the victim
+func (policy *LRUPolicy[K, V]) Victim() (K, bool) {
+	// Check if there are any tracked keys
+	back := policy.order.Back()
+	if back == nil {
+		var key K
+		return key, false
+	}
+
+	return back.Value.(K), true
+}
+
+// A method of LRUPolicy that admits every object
+func (policy *LRUPolicy[K, V]) Admit(object V) bool { return true }
+
+// A method of LRUPolicy that never expires objects
+func (policy *LRUPolicy[K, V]) Expired() []K { return nil }
+
+// A struct that represents an object tracked by a PriorityPolicy
+type priorityitem[K comparable] struct {
+	// Represents the key of the object
+	key K
+	// Represents the priority of the object
+	priority int64
+	// Represents the index of the item in the queue
+	index int
+}
+
+// A type that represents a min-heap of priority items that implements heap.Interface
+type priorityqueue[K comparable] []*priorityitem[K]
+
+func (queue priorityqueue[K]) Len() int           { return len(queue) }
+func (queue priorityqueue[K]) Less(i, j int) bool { return queue[i].priority < queue[j].priority }
+
+func (queue priorityqueue[K]) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+	queue[i].index = i
+	queue[j].index = j
+}
+
+func (queue *priorityqueue[K]) Push(x any) {
+	item := x.(*priorityitem[K])
+	item.index = len(*queue)
+	*queue = append(*queue, item)
+}
+
+func (queue *priorityqueue[K]) Pop() any {
+	old := *queue
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*queue = old[:len(old)-1]
+	return item
+}
+
+// A struct that represents an eviction policy which evicts the object with the lowest priority.
+// The priority of an object is supplied by the caller and is computed when the object is added.
+type PriorityPolicy[K comparable, V any] struct {
+	// Represents the function that computes the priority of an object
+	priority func(object V) int64
+	// Represents the queue of tracked objects with the lowest priority first
+	queue priorityqueue[K]
+	// Represents the items of the queue for each key
+	items map[K]*priorityitem[K]
+}
+
+// A constructor function that creates a new lowest priority first eviction policy
+// that computes the priority of each object with the given function
+func NewPriorityPolicy[K comparable, V any](priority func(object V) int64) *PriorityPolicy[K, V] {
+	return &PriorityPolicy[K, V]{priority: priority, items: make(map[K]*priorityitem[K])}
+}
+
+// A method of PriorityPolicy that tracks the priority of an added object
+func (policy *PriorityPolicy[K, V]) Added(key K, object V) {
+	// Update the priority if the key is already tracked
+	if item, ok := policy.items[key]; ok {
+		item.priority = policy.priority(object)
+		heap.Fix(&policy.queue, item.index)
+		return
+	}
+
+	// Track the key with its priority
+	item := &priorityitem[K]{key: key, priority: policy.priority(object)}
+	heap.Push(&policy.queue, item)
+	policy.items[key] = item
+}
+
+// A method of PriorityPolicy that ignores accesses
+func (policy *PriorityPolicy[K, V]) Accessed(key K) {}
+
+// A method of PriorityPolicy that stops tracking a removed object
+func (policy *PriorityPolicy[K, V]) Removed(key K) {
+	if item, ok := policy.items[key]; ok {
+		heap.Remove(&policy.queue, item.index)
+		delete(policy.items, key)
+	}
+}
+
+// A method of PriorityPolicy that stops tracking every object
+func (policy *PriorityPolicy[K, V]) Reset() {
+	policy.queue = nil
+	policy.items = make(map[K]*priorityitem[K])
+}
+
+// A method of PriorityPolicy that returns the object with the lowest priority as the victim
+func (policy *PriorityPolicy[K, V]) Victim() (K, bool) {
+	// Check if there are any tracked keys
+	if len(policy.queue) == 0 {
+		var key K
+		return key, false
+	}
+
+	return policy.queue[0].key, true
+}
+
+// A method of PriorityPolicy that admits an object only if
+// its priority is higher than that of the lowest priority object
+func (policy *PriorityPolicy[K, V]) Admit(object V) bool {
+	// Check if there are any tracked keys
+	if len(policy.queue) == 0 {
+		return true
+	}
+
+	return policy.priority(object) > policy.queue[0].priority
+}
+
+// A method of PriorityPolicy that never expires objects
+func (policy *PriorityPolicy[K, V]) Expired() []K { return nil }
+
+// A struct that represents an object tracked by a TTLPolicy
+type ttlentry[K comparable] struct {
+	// Represents the key of the object
+	key K
+	// Represents the time at which the object was added
+	added time.Time
+}
+
+// A struct that represents an eviction policy which expires objects after a time to live.
+// A full pool evicts the oldest object. Replacing an object restarts its time to live.
+type TTLPolicy[K comparable, V any] struct {
+	// Represents the time to live of each object
+	ttl time.Duration
+	// Represents the function that returns the current time
+	now func() time.Time
+
+	// Represents the keys of the pool from the newest to the oldest
+	order *list.List
+	// Represents the elements of the order for each key
+	elements map[K]*list.Element
+}
+
+// A constructor function that creates a new eviction policy with the given time to live
+func NewTTLPolicy[K comparable, V any](ttl time.Duration) *TTLPolicy[K, V] {
+	return &TTLPolicy[K, V]{ttl: ttl, now: time.Now, order: list.New(), elements: make(map[K]*list.Element)}
+}
+
+// A method of TTLPolicy that starts the time to live of an added object
+func (policy *TTLPolicy[K, V]) Added(key K, object V) {
+	// Stop tracking the key if it is already tracked
+	policy.Removed(key)
+	// Track the key at the front with the current time
+	policy.elements[key] = policy.order.PushFront(&ttlentry[K]{key: key, added: policy.now()})
+}
+
+// A method of TTLPolicy that ignores accesses
+func (policy *TTLPolicy[K, V]) Accessed(key K) {}
+
+// A method of TTLPolicy that stops tracking a removed object
+func (policy *TTLPolicy[K, V]) Removed(key K) {
+	if element, ok := policy.elements[key]; ok {
+		policy.order.Remove(element)
+		delete(policy.elements, key)
+	}
+}
+
+// A method of TTLPolicy that stops tracking every object
+func (policy *TTLPolicy[K, V]) Reset() {
+	policy.order.Init()
+	policy.elements = make(map[K]*list.Element)
+}
+
+// A method of TTLPolicy that returns the oldest object as the victim
+func (policy *TTLPolicy[K, V]) Victim() (K, bool) {
+	// Check if there are any tracked keys
+	back := policy.order.Back()
+	if back == nil {
+		var key K
+		return key, false
+	}
+
+	return back.Value.(*ttlentry[K]).key, true
+}
+
+// A method of TTLPolicy that admits every object
+func (policy *TTLPolicy[K, V]) Admit(object V) bool { return true }
+
+// A method of TTLPolicy that returns the keys of the objects that have outlived the time to live
+func (policy *TTLPolicy[K, V]) Expired() []K {
+	// Determine the time before which objects have expired
+	deadline := policy.now().Add(-policy.ttl)
+
+	// Collect the expired keys from the oldest
+	var expired []K
+	for element := policy.order.Back(); element != nil; element = element.Prev() {
+		entry := element.Value.(*ttlentry[K])
+		if entry.added.After(deadline) {
+			break
+		}
+
+		expired = append(expired, entry.key)
+	}
+
+	return expired
+}
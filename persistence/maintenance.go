@@ -0,0 +1,159 @@
+package persistence
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Represents the default fraction of stale data in a value log file before it is rewritten
+const DefaultGCRatio = 0.5
+
+// Represents the default interval between the maintenance runs of a bucket
+const DefaultGCInterval = 10 * time.Minute
+
+// A struct that represents the disk usage statistics of a database bucket
+type BucketStats struct {
+	// Represents the type of bucket
+	Bucket Bucket
+	// Represents the size of the LSM tree files (keys and small values) in bytes
+	LSMSize int64
+	// Represents the size of the value log files in bytes
+	VlogSize int64
+}
+
+// A method of DatabaseBucket that returns the disk usage statistics of the bucket.
+// The sizes are measured from the files in the bucket directory.
+func (db *DatabaseBucket) Stats() BucketStats {
+	// Create the stats for the bucket
+	stats := BucketStats{Bucket: db.Bucket}
+
+	// Walk over the files in the bucket directory
+	filepath.Walk(db.Client.Opts().Dir, func(path string, info os.FileInfo, err error) error {
+		// Skip any files that cannot be read
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		// Accumulate the size of the file based on its type
+		switch filepath.Ext(path) {
+		case ".sst":
+			stats.LSMSize += info.Size()
+		case ".vlog":
+			stats.VlogSize += info.Size()
+		}
+
+		return nil
+	})
+
+	// Return the stats
+	return stats
+}
+
+// A method of DatabaseBucket that runs the value log garbage collection of the bucket.
+// Value log files with at least the given ratio of stale data are rewritten until no
+// more files can be rewritten. Returns the number of value log files that were rewritten.
+func (db *DatabaseBucket) RunGC(ratio float64) (int, error) {
+	// Declare a counter for the rewritten files
+	rewrites := 0
+
+	for {
+		// Run the value log garbage collection for a single file
+		err := db.Client.RunValueLogGC(ratio)
+		if errors.Is(err, badger.ErrNoRewrite) || errors.Is(err, badger.ErrRejected) {
+			// No more files can be rewritten
+			return rewrites, nil
+		} else if err != nil {
+			return rewrites, err
+		}
+
+		// Increment the counter
+		rewrites++
+	}
+}
+
+// A method of DatabaseBucket that compacts the bucket. The LSM tree is flattened into
+// a single level, which drops deleted and overwritten keys, after which the value log
+// is garbage collected with the given ratio. Returns the number of value log files
+// that were rewritten.
+func (db *DatabaseBucket) Compact(ratio float64) (int, error) {
+	// Flatten the LSM tree of the bucket
+	if err := db.Client.Flatten(runtime.NumCPU()); err != nil {
+		return 0, err
+	}
+
+	// Run the value log garbage collection
+	return db.RunGC(ratio)
+}
+
+// A method of DatabaseBucket that starts the background maintenance loop of the bucket.
+// The value log of the bucket is garbage collected with the given ratio on every interval
+// until the bucket is closed. Zero values are replaced with the default ratio and interval.
+func (db *DatabaseBucket) StartMaintenance(ratio float64, interval time.Duration) {
+	// Apply the defaults for any unconfigured values
+	if ratio <= 0 || ratio >= 1 {
+		ratio = DefaultGCRatio
+	}
+	if interval <= 0 {
+		interval = DefaultGCInterval
+	}
+
+	// Create the stop channel of the maintenance loop
+	db.stop = make(chan struct{})
+	db.maintenance.Add(1)
+
+	// Start the maintenance loop
+	go func() {
+		// Defer the completion of the maintenance loop
+		defer db.maintenance.Done()
+
+		// Create a ticker for the interval
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			// The bucket is being closed
+			case <-db.stop:
+				return
+
+			// The interval has elapsed
+			case <-ticker.C:
+				// Run the value log garbage collection
+				rewrites, err := db.RunGC(ratio)
+				if err != nil {
+					logrus.WithFields(logrus.Fields{"bucket": db.Bucket, "error": err}).Warn("database bucket garbage collection failed.")
+					continue
+				}
+
+				// Log the stats of the bucket
+				stats := db.Stats()
+				logrus.WithFields(logrus.Fields{
+					"bucket":   db.Bucket,
+					"rewrites": rewrites,
+					"lsm":      stats.LSMSize,
+					"vlog":     stats.VlogSize,
+				}).Debug("database bucket garbage collection complete.")
+			}
+		}
+	}()
+}
+
+// A method of DatabaseBucket that stops the background maintenance loop of the bucket
+// and waits for any running garbage collection to finish. Does nothing if not started.
+func (db *DatabaseBucket) StopMaintenance() {
+	// Check if the maintenance loop has been started
+	if db.stop == nil {
+		return
+	}
+
+	// Signal the maintenance loop to stop and wait for it
+	close(db.stop)
+	db.maintenance.Wait()
+	db.stop = nil
+}
@@ -0,0 +1,34 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_BucketMaintenance(t *testing.T) {
+	store, err := OpenBadgerStore(STATE, t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenBadgerStore() failed! %v", err)
+	}
+
+	// Write and then delete a set of keys to leave stale data behind
+	for i := 0; i < 100; i++ {
+		store.SetKey([]byte{byte(i)}, make([]byte, 1024))
+	}
+	store.DeleteKeyPrefix([]byte{})
+
+	if _, err := store.Compact(DefaultGCRatio); err != nil {
+		t.Fatalf("Compact() failed! %v", err)
+	}
+	if stats := store.Stats(); stats.Bucket != STATE || stats.LSMSize < 0 || stats.VlogSize < 0 {
+		t.Fatalf("Stats() failed! got: %+v", stats)
+	}
+
+	// The maintenance loop is stopped when the bucket is closed
+	store.StartMaintenance(0, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	store.Close()
+	if store.stop != nil {
+		t.Fatalf("Close() failed! expected the maintenance loop to be stopped")
+	}
+}
@@ -0,0 +1,241 @@
+package persistence
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A struct that represents an in-memory Store.
+// A MemoryStore holds no data on disk and is used for tests and simulations.
+type MemoryStore struct {
+	// Represents the mapping of keys to values
+	entries map[string][]byte
+	// Represents the synchronization lock for the store
+	mutex sync.RWMutex
+}
+
+// Assert that the MemoryStore and DatabaseBucket implement the Store interface
+var _ Store = (*MemoryStore)(nil)
+var _ Store = (*DatabaseBucket)(nil)
+
+// A constructor function that generates and returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string][]byte)}
+}
+
+// A method of MemoryStore that retrieves the value for a given key
+func (ms *MemoryStore) GetKey(key []byte) ([]byte, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	return memoryget(ms.entries, key)
+}
+
+// A method of MemoryStore that sets the value for a given key
+func (ms *MemoryStore) SetKey(key, value []byte) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	// Set a copy of the value for the key
+	ms.entries[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+// A method of MemoryStore that deletes the entry for a given key
+func (ms *MemoryStore) DeleteKey(key []byte) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.entries, string(key))
+	return nil
+}
+
+// A method of MemoryStore that deletes all entries with a given prefix
+func (ms *MemoryStore) DeleteKeyPrefix(prefix []byte) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	// Iterate over the entries and delete the keys with the prefix
+	for key := range ms.entries {
+		if strings.HasPrefix(key, string(prefix)) {
+			delete(ms.entries, key)
+		}
+	}
+}
+
+// A method of MemoryStore that calls a function for every key-value pair with a given prefix
+func (ms *MemoryStore) IteratePrefix(prefix []byte, fn func(key, value []byte) error) error {
+	// Collect the entries with the prefix (the lock is not held during the calls)
+	ms.mutex.RLock()
+	keys, values := memorycollect(ms.entries, prefix)
+	ms.mutex.RUnlock()
+
+	// Call the function for each entry in order of the keys
+	for index := range keys {
+		if err := fn(keys[index], values[index]); err != nil {
+			return err
+		}
+	}
+
+	// Return the nil error
+	return nil
+}
+
+// A method of MemoryStore that calls a function with a batch and applies
+// its writes to the store if the function returns a nil error. Batches
+// are serialized with all other writes on the store.
+func (ms *MemoryStore) Batch(fn func(batch Batch) error) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	// Create a batch over the entries of the store
+	batch := &memorybatch{base: ms.entries, writes: make(map[string][]byte)}
+	if err := fn(batch); err != nil {
+		return err
+	}
+
+	// Apply the writes of the batch to the store
+	for key, value := range batch.writes {
+		if value == nil {
+			delete(ms.entries, key)
+		} else {
+			ms.entries[key] = value
+		}
+	}
+
+	// Return the nil error
+	return nil
+}
+
+// A method of MemoryStore that returns a snapshot with a copy of the entries of the store
+func (ms *MemoryStore) Snapshot() Snapshot {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	// Copy the entries of the store into a new store
+	snapshot := NewMemoryStore()
+	for key, value := range ms.entries {
+		snapshot.entries[key] = value
+	}
+
+	return &memorysnapshot{snapshot}
+}
+
+// A method of MemoryStore that closes the store. The entries of the store are released.
+func (ms *MemoryStore) Close() {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.entries = make(map[string][]byte)
+}
+
+// A struct that represents a batch of writes over the entries of a MemoryStore.
+// A nil value in the writes represents the deletion of the key.
+type memorybatch struct {
+	// Represents the entries of the store
+	base map[string][]byte
+	// Represents the writes made on the batch
+	writes map[string][]byte
+}
+
+// A method of memorybatch that retrieves the value for a given key
+func (mb *memorybatch) GetKey(key []byte) ([]byte, error) {
+	// Check if the key has been written on the batch
+	if value, ok := mb.writes[string(key)]; ok {
+		if value == nil {
+			return nil, fmt.Errorf("failed to GET database item! error - %w", ErrKeyNotFound)
+		}
+
+		return append([]byte{}, value...), nil
+	}
+
+	return memoryget(mb.base, key)
+}
+
+// A method of memorybatch that sets the value for a given key
+func (mb *memorybatch) SetKey(key, value []byte) error {
+	mb.writes[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+// A method of memorybatch that deletes the entry for a given key
+func (mb *memorybatch) DeleteKey(key []byte) error {
+	mb.writes[string(key)] = nil
+	return nil
+}
+
+// A method of memorybatch that calls a function for every key-value pair with a
+// given prefix, including the writes that have been made on the batch
+func (mb *memorybatch) IteratePrefix(prefix []byte, fn func(key, value []byte) error) error {
+	// Merge the writes of the batch over the entries of the store
+	merged := make(map[string][]byte)
+	for key, value := range mb.base {
+		merged[key] = value
+	}
+	for key, value := range mb.writes {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	// Call the function for each entry in order of the keys
+	keys, values := memorycollect(merged, prefix)
+	for index := range keys {
+		if err := fn(keys[index], values[index]); err != nil {
+			return err
+		}
+	}
+
+	// Return the nil error
+	return nil
+}
+
+// A struct that represents a snapshot of a MemoryStore
+type memorysnapshot struct {
+	*MemoryStore
+}
+
+// A method of memorysnapshot that discards the snapshot
+func (snapshot *memorysnapshot) Discard() {
+	snapshot.MemoryStore.Close()
+}
+
+// A function that retrieves a copy of the value for a key from a map of entries
+func memoryget(entries map[string][]byte, key []byte) ([]byte, error) {
+	// Retrieve the value from the entries
+	value, ok := entries[string(key)]
+	if !ok {
+		return nil, fmt.Errorf("failed to GET database item! error - %w", ErrKeyNotFound)
+	}
+
+	// Return a copy of the value
+	return append([]byte{}, value...), nil
+}
+
+// A function that collects the keys and values with a given prefix
+// from a map of entries and returns them in order of the keys
+func memorycollect(entries map[string][]byte, prefix []byte) ([][]byte, [][]byte) {
+	// Collect the keys with the prefix
+	var collected []string
+	for key := range entries {
+		if strings.HasPrefix(key, string(prefix)) {
+			collected = append(collected, key)
+		}
+	}
+
+	// Sort the keys in order
+	sort.Strings(collected)
+
+	// Collect the keys and copies of their values
+	keys, values := make([][]byte, len(collected)), make([][]byte, len(collected))
+	for index, key := range collected {
+		keys[index] = []byte(key)
+		values[index] = append([]byte{}, entries[key]...)
+	}
+
+	return keys, values
+}
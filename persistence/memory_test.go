@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func Test_MemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+
+	if _, err := store.GetKey([]byte("missing")); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("GetKey() of missing key failed! expected: %v, got: %v", ErrKeyNotFound, err)
+	}
+
+	store.SetKey([]byte("utxo-b"), []byte("2"))
+	store.SetKey([]byte("utxo-a"), []byte("1"))
+	store.SetKey([]byte("other"), []byte("3"))
+
+	var keys []string
+	store.IteratePrefix([]byte("utxo-"), func(key, value []byte) error {
+		keys = append(keys, string(key))
+		return nil
+	})
+
+	if fmt.Sprint(keys) != "[utxo-a utxo-b]" {
+		t.Fatalf("IteratePrefix() failed! expected: [utxo-a utxo-b], got: %v", keys)
+	}
+
+	store.DeleteKeyPrefix([]byte("utxo-"))
+	if _, err := store.GetKey([]byte("utxo-a")); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("DeleteKeyPrefix() failed! key was not deleted")
+	}
+}
+
+func Test_MemoryStoreBatch(t *testing.T) {
+	store := NewMemoryStore()
+	store.SetKey([]byte("a"), []byte("1"))
+
+	// A failed batch discards all its writes
+	err := store.Batch(func(batch Batch) error {
+		batch.SetKey([]byte("b"), []byte("2"))
+		return errors.New("abort")
+	})
+
+	if err == nil {
+		t.Fatalf("Batch() failed! expected the batch error")
+	}
+	if _, err := store.GetKey([]byte("b")); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Batch() failed! write of an aborted batch was applied")
+	}
+
+	// A successful batch observes and applies its own writes
+	err = store.Batch(func(batch Batch) error {
+		batch.SetKey([]byte("b"), []byte("2"))
+		batch.DeleteKey([]byte("a"))
+
+		if value, err := batch.GetKey([]byte("b")); err != nil || !bytes.Equal(value, []byte("2")) {
+			return fmt.Errorf("batch did not observe its write")
+		}
+		if _, err := batch.GetKey([]byte("a")); !errors.Is(err, ErrKeyNotFound) {
+			return fmt.Errorf("batch did not observe its delete")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Batch() failed! %v", err)
+	}
+	if value, _ := store.GetKey([]byte("b")); !bytes.Equal(value, []byte("2")) {
+		t.Fatalf("Batch() failed! write was not applied")
+	}
+}
+
+func Test_MemoryStoreSnapshot(t *testing.T) {
+	store := NewMemoryStore()
+	store.SetKey([]byte("a"), []byte("1"))
+
+	snapshot := store.Snapshot()
+	defer snapshot.Discard()
+
+	store.SetKey([]byte("a"), []byte("2"))
+
+	if value, _ := snapshot.GetKey([]byte("a")); !bytes.Equal(value, []byte("1")) {
+		t.Fatalf("Snapshot() failed! expected: 1, got: %s", value)
+	}
+}
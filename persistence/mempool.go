@@ -5,25 +5,12 @@ import (
 	"sync"
 )
 
-// A type alias that represents a type of pool state event
-type PoolEvent string
-
-// A set of constants that represent valids types of pool state events
-const (
-	// Pool is full
-	POOLFULL PoolEvent = "pool is full"
-	// Pool is empty
-	POOLEMPTY PoolEvent = "pool is empty"
-	// Pool has been reset
-	POOLPURGE PoolEvent = "pool has been purged"
-	// Pool has been resized
-	POOLRESIZE PoolEvent = "pool has been resized"
-)
-
-// A struct that represents a memory pool of objects
-type MemPool struct {
+// A struct that represents a memory pool of objects of type V addressable by keys of type K.
+// When the pool is full, the eviction policy of the pool decides whether a new object is
+// rejected or whether it replaces an existing object. The policy can also expire objects.
+type MemPool[K comparable, V any] struct {
 	// Represents the actual addressable pool of objects
-	pool map[string]interface{}
+	pool map[K]V
 	// Represents the syncrhonization lock for the pool
 	mutex sync.Mutex
 
@@ -32,165 +19,336 @@ type MemPool struct {
 	// Represents the maximum size of the pool
 	size uint
 
-	// Represents the event channel for pool state events
-	eventchan chan PoolEvent
+	// Represents the eviction policy of the pool
+	policy EvictionPolicy[K, V]
+	// Represents the event bus for pool events
+	events eventbus[K, V]
 }
 
 // A constructor function that creates a new memory pool of the given size and returns it.
-// Requires a boolean that determines if the event handler for the pool should be initialized.
-func NewMemPool(poolsize uint, enable_events bool) *MemPool {
-	// Create a new MemPool object
-	pool := &MemPool{
-		pool:  make(map[string]interface{}),
-		mutex: sync.Mutex{},
-		Count: 0,
-		size:  poolsize,
+// If the eviction policy is nil, a full pool rejects new objects.
+func NewMemPool[K comparable, V any](poolsize uint, policy EvictionPolicy[K, V]) *MemPool[K, V] {
+	// Default to the policy that rejects new objects
+	if policy == nil {
+		policy = rejectpolicy[K, V]{}
 	}
 
-	// Check if the event handler should be enabled
-	if enable_events {
-		// Initialize the event handler
-		pool.eventchan = make(chan PoolEvent)
+	// Create a new MemPool object
+	pool := &MemPool[K, V]{
+		pool:   make(map[K]V),
+		mutex:  sync.Mutex{},
+		Count:  0,
+		size:   poolsize,
+		policy: policy,
 	}
 
 	// Return the new pool
 	return pool
 }
 
-// A method of MemPool that returns the event channel for the pool
-func (pool *MemPool) GetEventChannel() chan PoolEvent {
-	return pool.eventchan
+// A method of MemPool that subscribes to the events of the pool.
+// Events are delivered on the channel of the subscription, which buffers
+// the given number of events and handles a full buffer with the given policy.
+// Subscriptions that drop events buffer at least one event, while a BLOCK
+// subscription without a buffer waits for its reader on every event.
+// Events are never sent while the pool is locked, so the subscription can
+// call back into the pool. Call Unsubscribe when the events are not needed.
+func (pool *MemPool[K, V]) Subscribe(buffer int, policy DropPolicy) *Subscription[K, V] {
+	return pool.events.subscribe(buffer, policy)
+}
+
+// A method of MemPool that subscribes a callback to the events of the pool.
+// The callback is called for each event in order on a separate goroutine,
+// with the buffer and drop policy applied as with Subscribe.
+func (pool *MemPool[K, V]) SubscribeFunc(buffer int, policy DropPolicy, fn func(event PoolEvent[K, V])) *Subscription[K, V] {
+	// Create a subscription
+	sub := pool.events.subscribe(buffer, policy)
+
+	// Start a goroutine that calls the callback for each event
+	go func() {
+		for event := range sub.C {
+			fn(event)
+		}
+	}()
+
+	// Return the subscription
+	return sub
 }
 
 // A method of MemPool that reports whether the pool is full
-func (pool *MemPool) IsFull() bool {
+func (pool *MemPool[K, V]) IsFull() bool {
 	return pool.Count >= pool.size
 }
 
 // A method of MemPool that reports whether the pool is empty
-func (pool *MemPool) IsEmpty() bool {
+func (pool *MemPool[K, V]) IsEmpty() bool {
 	return pool.Count == 0
 }
 
 // A method of MemPool that adds an object to the pool which is addressable by the given key.
-// Returns an error if the pool is full. If an object exists for the key, it is overwritten.
-func (pool *MemPool) Put(key string, object interface{}) error {
+// If an object exists for the key, it is overwritten. If the pool is full, the object evicts
+// the victim of the eviction policy, or an error is returned if the policy does not admit it.
+func (pool *MemPool[K, V]) Put(key K, object V) error {
 	// Acquire the lock on the pool
 	pool.mutex.Lock()
-	defer pool.mutex.Unlock()
 
-	// Check if the pool is full
-	if pool.IsFull() {
-		// Return an error
-		return fmt.Errorf("pool is full")
+	// Remove any expired objects
+	events := pool.expire()
+
+	// Check if the pool is full and the key is new
+	if _, exists := pool.pool[key]; !exists && pool.IsFull() {
+		// Retrieve the victim of the eviction policy
+		victim, ok := pool.policy.Victim()
+		// Check if the object can replace the victim
+		if !ok || !pool.policy.Admit(object) {
+			// Release the lock, publish the expiry events and return an error
+			pool.mutex.Unlock()
+			pool.events.publish(events...)
+			return fmt.Errorf("pool is full")
+		}
+
+		// Evict the victim from the pool
+		events = append(events, pool.remove(victim, POOLEVICT)...)
 	}
 
-	// Increment the pool count
-	pool.Count++
 	// Add the object to the pool
 	pool.pool[key] = object
+	pool.policy.Added(key, object)
+	// Update the count of the pool
+	pool.Count = uint(len(pool.pool))
 
+	// Collect the events of the put
+	events = append(events, PoolEvent[K, V]{Type: POOLPUT, Key: key, Object: object})
 	// Check if the pool is full
 	if pool.IsFull() {
-		// Check if the event handler is initalized
-		if pool.eventchan != nil {
-			// Send a pool full event
-			pool.eventchan <- POOLFULL
-		}
+		events = append(events, PoolEvent[K, V]{Type: POOLFULL})
 	}
 
+	// Release the lock and publish the events
+	pool.mutex.Unlock()
+	pool.events.publish(events...)
+
 	// Return nil error
 	return nil
 }
 
 // A method of MemPool that returns the object that is addressable by the given key.
 // Returns the object and a boolean that indicates whether the object exists in the pool.
-func (pool *MemPool) Get(key string) (interface{}, bool) {
+// The read counts as an access for the eviction policy.
+func (pool *MemPool[K, V]) Get(key K) (V, bool) {
 	// Acquire the lock on the pool
 	pool.mutex.Lock()
-	defer pool.mutex.Unlock()
+
+	// Remove any expired objects
+	events := pool.expire()
 
 	// Retrieve the object from the pool
 	object, ok := pool.pool[key]
+	// Notify the eviction policy of the access
+	if ok {
+		pool.policy.Accessed(key)
+	}
+
+	// Release the lock and publish the expiry events
+	pool.mutex.Unlock()
+	pool.events.publish(events...)
+
 	// Return the object and a boolean that indicates whether the object exists in the pool
 	return object, ok
 }
 
-// A method of MemPool that removes the object that is addressable by the given key.
-func (pool *MemPool) Remove(key string) {
+// A method of MemPool that reports whether an object exists for the given key.
+// Unlike Get, the check does not count as an access for the eviction policy.
+func (pool *MemPool[K, V]) Contains(key K) bool {
 	// Acquire the lock on the pool
 	pool.mutex.Lock()
 	defer pool.mutex.Unlock()
 
-	// Remove the object from the pool
-	delete(pool.pool, key)
-	// Update the count of the pool
-	pool.Count = uint(len(pool.pool))
+	_, ok := pool.pool[key]
+	return ok
+}
 
-	// Check if the pool is empty
-	if pool.IsEmpty() {
-		// Check if the event handler is initalized
-		if pool.eventchan != nil {
-			// Send a pool empty event
-			pool.eventchan <- POOLEMPTY
+// A method of MemPool that returns the key and object that the eviction policy would evict next.
+// Allows the owner of a pool to evict objects itself (along with any objects that depend on
+// them) before the pool is full. Returns false if the policy does not evict objects.
+func (pool *MemPool[K, V]) Victim() (K, V, bool) {
+	// Acquire the lock on the pool
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	// Retrieve the victim of the eviction policy
+	var object V
+	key, ok := pool.policy.Victim()
+	if ok {
+		object, ok = pool.pool[key]
+	}
+
+	return key, object, ok
+}
+
+// A method of MemPool that calls a function for each object in the pool until it returns false.
+// The objects are collected before the function is called, so the function can call back into
+// the pool. The reads do not count as accesses for the eviction policy.
+func (pool *MemPool[K, V]) Range(fn func(key K, object V) bool) {
+	// Acquire the lock on the pool
+	pool.mutex.Lock()
+
+	// Collect the keys and objects of the pool
+	keys := make([]K, 0, len(pool.pool))
+	objects := make([]V, 0, len(pool.pool))
+	for key, object := range pool.pool {
+		keys = append(keys, key)
+		objects = append(objects, object)
+	}
+
+	// Release the lock on the pool
+	pool.mutex.Unlock()
+
+	// Call the function for each object
+	for index, key := range keys {
+		if !fn(key, objects[index]) {
+			return
 		}
 	}
 }
 
+// A method of MemPool that removes the object that is addressable by the given key.
+func (pool *MemPool[K, V]) Remove(key K) {
+	// Acquire the lock on the pool
+	pool.mutex.Lock()
+	// Remove the object and collect the events of the removal
+	events := pool.remove(key, POOLREMOVE)
+
+	// Release the lock and publish the events
+	pool.mutex.Unlock()
+	pool.events.publish(events...)
+}
+
 // A method of MemPool that retrieves the object that is addressable by the given key and removes it from the pool.
 // Returns the object and a boolean that indicates whether the object exists in the pool.
-func (pool *MemPool) Pop(key string) (interface{}, bool) {
-	// Retrieve the object from the pool and the boolean that indicates whether the object exists in the pool
-	object, ok := pool.Get(key)
-	// Check if the object exists in the pool
-	if ok {
-		// Remove the object from the pool
-		pool.Remove(key)
-	}
+func (pool *MemPool[K, V]) Pop(key K) (V, bool) {
+	// Acquire the lock on the pool
+	pool.mutex.Lock()
+
+	// Retrieve the object from the pool
+	object, ok := pool.pool[key]
+	// Remove the object and collect the events of the removal
+	events := pool.remove(key, POOLREMOVE)
+
+	// Release the lock and publish the events
+	pool.mutex.Unlock()
+	pool.events.publish(events...)
 
 	// Return the object and a boolean that indicates whether the object exists in the pool
 	return object, ok
 }
 
+// A method of MemPool that removes the objects that have expired according to the
+// eviction policy. Expired objects are also removed whenever the pool is accessed.
+// Returns the number of objects that were removed.
+func (pool *MemPool[K, V]) Expire() int {
+	// Acquire the lock on the pool
+	pool.mutex.Lock()
+	// Remove the expired objects and collect the events of the expiry
+	events := pool.expire()
+
+	// Release the lock and publish the events
+	pool.mutex.Unlock()
+	pool.events.publish(events...)
+
+	// Count the expired objects
+	expired := 0
+	for _, event := range events {
+		if event.Type == POOLEXPIRE {
+			expired++
+		}
+	}
+
+	return expired
+}
+
 // A method of MemPool that purges the pool of all objects.
-func (pool *MemPool) Purge() {
+func (pool *MemPool[K, V]) Purge() {
 	// Acquire the lock on the pool
 	pool.mutex.Lock()
-	defer pool.mutex.Unlock()
 
-	// Reset the pool map
-	pool.pool = make(map[string]interface{})
+	// Reset the pool map, count and eviction policy
+	pool.pool = make(map[K]V)
+	pool.Count = 0
+	pool.policy.Reset()
 
-	// Check if the event handler is initalized
-	if pool.eventchan != nil {
-		// Send a pool purge event and pool empty event
-		pool.eventchan <- POOLPURGE
-		pool.eventchan <- POOLEMPTY
-	}
+	// Release the lock and publish a pool purge event and pool empty event
+	pool.mutex.Unlock()
+	pool.events.publish(PoolEvent[K, V]{Type: POOLPURGE}, PoolEvent[K, V]{Type: POOLEMPTY})
 }
 
-// A method of MemPool that resizes the pool for the new size limit.
-// Returns an error if there are more object in the pool than the new size limit.
-func (pool *MemPool) Resize(newsize uint) error {
+// A method of MemPool that resizes the pool for the new size limit. If there are more
+// objects in the pool than the new size limit, the victims of the eviction policy are
+// evicted, or an error is returned if the policy does not evict objects.
+func (pool *MemPool[K, V]) Resize(newsize uint) error {
 	// Acquire the lock on the pool
 	pool.mutex.Lock()
-	defer pool.mutex.Unlock()
 
-	// Check if the new size limit is less than the current count of the pool
-	if newsize < pool.Count {
-		// Return an error
-		return fmt.Errorf("cannot resize pool to smaller size than the current number of elements")
+	// Collect the events of the resize
+	var events []PoolEvent[K, V]
+	// Evict objects until the pool fits in the new size limit
+	for pool.Count > newsize {
+		// Retrieve the victim of the eviction policy
+		victim, ok := pool.policy.Victim()
+		if !ok {
+			// Release the lock, publish the eviction events and return an error
+			pool.mutex.Unlock()
+			pool.events.publish(events...)
+			return fmt.Errorf("cannot resize pool to smaller size than the current number of elements")
+		}
+
+		// Evict the victim from the pool
+		events = append(events, pool.remove(victim, POOLEVICT)...)
 	}
 
 	// Modify the size of the pool
 	pool.size = newsize
 
-	// Check if the event handler is initalized
-	if pool.eventchan != nil {
-		// Send a pool resize event
-		pool.eventchan <- POOLRESIZE
-	}
+	// Release the lock and publish the events with a pool resize event
+	pool.mutex.Unlock()
+	pool.events.publish(append(events, PoolEvent[K, V]{Type: POOLRESIZE})...)
 
 	// Return nil error
 	return nil
 }
+
+// A method of MemPool that removes the object for a key with the given event type and
+// returns the events of the removal. Must be called while holding the lock on the pool.
+func (pool *MemPool[K, V]) remove(key K, eventtype PoolEventType) []PoolEvent[K, V] {
+	// Retrieve the object from the pool
+	object, ok := pool.pool[key]
+	if !ok {
+		return nil
+	}
+
+	// Remove the object from the pool and the eviction policy
+	delete(pool.pool, key)
+	pool.policy.Removed(key)
+	// Update the count of the pool
+	pool.Count = uint(len(pool.pool))
+
+	// Collect the events of the removal
+	events := []PoolEvent[K, V]{{Type: eventtype, Key: key, Object: object}}
+	// Check if the pool is empty
+	if pool.IsEmpty() {
+		events = append(events, PoolEvent[K, V]{Type: POOLEMPTY})
+	}
+
+	return events
+}
+
+// A method of MemPool that removes the expired objects and returns the
+// events of the expiry. Must be called while holding the lock on the pool.
+func (pool *MemPool[K, V]) expire() []PoolEvent[K, V] {
+	var events []PoolEvent[K, V]
+	for _, key := range pool.policy.Expired() {
+		events = append(events, pool.remove(key, POOLEXPIRE)...)
+	}
+
+	return events
+}
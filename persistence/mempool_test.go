@@ -0,0 +1,230 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_MemPoolEvents(t *testing.T) {
+	pool := NewMemPool[string, int](2, nil)
+
+	// Operations on a pool without subscribers never block
+	pool.Put("a", 1)
+	pool.Remove("a")
+
+	first := pool.Subscribe(8, DROPNEWEST)
+	second := pool.Subscribe(8, DROPNEWEST)
+	defer first.Unsubscribe()
+	defer second.Unsubscribe()
+
+	pool.Put("a", 1)
+	pool.Put("b", 2)
+
+	// Every subscriber receives every event in order
+	expected := []PoolEvent[string, int]{{Type: POOLPUT, Key: "a", Object: 1}, {Type: POOLPUT, Key: "b", Object: 2}, {Type: POOLFULL}}
+	for _, sub := range []*Subscription[string, int]{first, second} {
+		for _, event := range expected {
+			if received := <-sub.C; received != event {
+				t.Fatalf("Subscribe() failed! expected: %v, got: %v", event, received)
+			}
+		}
+	}
+}
+
+func Test_MemPoolDropPolicies(t *testing.T) {
+	pool := NewMemPool[string, int](16, nil)
+
+	newest := pool.Subscribe(1, DROPNEWEST)
+	oldest := pool.Subscribe(1, DROPOLDEST)
+	defer newest.Unsubscribe()
+	defer oldest.Unsubscribe()
+
+	// Nobody reads, so the buffers fill up without blocking the pool
+	pool.Put("a", 1)
+	pool.Put("b", 2)
+	pool.Put("c", 3)
+
+	if event := <-newest.C; event.Key != "a" || newest.Dropped() != 2 {
+		t.Fatalf("DROPNEWEST failed! expected: a with 2 dropped, got: %v with %v dropped", event.Key, newest.Dropped())
+	}
+	if event := <-oldest.C; event.Key != "c" || oldest.Dropped() != 2 {
+		t.Fatalf("DROPOLDEST failed! expected: c with 2 dropped, got: %v with %v dropped", event.Key, oldest.Dropped())
+	}
+
+	// A dropping subscription without a buffer still buffers one event
+	unbuffered := pool.Subscribe(0, DROPOLDEST)
+	defer unbuffered.Unsubscribe()
+
+	pool.Put("d", 4)
+	pool.Put("e", 5)
+	if event := <-unbuffered.C; event.Key != "e" || unbuffered.Dropped() != 1 {
+		t.Fatalf("DROPOLDEST failed! expected: e with 1 dropped, got: %v with %v dropped", event.Key, unbuffered.Dropped())
+	}
+}
+
+func Test_MemPoolBackpressure(t *testing.T) {
+	pool := NewMemPool[string, int](16, nil)
+	sub := pool.Subscribe(0, BLOCK)
+
+	// A blocked publisher does not hold the pool lock
+	done := make(chan struct{})
+	go func() {
+		pool.Put("a", 1)
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if _, ok := pool.Get("a"); !ok {
+		t.Fatalf("BLOCK failed! expected the pool to be usable while the publisher waits")
+	}
+
+	// Cancelling the subscription releases the publisher and closes the channel
+	sub.Unsubscribe()
+	<-done
+	if _, ok := <-sub.C; ok {
+		t.Fatalf("Unsubscribe() failed! expected the event channel to be closed")
+	}
+}
+
+func Test_MemPoolSubscribeFunc(t *testing.T) {
+	pool := NewMemPool[string, int](16, nil)
+
+	// A callback can call back into the pool
+	received := make(chan PoolEvent[string, int], 4)
+	sub := pool.SubscribeFunc(4, BLOCK, func(event PoolEvent[string, int]) {
+		pool.Get(event.Key)
+		received <- event
+	})
+	defer sub.Unsubscribe()
+
+	pool.Put("a", 1)
+	if event := <-received; event.Type != POOLPUT || event.Key != "a" {
+		t.Fatalf("SubscribeFunc() failed! got: %v", event)
+	}
+}
+
+func Test_MemPoolAccounting(t *testing.T) {
+	pool := NewMemPool[string, int](2, nil)
+
+	// Overwriting a key does not change the count
+	pool.Put("a", 1)
+	pool.Put("a", 2)
+	if pool.Count != 1 {
+		t.Fatalf("Put() failed! expected: 1, got: %v", pool.Count)
+	}
+
+	// A full pool without an eviction policy rejects new keys but accepts overwrites
+	pool.Put("b", 1)
+	if err := pool.Put("c", 1); err == nil {
+		t.Fatalf("Put() failed! expected: error for a full pool")
+	}
+	if err := pool.Put("b", 2); err != nil {
+		t.Fatalf("Put() failed! expected: nil, got: %v", err)
+	}
+
+	// Removing a missing key does not change the count
+	pool.Remove("c")
+	if object, ok := pool.Pop("a"); !ok || object != 2 || pool.Count != 1 {
+		t.Fatalf("Pop() failed! expected: 2 with count 1, got: %v with count %v", object, pool.Count)
+	}
+
+	// Shrinking below the count fails without an eviction policy
+	if err := pool.Resize(0); err == nil {
+		t.Fatalf("Resize() failed! expected: error for a smaller size")
+	}
+}
+
+func Test_LRUPolicy(t *testing.T) {
+	pool := NewMemPool[string, int](2, NewLRUPolicy[string, int]())
+	sub := pool.Subscribe(16, DROPNEWEST)
+	defer sub.Unsubscribe()
+
+	// Reading a makes b the least recently used
+	pool.Put("a", 1)
+	pool.Put("b", 2)
+	pool.Get("a")
+	pool.Put("c", 3)
+
+	if pool.Contains("b") || !pool.Contains("a") || !pool.Contains("c") || pool.Count != 2 {
+		t.Fatalf("LRUPolicy failed! expected: b to be evicted")
+	}
+
+	// The eviction is published
+	for event := range sub.C {
+		if event.Type == POOLEVICT {
+			if event.Key != "b" || event.Object != 2 {
+				t.Fatalf("LRUPolicy failed! expected: eviction of b, got: %v", event)
+			}
+			break
+		}
+	}
+
+	// Shrinking the pool evicts the least recently used
+	pool.Resize(1)
+	if pool.Contains("a") || !pool.Contains("c") {
+		t.Fatalf("Resize() failed! expected: a to be evicted")
+	}
+}
+
+func Test_PriorityPolicy(t *testing.T) {
+	pool := NewMemPool[string, int](2, NewPriorityPolicy[string](func(object int) int64 { return int64(object) }))
+
+	pool.Put("a", 5)
+	pool.Put("b", 3)
+
+	// The victim is the object with the lowest priority
+	if key, object, ok := pool.Victim(); !ok || key != "b" || object != 3 {
+		t.Fatalf("Victim() failed! expected: b, got: %v", key)
+	}
+
+	// An object with a lower priority than every object is rejected
+	if err := pool.Put("c", 1); err == nil {
+		t.Fatalf("PriorityPolicy failed! expected: error for a low priority object")
+	}
+
+	// An object with a higher priority evicts the lowest priority object
+	if err := pool.Put("d", 4); err != nil || pool.Contains("b") {
+		t.Fatalf("PriorityPolicy failed! expected: b to be evicted, got: %v", err)
+	}
+
+	// Replacing an object updates its priority
+	pool.Put("a", 1)
+	pool.Put("e", 2)
+	if pool.Contains("a") || !pool.Contains("d") || !pool.Contains("e") {
+		t.Fatalf("PriorityPolicy failed! expected: a to be evicted")
+	}
+}
+
+func Test_TTLPolicy(t *testing.T) {
+	// Create a policy with a controlled clock
+	now := time.Unix(0, 0)
+	policy := NewTTLPolicy[string, int](time.Minute)
+	policy.now = func() time.Time { return now }
+
+	pool := NewMemPool[string, int](2, policy)
+	pool.Put("a", 1)
+
+	now = now.Add(30 * time.Second)
+	pool.Put("b", 2)
+
+	// A full pool evicts the oldest object
+	pool.Put("c", 3)
+	if pool.Contains("a") {
+		t.Fatalf("TTLPolicy failed! expected: a to be evicted")
+	}
+
+	// Objects expire after the time to live
+	now = now.Add(time.Minute)
+	if _, ok := pool.Get("b"); ok || pool.Count != 0 {
+		t.Fatalf("TTLPolicy failed! expected: b to have expired, got count: %v", pool.Count)
+	}
+
+	// Replacing an object restarts its time to live
+	pool.Put("d", 4)
+	now = now.Add(45 * time.Second)
+	pool.Put("d", 5)
+	now = now.Add(45 * time.Second)
+	if expired := pool.Expire(); expired != 0 || !pool.Contains("d") {
+		t.Fatalf("Expire() failed! expected: 0, got: %v", expired)
+	}
+}
@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/manishmeganathan/weave/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// A structure that represents a migration of the on-disk layout of the data in a store
+type Migration struct {
+	// Represents the schema version that the migration upgrades the store to
+	Version int
+
+	// Represents a description of the migration
+	Description string
+
+	// Represents the function that performs the migration
+	Apply func() error
+}
+
+// A function that returns the schema version of a store.
+// A store without a schema version is at version 0.
+func SchemaVersion(store Reader) (int, error) {
+	// Get the schema version from the store
+	value, err := store.GetKey(utils.SchemaVersionKey)
+	if errors.Is(err, ErrKeyNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	// Decode the schema version
+	version, err := utils.IntDecode(value)
+	if err != nil {
+		return 0, fmt.Errorf("malformed schema version! error - %v", err)
+	}
+
+	return version, nil
+}
+
+// A function that sets the schema version of a store
+func SetSchemaVersion(store Writer, version int) error {
+	// Set the schema version to the store as a fixed width integer
+	return store.SetKey(utils.SchemaVersionKey, utils.IntEncode(version))
+}
+
+// A function that migrates a store to the latest schema version from a set of migrations.
+// The migrations after the current schema version of the store are applied step by step
+// in order of their versions and the schema version is set after each migration, so that
+// an interrupted migration is resumed from the failed step. Returns an error if the store
+// is at a newer schema version than the latest migration or if a migration fails.
+func Migrate(store Store, migrations []Migration) error {
+	// Get the current schema version of the store
+	current, err := SchemaVersion(store)
+	if err != nil {
+		return err
+	}
+
+	// Sort the migrations in order of their versions
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	// Check that the store is not from a newer version of the software
+	if latest := LatestVersion(migrations); current > latest {
+		return fmt.Errorf("store schema version %v is newer than the supported version %v", current, latest)
+	}
+
+	// Iterate over the migrations
+	for _, migration := range migrations {
+		// Skip the migrations that have already been applied
+		if migration.Version <= current {
+			continue
+		}
+
+		// Log the migration
+		logrus.WithFields(logrus.Fields{"version": migration.Version}).Infof("migrating store: %v.", migration.Description)
+
+		// Apply the migration
+		if err := migration.Apply(); err != nil {
+			return fmt.Errorf("migration to schema version %v failed! error - %v", migration.Version, err)
+		}
+
+		// Set the schema version of the store
+		if err := SetSchemaVersion(store, migration.Version); err != nil {
+			return err
+		}
+	}
+
+	// Return a nil error
+	return nil
+}
+
+// A function that returns the latest schema version from a set of migrations
+func LatestVersion(migrations []Migration) int {
+	latest := 0
+	for _, migration := range migrations {
+		if migration.Version > latest {
+			latest = migration.Version
+		}
+	}
+
+	return latest
+}
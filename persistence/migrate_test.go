@@ -0,0 +1,66 @@
+package persistence
+
+import (
+	"bytes"
+	"testing"
+
+	legacybadger "github.com/dgraph-io/badger"
+)
+
+func Test_Migrate(t *testing.T) {
+	store := NewMemoryStore()
+	applied := []int{}
+
+	migrations := []Migration{
+		{Version: 2, Description: "second", Apply: func() error { applied = append(applied, 2); return nil }},
+		{Version: 1, Description: "first", Apply: func() error { applied = append(applied, 1); return nil }},
+	}
+
+	if err := Migrate(store, migrations); err != nil {
+		t.Fatalf("Migrate() failed! %v", err)
+	}
+	if len(applied) != 2 || applied[0] != 1 || applied[1] != 2 {
+		t.Fatalf("Migrate() order failed! expected: [1 2], got: %v", applied)
+	}
+	if version, _ := SchemaVersion(store); version != 2 {
+		t.Fatalf("SchemaVersion() failed! expected: 2, got: %v", version)
+	}
+
+	// Migrations that have been applied are not applied again
+	if err := Migrate(store, migrations); err != nil || len(applied) != 2 {
+		t.Fatalf("Migrate() repeat failed! applied: %v, error: %v", applied, err)
+	}
+
+	// A store from a newer version is rejected
+	SetSchemaVersion(store, 3)
+	if err := Migrate(store, migrations); err == nil {
+		t.Fatalf("Migrate() failed! expected an error for a newer schema version")
+	}
+}
+
+func Test_UpgradeBucket(t *testing.T) {
+	directory := t.TempDir()
+
+	// Create a bucket in the legacy format with a key
+	opts := legacybadger.DefaultOptions(directory)
+	opts.Logger = nil
+	legacydb, err := legacybadger.Open(opts)
+	if err != nil {
+		t.Fatalf("failed to open legacy bucket! %v", err)
+	}
+
+	legacydb.Update(func(txn *legacybadger.Txn) error {
+		return txn.Set([]byte("chainhead"), []byte("hash"))
+	})
+	legacydb.Close()
+
+	store, err := OpenBadgerStore(STATE, directory)
+	if err != nil {
+		t.Fatalf("OpenBadgerStore() failed! %v", err)
+	}
+	defer store.Close()
+
+	if value, err := store.GetKey([]byte("chainhead")); err != nil || !bytes.Equal(value, []byte("hash")) {
+		t.Fatalf("upgradebucket() failed! expected: hash, got: %s (%v)", value, err)
+	}
+}
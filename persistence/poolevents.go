@@ -0,0 +1,216 @@
+package persistence
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// A type alias that represents a type of pool event
+type PoolEventType string
+
+// A set of constants that represent valid types of pool events
+const (
+	// Object has been added to the pool (or replaced)
+	POOLPUT PoolEventType = "object has been added"
+	// Object has been removed from the pool
+	POOLREMOVE PoolEventType = "object has been removed"
+	// Pool is full
+	POOLFULL PoolEventType = "pool is full"
+	// Pool is empty
+	POOLEMPTY PoolEventType = "pool is empty"
+	// Pool has been reset
+	POOLPURGE PoolEventType = "pool has been purged"
+	// Pool has been resized
+	POOLRESIZE PoolEventType = "pool has been resized"
+	// Object has been evicted from the pool by its eviction policy
+	POOLEVICT PoolEventType = "object has been evicted"
+	// Object has expired from the pool
+	POOLEXPIRE PoolEventType = "object has expired"
+)
+
+// A struct that represents an event of a memory pool
+type PoolEvent[K comparable, V any] struct {
+	// Represents the type of event
+	Type PoolEventType
+	// Represents the key of the affected object (zero for pool state events)
+	Key K
+	// Represents the affected object (zero for pool state events)
+	Object V
+}
+
+// A type alias that represents the policy of a subscription when its buffer is full
+type DropPolicy int
+
+// A set of constants that represent valid subscription drop policies
+const (
+	// The new event is dropped when the buffer is full
+	DROPNEWEST DropPolicy = iota
+	// The oldest buffered event is dropped to make room for the new event
+	DROPOLDEST
+	// The publisher waits until there is room for the new event (backpressure)
+	BLOCK
+)
+
+// A struct that represents a subscription to the events of a memory pool
+type Subscription[K comparable, V any] struct {
+	// Represents the channel on which the events are received.
+	// The channel is closed when the subscription is cancelled.
+	C <-chan PoolEvent[K, V]
+
+	// Represents the send side of the event channel
+	events chan PoolEvent[K, V]
+	// Represents the drop policy of the subscription
+	policy DropPolicy
+	// Represents the number of events that have been dropped
+	dropped uint64
+
+	// Represents the channel that is closed when the subscription is cancelled
+	done chan struct{}
+	// Represents the synchronization lock for sending and closing the event channel
+	mutex sync.Mutex
+	// Represents whether the event channel has been closed
+	closed bool
+	// Represents the guard for cancelling the subscription once
+	once sync.Once
+	// Represents the event bus of the subscription
+	bus *eventbus[K, V]
+}
+
+// A method of Subscription that returns the number of events
+// that were dropped because the subscription buffer was full
+func (sub *Subscription[K, V]) Dropped() uint64 {
+	return atomic.LoadUint64(&sub.dropped)
+}
+
+// A method of Subscription that cancels the subscription and closes its
+// event channel. A publisher blocked on the subscription is released.
+func (sub *Subscription[K, V]) Unsubscribe() {
+	sub.once.Do(func() {
+		// Release any publisher that is blocked on the subscription
+		close(sub.done)
+		// Remove the subscription from the event bus
+		sub.bus.remove(sub)
+
+		// Close the event channel
+		sub.mutex.Lock()
+		defer sub.mutex.Unlock()
+		sub.closed = true
+		close(sub.events)
+	})
+}
+
+// A method of Subscription that delivers an event according to its drop policy
+func (sub *Subscription[K, V]) deliver(event PoolEvent[K, V]) {
+	// Acquire the lock on the subscription
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	// Check if the subscription has been cancelled
+	if sub.closed {
+		return
+	}
+
+	switch sub.policy {
+	case BLOCK:
+		// Wait for room in the buffer or for the subscription to be cancelled
+		select {
+		case sub.events <- event:
+		case <-sub.done:
+		}
+
+	case DROPOLDEST:
+		for {
+			// Attempt to send the event
+			select {
+			case sub.events <- event:
+				return
+			default:
+			}
+
+			// Drop the oldest buffered event to make room
+			select {
+			case <-sub.events:
+				atomic.AddUint64(&sub.dropped, 1)
+			default:
+			}
+		}
+
+	default:
+		// Attempt to send the event or drop it
+		select {
+		case sub.events <- event:
+		default:
+			atomic.AddUint64(&sub.dropped, 1)
+		}
+	}
+}
+
+// A struct that represents a bus that publishes events to many subscriptions
+type eventbus[K comparable, V any] struct {
+	// Represents the subscriptions on the bus
+	subscriptions map[*Subscription[K, V]]struct{}
+	// Represents the synchronization lock for the subscriptions
+	mutex sync.Mutex
+}
+
+// A method of eventbus that creates a subscription with the given buffer size and drop policy.
+// The buffer of a subscription that drops events holds at least one event, because a dropping
+// subscription without a buffer could never hold an event for its reader (DROPOLDEST would spin).
+func (bus *eventbus[K, V]) subscribe(buffer int, policy DropPolicy) *Subscription[K, V] {
+	// Clamp the buffer size for the drop policy
+	if buffer < 0 {
+		buffer = 0
+	}
+	if buffer < 1 && policy != BLOCK {
+		buffer = 1
+	}
+
+	// Create the subscription
+	events := make(chan PoolEvent[K, V], buffer)
+	sub := &Subscription[K, V]{C: events, events: events, policy: policy, done: make(chan struct{}), bus: bus}
+
+	// Add the subscription to the bus
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	if bus.subscriptions == nil {
+		bus.subscriptions = make(map[*Subscription[K, V]]struct{})
+	}
+	bus.subscriptions[sub] = struct{}{}
+
+	// Return the subscription
+	return sub
+}
+
+// A method of eventbus that removes a subscription from the bus
+func (bus *eventbus[K, V]) remove(sub *Subscription[K, V]) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	delete(bus.subscriptions, sub)
+}
+
+// A method of eventbus that publishes a set of events to every subscription in order.
+// Must not be called while holding the lock of the publisher, because a subscription
+// with the BLOCK policy waits for its subscriber to receive the events.
+func (bus *eventbus[K, V]) publish(events ...PoolEvent[K, V]) {
+	// Check if there are any events
+	if len(events) == 0 {
+		return
+	}
+
+	// Collect the current subscriptions
+	bus.mutex.Lock()
+	subscriptions := make([]*Subscription[K, V], 0, len(bus.subscriptions))
+	for sub := range bus.subscriptions {
+		subscriptions = append(subscriptions, sub)
+	}
+	bus.mutex.Unlock()
+
+	// Deliver the events to every subscription
+	for _, sub := range subscriptions {
+		for _, event := range events {
+			sub.deliver(event)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package persistence
+
+import "errors"
+
+// Represents the error returned when a key does not exist in a store
+var ErrKeyNotFound = errors.New("key not found")
+
+// An interface for reading key-value pairs from a store
+type Reader interface {
+	// A method that returns the value for a given key.
+	// Returns an error wrapping ErrKeyNotFound if the key does not exist.
+	GetKey(key []byte) ([]byte, error)
+
+	// A method that calls a function for every key-value pair with a given prefix
+	// in order of the keys. The key and value are only valid during the call.
+	// The iteration stops and returns the error if the function returns one.
+	IteratePrefix(prefix []byte, fn func(key, value []byte) error) error
+}
+
+// An interface for writing key-value pairs to a store
+type Writer interface {
+	// A method that sets the value for a given key
+	SetKey(key, value []byte) error
+
+	// A method that deletes the entry for a given key
+	DeleteKey(key []byte) error
+}
+
+// An interface for a set of reads and writes on a store that is committed atomically.
+// Reads on a batch observe the writes that have already been made on the batch.
+type Batch interface {
+	Reader
+	Writer
+}
+
+// An interface for a read-only view of a store at a point in time.
+// A snapshot is not affected by writes made to the store after it
+// was taken and must be discarded when it is no longer needed.
+type Snapshot interface {
+	Reader
+
+	// A method that releases the resources held by the snapshot
+	Discard()
+}
+
+// An interface for a key-value storage backend.
+// Each database bucket of the chain is held in a Store.
+type Store interface {
+	Reader
+	Writer
+
+	// A method that deletes all entries with a given prefix
+	DeleteKeyPrefix(prefix []byte)
+
+	// A method that calls a function with a batch and commits the writes made on it
+	// atomically if the function returns a nil error, otherwise all writes are discarded
+	Batch(fn func(batch Batch) error) error
+
+	// A method that returns a read-only snapshot of the store
+	Snapshot() Snapshot
+
+	// A method that closes the store
+	Close()
+}
@@ -0,0 +1,150 @@
+package persistence
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	legacybadger "github.com/dgraph-io/badger"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Represents the manifest magic version of the legacy (v1) Badger on-disk format
+const legacyManifestVersion = 4
+
+// A function that checks if the bucket in a given directory uses the legacy Badger on-disk format.
+// The MANIFEST of a bucket begins with the magic text 'Bdgr' followed by the format version.
+func islegacybucket(directory string) (bool, error) {
+	// Open the manifest file of the bucket
+	manifest, err := os.Open(filepath.Join(directory, "MANIFEST"))
+	if errors.Is(err, os.ErrNotExist) {
+		// A bucket without a manifest is new
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	// Defer the closing of the manifest file
+	defer manifest.Close()
+
+	// Read the magic bytes of the manifest
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(manifest, magic); err != nil {
+		return false, fmt.Errorf("failed to read bucket manifest! error - %v", err)
+	}
+
+	// Check the magic text and the legacy (4 byte) version
+	return string(magic[:4]) == "Bdgr" && binary.BigEndian.Uint32(magic[4:8]) == legacyManifestVersion, nil
+}
+
+// A function that upgrades the bucket in a given directory from the legacy Badger on-disk
+// format. Every key-value pair of the bucket is copied into a new bucket that replaces the
+// legacy bucket once the copy is complete. An upgrade that was interrupted is restarted.
+// Does nothing if the bucket is not in the legacy format.
+func upgradebucket(directory string) error {
+	// Determine the locations of the new bucket and the legacy bucket backup
+	upgradedir, legacydir := directory+".upgrade", directory+".legacy"
+
+	// Check if an upgrade was interrupted after the legacy bucket was moved
+	if _, err := os.Stat(legacydir); err == nil {
+		if _, err := os.Stat(directory); errors.Is(err, os.ErrNotExist) {
+			// Move the completed upgrade into place
+			if err := os.Rename(upgradedir, directory); err != nil {
+				return fmt.Errorf("failed to complete bucket upgrade! error - %v", err)
+			}
+		}
+
+		// Remove the legacy bucket backup
+		return os.RemoveAll(legacydir)
+	}
+
+	// Check if the bucket is in the legacy format
+	legacy, err := islegacybucket(directory)
+	if err != nil || !legacy {
+		return err
+	}
+
+	// Log the upgrade of the bucket
+	logrus.WithFields(logrus.Fields{"bucket": directory}).Info("upgrading database bucket from the legacy format.")
+
+	// Remove any partial upgrade and copy the bucket into the new format
+	if err := os.RemoveAll(upgradedir); err != nil {
+		return err
+	}
+	if err := copylegacybucket(directory, upgradedir); err != nil {
+		return fmt.Errorf("failed to upgrade bucket! error - %v", err)
+	}
+
+	// Move the legacy bucket out of the way and move the new bucket into place
+	if err := os.Rename(directory, legacydir); err != nil {
+		return err
+	}
+	if err := os.Rename(upgradedir, directory); err != nil {
+		return err
+	}
+
+	// Remove the legacy bucket backup
+	return os.RemoveAll(legacydir)
+}
+
+// A function that copies all key-value pairs from a legacy Badger
+// bucket into a new Badger bucket in a given destination directory
+func copylegacybucket(source, destination string) error {
+	// Open the legacy bucket
+	legacyopts := legacybadger.DefaultOptions(source)
+	legacyopts.Logger = nil
+	legacydb, err := legacybadger.Open(legacyopts)
+	if err != nil {
+		return err
+	}
+	defer legacydb.Close()
+
+	// Open the new bucket
+	opts := badger.DefaultOptions(destination)
+	opts.Logger = nil
+	db, err := badger.Open(opts)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Create a write batch on the new bucket
+	batch := db.NewWriteBatch()
+
+	// Define a view transaction on the legacy bucket
+	err = legacydb.View(func(txn *legacybadger.Txn) error {
+		// Start an iterator over all the items of the legacy bucket
+		dbiterator := txn.NewIterator(legacybadger.DefaultIteratorOptions)
+		defer dbiterator.Close()
+
+		for dbiterator.Rewind(); dbiterator.Valid(); dbiterator.Next() {
+			// Retrieve a copy of the value of the item
+			item := dbiterator.Item()
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			// Add the key-value pair to the write batch
+			if err := batch.Set(item.KeyCopy(nil), value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	// Handle any potential error
+	if err != nil {
+		// Cancel the write batch
+		batch.Cancel()
+		return err
+	}
+
+	// Flush the write batch to the new bucket
+	return batch.Flush()
+}
@@ -193,6 +193,65 @@ func (x *Txn) GetTxndata() []byte {
 	return nil
 }
 
+// A message for Block header data
+type Header struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Hash of the Block
+	Blockhash []byte `protobuf:"bytes,1,opt,name=blockhash,proto3" json:"blockhash,omitempty"`
+	// Data of the Block header
+	// Must decode to a weave.core.HeaderEntry object
+	Headerdata []byte `protobuf:"bytes,2,opt,name=headerdata,proto3" json:"headerdata,omitempty"`
+}
+
+func (x *Header) Reset() {
+	*x = Header{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_entity_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Header) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Header) ProtoMessage() {}
+
+func (x *Header) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_entity_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Header.ProtoReflect.Descriptor instead.
+func (*Header) Descriptor() ([]byte, []int) {
+	return file_protos_entity_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Header) GetBlockhash() []byte {
+	if x != nil {
+		return x.Blockhash
+	}
+	return nil
+}
+
+func (x *Header) GetHeaderdata() []byte {
+	if x != nil {
+		return x.Headerdata
+	}
+	return nil
+}
+
 // A message for the miner configuration values
 type MinerConfig struct {
 	state         protoimpl.MessageState
@@ -213,7 +272,7 @@ type MinerConfig struct {
 func (x *MinerConfig) Reset() {
 	*x = MinerConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_entity_proto_msgTypes[2]
+		mi := &file_protos_entity_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -226,7 +285,7 @@ func (x *MinerConfig) String() string {
 func (*MinerConfig) ProtoMessage() {}
 
 func (x *MinerConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_entity_proto_msgTypes[2]
+	mi := &file_protos_entity_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -239,7 +298,7 @@ func (x *MinerConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MinerConfig.ProtoReflect.Descriptor instead.
 func (*MinerConfig) Descriptor() ([]byte, []int) {
-	return file_protos_entity_proto_rawDescGZIP(), []int{2}
+	return file_protos_entity_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *MinerConfig) GetPoolsize() uint32 {
@@ -283,7 +342,7 @@ type Entity struct {
 func (x *Entity) Reset() {
 	*x = Entity{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_entity_proto_msgTypes[3]
+		mi := &file_protos_entity_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -296,7 +355,7 @@ func (x *Entity) String() string {
 func (*Entity) ProtoMessage() {}
 
 func (x *Entity) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_entity_proto_msgTypes[3]
+	mi := &file_protos_entity_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -309,7 +368,7 @@ func (x *Entity) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Entity.ProtoReflect.Descriptor instead.
 func (*Entity) Descriptor() ([]byte, []int) {
-	return file_protos_entity_proto_rawDescGZIP(), []int{3}
+	return file_protos_entity_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Entity) GetType() Entitytype {
@@ -384,28 +443,32 @@ var file_protos_entity_proto_rawDesc = []byte{
 	0x6e, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x78, 0x6e, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x0c, 0x52, 0x07, 0x74, 0x78, 0x6e, 0x68, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x74,
 	0x78, 0x6e, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x78,
-	0x6e, 0x64, 0x61, 0x74, 0x61, 0x22, 0x61, 0x0a, 0x0b, 0x4d, 0x69, 0x6e, 0x65, 0x72, 0x43, 0x6f,
-	0x6e, 0x66, 0x69, 0x67, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x6f, 0x6c, 0x73, 0x69, 0x7a, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x6f, 0x6f, 0x6c, 0x73, 0x69, 0x7a, 0x65,
-	0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x66, 0x66, 0x69, 0x63, 0x75, 0x6c, 0x74, 0x79, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x69, 0x66, 0x66, 0x69, 0x63, 0x75, 0x6c, 0x74, 0x79,
-	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64, 0x22, 0x9f, 0x01, 0x0a, 0x06, 0x45, 0x6e, 0x74,
-	0x69, 0x74, 0x79, 0x12, 0x1f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x0b, 0x2e, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x74, 0x79, 0x70, 0x65, 0x52, 0x04,
-	0x74, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x05, 0x62,
-	0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x18, 0x0a, 0x03, 0x74, 0x78, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x04, 0x2e, 0x54, 0x78, 0x6e, 0x48, 0x00, 0x52, 0x03, 0x74, 0x78, 0x6e, 0x12, 0x30,
-	0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x65, 0x72, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x4d, 0x69, 0x6e, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x48, 0x00, 0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x65, 0x72, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x42, 0x08, 0x0a, 0x06, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x2a, 0x31, 0x0a, 0x0a, 0x65, 0x6e,
-	0x74, 0x69, 0x74, 0x79, 0x74, 0x79, 0x70, 0x65, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x58, 0x4e, 0x10,
-	0x00, 0x12, 0x09, 0x0a, 0x05, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b,
-	0x4d, 0x49, 0x4e, 0x45, 0x52, 0x43, 0x4f, 0x4e, 0x46, 0x49, 0x47, 0x10, 0x02, 0x42, 0x0a, 0x5a,
-	0x08, 0x2e, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x6e, 0x64, 0x61, 0x74, 0x61, 0x22, 0x46, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12,
+	0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1e, 0x0a,
+	0x0a, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0a, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x64, 0x61, 0x74, 0x61, 0x22, 0x61, 0x0a,
+	0x0b, 0x4d, 0x69, 0x6e, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1a, 0x0a, 0x08,
+	0x70, 0x6f, 0x6f, 0x6c, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
+	0x70, 0x6f, 0x6f, 0x6c, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x66, 0x66,
+	0x69, 0x63, 0x75, 0x6c, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x69,
+	0x66, 0x66, 0x69, 0x63, 0x75, 0x6c, 0x74, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x77, 0x61,
+	0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x72, 0x65, 0x77, 0x61, 0x72, 0x64,
+	0x22, 0x9f, 0x01, 0x0a, 0x06, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x1f, 0x0a, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x65, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x74, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x05,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x18, 0x0a, 0x03,
+	0x74, 0x78, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x54, 0x78, 0x6e, 0x48,
+	0x00, 0x52, 0x03, 0x74, 0x78, 0x6e, 0x12, 0x30, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x65, 0x72, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x4d, 0x69,
+	0x6e, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x48, 0x00, 0x52, 0x0b, 0x6d, 0x69, 0x6e,
+	0x65, 0x72, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x42, 0x08, 0x0a, 0x06, 0x65, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x2a, 0x31, 0x0a, 0x0a, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x07, 0x0a, 0x03, 0x54, 0x58, 0x4e, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x42, 0x4c, 0x4f,
+	0x43, 0x4b, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x49, 0x4e, 0x45, 0x52, 0x43, 0x4f, 0x4e,
+	0x46, 0x49, 0x47, 0x10, 0x02, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -421,19 +484,20 @@ func file_protos_entity_proto_rawDescGZIP() []byte {
 }
 
 var file_protos_entity_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_protos_entity_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_protos_entity_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_protos_entity_proto_goTypes = []interface{}{
 	(Entitytype)(0),     // 0: entitytype
 	(*Block)(nil),       // 1: Block
 	(*Txn)(nil),         // 2: Txn
-	(*MinerConfig)(nil), // 3: MinerConfig
-	(*Entity)(nil),      // 4: Entity
+	(*Header)(nil),      // 3: Header
+	(*MinerConfig)(nil), // 4: MinerConfig
+	(*Entity)(nil),      // 5: Entity
 }
 var file_protos_entity_proto_depIdxs = []int32{
 	0, // 0: Entity.type:type_name -> entitytype
 	1, // 1: Entity.block:type_name -> Block
 	2, // 2: Entity.txn:type_name -> Txn
-	3, // 3: Entity.minerconfig:type_name -> MinerConfig
+	4, // 3: Entity.minerconfig:type_name -> MinerConfig
 	4, // [4:4] is the sub-list for method output_type
 	4, // [4:4] is the sub-list for method input_type
 	4, // [4:4] is the sub-list for extension type_name
@@ -472,7 +536,7 @@ func file_protos_entity_proto_init() {
 			}
 		}
 		file_protos_entity_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MinerConfig); i {
+			switch v := v.(*Header); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -484,6 +548,18 @@ func file_protos_entity_proto_init() {
 			}
 		}
 		file_protos_entity_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MinerConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_entity_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Entity); i {
 			case 0:
 				return &v.state
@@ -496,7 +572,7 @@ func file_protos_entity_proto_init() {
 			}
 		}
 	}
-	file_protos_entity_proto_msgTypes[3].OneofWrappers = []interface{}{
+	file_protos_entity_proto_msgTypes[4].OneofWrappers = []interface{}{
 		(*Entity_Block)(nil),
 		(*Entity_Txn)(nil),
 		(*Entity_Minerconfig)(nil),
@@ -507,7 +583,7 @@ func file_protos_entity_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_protos_entity_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   4,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
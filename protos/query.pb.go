@@ -34,6 +34,9 @@ const (
 	Query_STATE     QueryQuerytype = 2
 	Query_STATUS    QueryQuerytype = 3
 	Query_INVENTORY QueryQuerytype = 4
+	Query_HEADERS   QueryQuerytype = 5
+	Query_PROOF     QueryQuerytype = 6
+	Query_FILTER    QueryQuerytype = 7
 )
 
 // Enum value maps for QueryQuerytype.
@@ -44,6 +47,9 @@ var (
 		2: "STATE",
 		3: "STATUS",
 		4: "INVENTORY",
+		5: "HEADERS",
+		6: "PROOF",
+		7: "FILTER",
 	}
 	QueryQuerytype_value = map[string]int32{
 		"TXN":       0,
@@ -51,6 +57,9 @@ var (
 		"STATE":     2,
 		"STATUS":    3,
 		"INVENTORY": 4,
+		"HEADERS":   5,
+		"PROOF":     6,
+		"FILTER":    7,
 	}
 )
 
@@ -78,7 +87,7 @@ func (x QueryQuerytype) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use QueryQuerytype.Descriptor instead.
 func (QueryQuerytype) EnumDescriptor() ([]byte, []int) {
-	return file_protos_query_proto_rawDescGZIP(), []int{5, 0}
+	return file_protos_query_proto_rawDescGZIP(), []int{8, 0}
 }
 
 // A message for a Block data query
@@ -239,6 +248,9 @@ type InventoryQuery struct {
 	Lastblock uint32 `protobuf:"varint,1,opt,name=lastblock,proto3" json:"lastblock,omitempty"`
 	// Whether to include pool transactions
 	Pooltxns bool `protobuf:"varint,2,opt,name=pooltxns,proto3" json:"pooltxns,omitempty"`
+	// Whether the peer making the request has no blocks. The lastblock
+	// is ignored and the block inventory starts from the genesis block
+	Noblocks bool `protobuf:"varint,3,opt,name=noblocks,proto3" json:"noblocks,omitempty"`
 }
 
 func (x *InventoryQuery) Reset() {
@@ -287,6 +299,13 @@ func (x *InventoryQuery) GetPooltxns() bool {
 	return false
 }
 
+func (x *InventoryQuery) GetNoblocks() bool {
+	if x != nil {
+		return x.Noblocks
+	}
+	return false
+}
+
 // A message for Status data query
 type StatusQuery struct {
 	state         protoimpl.MessageState
@@ -345,6 +364,162 @@ func (x *StatusQuery) GetEntityhash() []byte {
 	return nil
 }
 
+// A message for a Headers data query
+type HeadersQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Height of the first header to include
+	Startheight uint32 `protobuf:"varint,1,opt,name=startheight,proto3" json:"startheight,omitempty"`
+	// Maximum number of headers to include
+	Count uint32 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *HeadersQuery) Reset() {
+	*x = HeadersQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_query_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HeadersQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeadersQuery) ProtoMessage() {}
+
+func (x *HeadersQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_query_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeadersQuery.ProtoReflect.Descriptor instead.
+func (*HeadersQuery) Descriptor() ([]byte, []int) {
+	return file_protos_query_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HeadersQuery) GetStartheight() uint32 {
+	if x != nil {
+		return x.Startheight
+	}
+	return 0
+}
+
+func (x *HeadersQuery) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// A message for a Transaction inclusion proof query
+type ProofQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Hash of the transaction to prove
+	Txnhash []byte `protobuf:"bytes,1,opt,name=txnhash,proto3" json:"txnhash,omitempty"`
+}
+
+func (x *ProofQuery) Reset() {
+	*x = ProofQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_query_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProofQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProofQuery) ProtoMessage() {}
+
+func (x *ProofQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_query_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProofQuery.ProtoReflect.Descriptor instead.
+func (*ProofQuery) Descriptor() ([]byte, []int) {
+	return file_protos_query_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProofQuery) GetTxnhash() []byte {
+	if x != nil {
+		return x.Txnhash
+	}
+	return nil
+}
+
+// A message for a compact block Filter query
+type FilterQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Hash of the block whose filter is requested
+	Blockhash []byte `protobuf:"bytes,1,opt,name=blockhash,proto3" json:"blockhash,omitempty"`
+}
+
+func (x *FilterQuery) Reset() {
+	*x = FilterQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_query_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FilterQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterQuery) ProtoMessage() {}
+
+func (x *FilterQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_query_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterQuery.ProtoReflect.Descriptor instead.
+func (*FilterQuery) Descriptor() ([]byte, []int) {
+	return file_protos_query_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FilterQuery) GetBlockhash() []byte {
+	if x != nil {
+		return x.Blockhash
+	}
+	return nil
+}
+
 // A message for an arbitrary data query
 type Query struct {
 	state         protoimpl.MessageState
@@ -361,13 +536,16 @@ type Query struct {
 	//	*Query_State
 	//	*Query_Status
 	//	*Query_Inventory
+	//	*Query_Headers
+	//	*Query_Proof
+	//	*Query_Filter
 	Body isQuery_Body `protobuf_oneof:"body"`
 }
 
 func (x *Query) Reset() {
 	*x = Query{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_query_proto_msgTypes[5]
+		mi := &file_protos_query_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -380,7 +558,7 @@ func (x *Query) String() string {
 func (*Query) ProtoMessage() {}
 
 func (x *Query) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_query_proto_msgTypes[5]
+	mi := &file_protos_query_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -393,7 +571,7 @@ func (x *Query) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Query.ProtoReflect.Descriptor instead.
 func (*Query) Descriptor() ([]byte, []int) {
-	return file_protos_query_proto_rawDescGZIP(), []int{5}
+	return file_protos_query_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Query) GetType() QueryQuerytype {
@@ -445,6 +623,27 @@ func (x *Query) GetInventory() *InventoryQuery {
 	return nil
 }
 
+func (x *Query) GetHeaders() *HeadersQuery {
+	if x, ok := x.GetBody().(*Query_Headers); ok {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Query) GetProof() *ProofQuery {
+	if x, ok := x.GetBody().(*Query_Proof); ok {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *Query) GetFilter() *FilterQuery {
+	if x, ok := x.GetBody().(*Query_Filter); ok {
+		return x.Filter
+	}
+	return nil
+}
+
 type isQuery_Body interface {
 	isQuery_Body()
 }
@@ -474,6 +673,21 @@ type Query_Inventory struct {
 	Inventory *InventoryQuery `protobuf:"bytes,6,opt,name=inventory,proto3,oneof"`
 }
 
+type Query_Headers struct {
+	// Type must be HEADERS
+	Headers *HeadersQuery `protobuf:"bytes,7,opt,name=headers,proto3,oneof"`
+}
+
+type Query_Proof struct {
+	// Type must be PROOF
+	Proof *ProofQuery `protobuf:"bytes,8,opt,name=proof,proto3,oneof"`
+}
+
+type Query_Filter struct {
+	// Type must be FILTER
+	Filter *FilterQuery `protobuf:"bytes,9,opt,name=filter,proto3,oneof"`
+}
+
 func (*Query_Txn) isQuery_Body() {}
 
 func (*Query_Block) isQuery_Body() {}
@@ -484,6 +698,12 @@ func (*Query_Status) isQuery_Body() {}
 
 func (*Query_Inventory) isQuery_Body() {}
 
+func (*Query_Headers) isQuery_Body() {}
+
+func (*Query_Proof) isQuery_Body() {}
+
+func (*Query_Filter) isQuery_Body() {}
+
 var File_protos_query_proto protoreflect.FileDescriptor
 
 var file_protos_query_proto_rawDesc = []byte{
@@ -497,38 +717,60 @@ var file_protos_query_proto_rawDesc = []byte{
 	0x28, 0x0c, 0x52, 0x07, 0x74, 0x78, 0x6e, 0x68, 0x61, 0x73, 0x68, 0x22, 0x2e, 0x0a, 0x0a, 0x53,
 	0x74, 0x61, 0x74, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x69, 0x6e,
 	0x65, 0x72, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b,
-	0x6d, 0x69, 0x6e, 0x65, 0x72, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x4a, 0x0a, 0x0e, 0x49,
+	0x6d, 0x69, 0x6e, 0x65, 0x72, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x66, 0x0a, 0x0e, 0x49,
 	0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1c, 0x0a,
 	0x09, 0x6c, 0x61, 0x73, 0x74, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
 	0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x70,
 	0x6f, 0x6f, 0x6c, 0x74, 0x78, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x70,
-	0x6f, 0x6f, 0x6c, 0x74, 0x78, 0x6e, 0x73, 0x22, 0x4e, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x74, 0x79, 0x70,
-	0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x6e, 0x74, 0x69, 0x74,
-	0x79, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x65, 0x6e, 0x74,
-	0x69, 0x74, 0x79, 0x68, 0x61, 0x73, 0x68, 0x22, 0xbe, 0x02, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72,
-	0x79, 0x12, 0x24, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x10, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x74, 0x79, 0x70,
-	0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x03, 0x74, 0x78, 0x6e, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x54, 0x78, 0x6e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48,
-	0x00, 0x52, 0x03, 0x74, 0x78, 0x6e, 0x12, 0x23, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x51, 0x75, 0x65,
-	0x72, 0x79, 0x48, 0x00, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x23, 0x0a, 0x05, 0x73,
-	0x74, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x53, 0x74, 0x61,
-	0x74, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x00, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65,
-	0x12, 0x26, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0c, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x00,
-	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2f, 0x0a, 0x09, 0x69, 0x6e, 0x76, 0x65,
-	0x6e, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x49, 0x6e,
-	0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x00, 0x52, 0x09,
-	0x69, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x22, 0x45, 0x0a, 0x09, 0x71, 0x75, 0x65,
-	0x72, 0x79, 0x74, 0x79, 0x70, 0x65, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x58, 0x4e, 0x10, 0x00, 0x12,
-	0x09, 0x0a, 0x05, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x53, 0x54,
-	0x41, 0x54, 0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x10,
-	0x03, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x4e, 0x56, 0x45, 0x4e, 0x54, 0x4f, 0x52, 0x59, 0x10, 0x04,
-	0x42, 0x06, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x3b, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x6f, 0x6c, 0x74, 0x78, 0x6e, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x6f, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6e, 0x6f, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x22, 0x4e, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x12, 0x1f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0b, 0x2e, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x74, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x68, 0x61, 0x73,
+	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x68,
+	0x61, 0x73, 0x68, 0x22, 0x46, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x68, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x26, 0x0a, 0x0a, 0x50,
+	0x72, 0x6f, 0x6f, 0x66, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x78, 0x6e,
+	0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x78, 0x6e, 0x68,
+	0x61, 0x73, 0x68, 0x22, 0x2b, 0x0a, 0x0b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68,
+	0x22, 0xda, 0x03, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x24, 0x0a, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x2e, 0x71, 0x75, 0x65, 0x72, 0x79, 0x74, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x1d, 0x0a, 0x03, 0x74, 0x78, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e,
+	0x54, 0x78, 0x6e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x00, 0x52, 0x03, 0x74, 0x78, 0x6e, 0x12,
+	0x23, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x00, 0x52, 0x05, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x23, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x48, 0x00, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x26, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x00, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x2f, 0x0a, 0x09, 0x69, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x49, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x00, 0x52, 0x09, 0x69, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f,
+	0x72, 0x79, 0x12, 0x29, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x48, 0x00, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x23, 0x0a,
+	0x05, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x50,
+	0x72, 0x6f, 0x6f, 0x66, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x00, 0x52, 0x05, 0x70, 0x72, 0x6f,
+	0x6f, 0x66, 0x12, 0x26, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x48, 0x00, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x22, 0x69, 0x0a, 0x09, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x74, 0x79, 0x70, 0x65, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x58, 0x4e, 0x10, 0x00,
+	0x12, 0x09, 0x0a, 0x05, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x53,
+	0x54, 0x41, 0x54, 0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
+	0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x4e, 0x56, 0x45, 0x4e, 0x54, 0x4f, 0x52, 0x59, 0x10,
+	0x04, 0x12, 0x0b, 0x0a, 0x07, 0x48, 0x45, 0x41, 0x44, 0x45, 0x52, 0x53, 0x10, 0x05, 0x12, 0x09,
+	0x0a, 0x05, 0x50, 0x52, 0x4f, 0x4f, 0x46, 0x10, 0x06, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x49, 0x4c,
+	0x54, 0x45, 0x52, 0x10, 0x07, 0x42, 0x06, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x42, 0x0a, 0x5a,
+	0x08, 0x2e, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -544,7 +786,7 @@ func file_protos_query_proto_rawDescGZIP() []byte {
 }
 
 var file_protos_query_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_protos_query_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_protos_query_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_protos_query_proto_goTypes = []interface{}{
 	(QueryQuerytype)(0),    // 0: Query.querytype
 	(*BlockQuery)(nil),     // 1: BlockQuery
@@ -552,22 +794,28 @@ var file_protos_query_proto_goTypes = []interface{}{
 	(*StateQuery)(nil),     // 3: StateQuery
 	(*InventoryQuery)(nil), // 4: InventoryQuery
 	(*StatusQuery)(nil),    // 5: StatusQuery
-	(*Query)(nil),          // 6: Query
-	(Entitytype)(0),        // 7: entitytype
+	(*HeadersQuery)(nil),   // 6: HeadersQuery
+	(*ProofQuery)(nil),     // 7: ProofQuery
+	(*FilterQuery)(nil),    // 8: FilterQuery
+	(*Query)(nil),          // 9: Query
+	(Entitytype)(0),        // 10: entitytype
 }
 var file_protos_query_proto_depIdxs = []int32{
-	7, // 0: StatusQuery.type:type_name -> entitytype
-	0, // 1: Query.type:type_name -> Query.querytype
-	2, // 2: Query.txn:type_name -> TxnQuery
-	1, // 3: Query.block:type_name -> BlockQuery
-	3, // 4: Query.state:type_name -> StateQuery
-	5, // 5: Query.status:type_name -> StatusQuery
-	4, // 6: Query.inventory:type_name -> InventoryQuery
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	10, // 0: StatusQuery.type:type_name -> entitytype
+	0,  // 1: Query.type:type_name -> Query.querytype
+	2,  // 2: Query.txn:type_name -> TxnQuery
+	1,  // 3: Query.block:type_name -> BlockQuery
+	3,  // 4: Query.state:type_name -> StateQuery
+	5,  // 5: Query.status:type_name -> StatusQuery
+	4,  // 6: Query.inventory:type_name -> InventoryQuery
+	6,  // 7: Query.headers:type_name -> HeadersQuery
+	7,  // 8: Query.proof:type_name -> ProofQuery
+	8,  // 9: Query.filter:type_name -> FilterQuery
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_protos_query_proto_init() }
@@ -638,6 +886,42 @@ func file_protos_query_proto_init() {
 			}
 		}
 		file_protos_query_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HeadersQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_query_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProofQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_query_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FilterQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_query_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Query); i {
 			case 0:
 				return &v.state
@@ -650,12 +934,15 @@ func file_protos_query_proto_init() {
 			}
 		}
 	}
-	file_protos_query_proto_msgTypes[5].OneofWrappers = []interface{}{
+	file_protos_query_proto_msgTypes[8].OneofWrappers = []interface{}{
 		(*Query_Txn)(nil),
 		(*Query_Block)(nil),
 		(*Query_State)(nil),
 		(*Query_Status)(nil),
 		(*Query_Inventory)(nil),
+		(*Query_Headers)(nil),
+		(*Query_Proof)(nil),
+		(*Query_Filter)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -663,7 +950,7 @@ func file_protos_query_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_protos_query_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   6,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -34,6 +34,9 @@ const (
 	Response_STATE     ResponseResponsetype = 2
 	Response_STATUS    ResponseResponsetype = 3
 	Response_INVENTORY ResponseResponsetype = 4
+	Response_HEADERS   ResponseResponsetype = 5
+	Response_PROOF     ResponseResponsetype = 6
+	Response_FILTER    ResponseResponsetype = 7
 )
 
 // Enum value maps for ResponseResponsetype.
@@ -44,6 +47,9 @@ var (
 		2: "STATE",
 		3: "STATUS",
 		4: "INVENTORY",
+		5: "HEADERS",
+		6: "PROOF",
+		7: "FILTER",
 	}
 	ResponseResponsetype_value = map[string]int32{
 		"TXN":       0,
@@ -51,6 +57,9 @@ var (
 		"STATE":     2,
 		"STATUS":    3,
 		"INVENTORY": 4,
+		"HEADERS":   5,
+		"PROOF":     6,
+		"FILTER":    7,
 	}
 )
 
@@ -78,7 +87,7 @@ func (x ResponseResponsetype) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ResponseResponsetype.Descriptor instead.
 func (ResponseResponsetype) EnumDescriptor() ([]byte, []int) {
-	return file_protos_response_proto_rawDescGZIP(), []int{5, 0}
+	return file_protos_response_proto_rawDescGZIP(), []int{8, 0}
 }
 
 // A message for a Block query response
@@ -189,6 +198,10 @@ type StateResponse struct {
 	Chainheight uint32 `protobuf:"varint,1,opt,name=chainheight,proto3" json:"chainheight,omitempty"`
 	// Miner configuration state of the peer that sent the response
 	Minerconfig *MinerConfig `protobuf:"bytes,2,opt,name=minerconfig,proto3" json:"minerconfig,omitempty"`
+	// Whether the peer that sent the response has pruned old block bodies
+	Pruned bool `protobuf:"varint,3,opt,name=pruned,proto3" json:"pruned,omitempty"`
+	// Height of the lowest block with a body on the peer that sent the response
+	Prunedheight uint32 `protobuf:"varint,4,opt,name=prunedheight,proto3" json:"prunedheight,omitempty"`
 }
 
 func (x *StateResponse) Reset() {
@@ -237,6 +250,20 @@ func (x *StateResponse) GetMinerconfig() *MinerConfig {
 	return nil
 }
 
+func (x *StateResponse) GetPruned() bool {
+	if x != nil {
+		return x.Pruned
+	}
+	return false
+}
+
+func (x *StateResponse) GetPrunedheight() uint32 {
+	if x != nil {
+		return x.Prunedheight
+	}
+	return 0
+}
+
 // A message for an Inventory query response
 type InventoryResponse struct {
 	state         protoimpl.MessageState
@@ -365,6 +392,183 @@ func (x *StatusResponse) GetStatus() string {
 	return ""
 }
 
+// A message for a Headers query response
+type HeadersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// List of headers on the chain of the peer that sent the response,
+	// in order of height from the start height (taken from query).
+	Headers []*Header `protobuf:"bytes,1,rep,name=headers,proto3" json:"headers,omitempty"`
+}
+
+func (x *HeadersResponse) Reset() {
+	*x = HeadersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_response_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HeadersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeadersResponse) ProtoMessage() {}
+
+func (x *HeadersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_response_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeadersResponse.ProtoReflect.Descriptor instead.
+func (*HeadersResponse) Descriptor() ([]byte, []int) {
+	return file_protos_response_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HeadersResponse) GetHeaders() []*Header {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+// A message for a Transaction inclusion proof query response
+type ProofResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Response transaction data
+	Txn *Txn `protobuf:"bytes,1,opt,name=txn,proto3" json:"txn,omitempty"`
+	// Hash of the block that includes the transaction
+	Blockhash []byte `protobuf:"bytes,2,opt,name=blockhash,proto3" json:"blockhash,omitempty"`
+	// Data of the inclusion proof
+	// Must decode to a weave.merkle.MerkleProof object
+	Proofdata []byte `protobuf:"bytes,3,opt,name=proofdata,proto3" json:"proofdata,omitempty"`
+}
+
+func (x *ProofResponse) Reset() {
+	*x = ProofResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_response_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProofResponse) ProtoMessage() {}
+
+func (x *ProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_response_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProofResponse.ProtoReflect.Descriptor instead.
+func (*ProofResponse) Descriptor() ([]byte, []int) {
+	return file_protos_response_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProofResponse) GetTxn() *Txn {
+	if x != nil {
+		return x.Txn
+	}
+	return nil
+}
+
+func (x *ProofResponse) GetBlockhash() []byte {
+	if x != nil {
+		return x.Blockhash
+	}
+	return nil
+}
+
+func (x *ProofResponse) GetProofdata() []byte {
+	if x != nil {
+		return x.Proofdata
+	}
+	return nil
+}
+
+// A message for a compact block Filter query response
+type FilterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Hash of the block that the filter is for
+	Blockhash []byte `protobuf:"bytes,1,opt,name=blockhash,proto3" json:"blockhash,omitempty"`
+	// Data of the compact block filter
+	// Must decode to a weave.filter.Filter object
+	Filterdata []byte `protobuf:"bytes,2,opt,name=filterdata,proto3" json:"filterdata,omitempty"`
+}
+
+func (x *FilterResponse) Reset() {
+	*x = FilterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_response_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FilterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterResponse) ProtoMessage() {}
+
+func (x *FilterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_response_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterResponse.ProtoReflect.Descriptor instead.
+func (*FilterResponse) Descriptor() ([]byte, []int) {
+	return file_protos_response_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FilterResponse) GetBlockhash() []byte {
+	if x != nil {
+		return x.Blockhash
+	}
+	return nil
+}
+
+func (x *FilterResponse) GetFilterdata() []byte {
+	if x != nil {
+		return x.Filterdata
+	}
+	return nil
+}
+
 // A message for an arbitrary query response
 type Response struct {
 	state         protoimpl.MessageState
@@ -381,13 +585,16 @@ type Response struct {
 	//	*Response_Stateresponse
 	//	*Response_Statusresponse
 	//	*Response_Inventoryresponse
+	//	*Response_Headersresponse
+	//	*Response_Proofresponse
+	//	*Response_Filterresponse
 	Body isResponse_Body `protobuf_oneof:"body"`
 }
 
 func (x *Response) Reset() {
 	*x = Response{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_response_proto_msgTypes[5]
+		mi := &file_protos_response_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -400,7 +607,7 @@ func (x *Response) String() string {
 func (*Response) ProtoMessage() {}
 
 func (x *Response) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_response_proto_msgTypes[5]
+	mi := &file_protos_response_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -413,7 +620,7 @@ func (x *Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Response.ProtoReflect.Descriptor instead.
 func (*Response) Descriptor() ([]byte, []int) {
-	return file_protos_response_proto_rawDescGZIP(), []int{5}
+	return file_protos_response_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Response) GetType() ResponseResponsetype {
@@ -465,6 +672,27 @@ func (x *Response) GetInventoryresponse() *InventoryResponse {
 	return nil
 }
 
+func (x *Response) GetHeadersresponse() *HeadersResponse {
+	if x, ok := x.GetBody().(*Response_Headersresponse); ok {
+		return x.Headersresponse
+	}
+	return nil
+}
+
+func (x *Response) GetProofresponse() *ProofResponse {
+	if x, ok := x.GetBody().(*Response_Proofresponse); ok {
+		return x.Proofresponse
+	}
+	return nil
+}
+
+func (x *Response) GetFilterresponse() *FilterResponse {
+	if x, ok := x.GetBody().(*Response_Filterresponse); ok {
+		return x.Filterresponse
+	}
+	return nil
+}
+
 type isResponse_Body interface {
 	isResponse_Body()
 }
@@ -494,6 +722,21 @@ type Response_Inventoryresponse struct {
 	Inventoryresponse *InventoryResponse `protobuf:"bytes,6,opt,name=inventoryresponse,proto3,oneof"`
 }
 
+type Response_Headersresponse struct {
+	// Type must be HEADERS
+	Headersresponse *HeadersResponse `protobuf:"bytes,7,opt,name=headersresponse,proto3,oneof"`
+}
+
+type Response_Proofresponse struct {
+	// Type must be PROOF
+	Proofresponse *ProofResponse `protobuf:"bytes,8,opt,name=proofresponse,proto3,oneof"`
+}
+
+type Response_Filterresponse struct {
+	// Type must be FILTER
+	Filterresponse *FilterResponse `protobuf:"bytes,9,opt,name=filterresponse,proto3,oneof"`
+}
+
 func (*Response_Txnresponse) isResponse_Body() {}
 
 func (*Response_Blockresponse) isResponse_Body() {}
@@ -504,6 +747,12 @@ func (*Response_Statusresponse) isResponse_Body() {}
 
 func (*Response_Inventoryresponse) isResponse_Body() {}
 
+func (*Response_Headersresponse) isResponse_Body() {}
+
+func (*Response_Proofresponse) isResponse_Body() {}
+
+func (*Response_Filterresponse) isResponse_Body() {}
+
 var File_protos_response_proto protoreflect.FileDescriptor
 
 var file_protos_response_proto_rawDesc = []byte{
@@ -515,54 +764,85 @@ var file_protos_response_proto_rawDesc = []byte{
 	0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x25, 0x0a, 0x0b, 0x54,
 	0x78, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x03, 0x74, 0x78,
 	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x54, 0x78, 0x6e, 0x52, 0x03, 0x74,
-	0x78, 0x6e, 0x22, 0x61, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x68, 0x65, 0x69, 0x67,
-	0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x68,
-	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x2e, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x65, 0x72, 0x63, 0x6f,
-	0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x4d, 0x69, 0x6e,
-	0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x65, 0x72, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x5f, 0x0a, 0x11, 0x49, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f,
-	0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x0b, 0x63, 0x68,
-	0x61, 0x69, 0x6e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x06, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x62, 0x6c,
-	0x6f, 0x63, 0x6b, 0x73, 0x12, 0x20, 0x0a, 0x08, 0x70, 0x6f, 0x6f, 0x6c, 0x74, 0x78, 0x6e, 0x73,
-	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x54, 0x78, 0x6e, 0x52, 0x08, 0x70, 0x6f,
-	0x6f, 0x6c, 0x74, 0x78, 0x6e, 0x73, 0x22, 0x69, 0x0a, 0x0e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x74,
-	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x6e, 0x74,
-	0x69, 0x74, 0x79, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x65,
-	0x6e, 0x74, 0x69, 0x74, 0x79, 0x68, 0x61, 0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x22, 0xa9, 0x03, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a,
-	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x74, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x30, 0x0a, 0x0b, 0x74, 0x78,
-	0x6e, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x0c, 0x2e, 0x54, 0x78, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52,
-	0x0b, 0x74, 0x78, 0x6e, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0d,
-	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0d, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x72, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x74, 0x65, 0x72, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x53, 0x74,
-	0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0d, 0x73,
-	0x74, 0x61, 0x74, 0x65, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x0e,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0e, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x72,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x11, 0x69, 0x6e, 0x76, 0x65, 0x6e,
-	0x74, 0x6f, 0x72, 0x79, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x49, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x11, 0x69, 0x6e, 0x76, 0x65, 0x6e, 0x74,
-	0x6f, 0x72, 0x79, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x48, 0x0a, 0x0c, 0x72,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x74, 0x79, 0x70, 0x65, 0x12, 0x07, 0x0a, 0x03, 0x54,
-	0x58, 0x4e, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x10, 0x01, 0x12,
-	0x09, 0x0a, 0x05, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54,
-	0x41, 0x54, 0x55, 0x53, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x4e, 0x56, 0x45, 0x4e, 0x54,
-	0x4f, 0x52, 0x59, 0x10, 0x04, 0x42, 0x06, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x42, 0x0a, 0x5a,
-	0x08, 0x2e, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x78, 0x6e, 0x22, 0x9d, 0x01, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x68, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x69, 0x6e,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x2e, 0x0a, 0x0b, 0x6d, 0x69, 0x6e, 0x65, 0x72, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x4d, 0x69,
+	0x6e, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x65, 0x72,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x75, 0x6e, 0x65, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x72, 0x75, 0x6e, 0x65, 0x64, 0x12, 0x22,
+	0x0a, 0x0c, 0x70, 0x72, 0x75, 0x6e, 0x65, 0x64, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x70, 0x72, 0x75, 0x6e, 0x65, 0x64, 0x68, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x22, 0x5f, 0x0a, 0x11, 0x49, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x0b, 0x63, 0x68, 0x61, 0x69, 0x6e,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x73, 0x12, 0x20, 0x0a, 0x08, 0x70, 0x6f, 0x6f, 0x6c, 0x74, 0x78, 0x6e, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x54, 0x78, 0x6e, 0x52, 0x08, 0x70, 0x6f, 0x6f, 0x6c, 0x74,
+	0x78, 0x6e, 0x73, 0x22, 0x69, 0x0a, 0x0e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x74, 0x79, 0x70, 0x65,
+	0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79,
+	0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x65, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x68, 0x61, 0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x34,
+	0x0a, 0x0f, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x21, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x07, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x07, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x73, 0x22, 0x63, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x03, 0x74, 0x78, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x04, 0x2e, 0x54, 0x78, 0x6e, 0x52, 0x03, 0x74, 0x78, 0x6e, 0x12, 0x1c, 0x0a,
+	0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x70,
+	0x72, 0x6f, 0x6f, 0x66, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x70, 0x72, 0x6f, 0x6f, 0x66, 0x64, 0x61, 0x74, 0x61, 0x22, 0x4e, 0x0a, 0x0e, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x66, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x66,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x64, 0x61, 0x74, 0x61, 0x22, 0xfe, 0x04, 0x0a, 0x08, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e,
+	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x74, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x30, 0x0a, 0x0b, 0x74, 0x78, 0x6e, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x54, 0x78, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0b, 0x74, 0x78, 0x6e, 0x72, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0d, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x72, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0d, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0d,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0d, 0x73, 0x74, 0x61, 0x74, 0x65, 0x72, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x0e, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x72, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52,
+	0x0e, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x42, 0x0a, 0x11, 0x69, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x72, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x49, 0x6e, 0x76,
+	0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00,
+	0x52, 0x11, 0x69, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x72, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x0f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x72, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x48,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00,
+	0x52, 0x0f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x36, 0x0a, 0x0d, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x50, 0x72, 0x6f, 0x6f, 0x66,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x6f,
+	0x66, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x0e, 0x66, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x48, 0x00, 0x52, 0x0e, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x72, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x6c, 0x0a, 0x0c, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x74, 0x79, 0x70, 0x65, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x58, 0x4e, 0x10, 0x00, 0x12, 0x09, 0x0a,
+	0x05, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x53, 0x54, 0x41, 0x54,
+	0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x10, 0x03, 0x12,
+	0x0d, 0x0a, 0x09, 0x49, 0x4e, 0x56, 0x45, 0x4e, 0x54, 0x4f, 0x52, 0x59, 0x10, 0x04, 0x12, 0x0b,
+	0x0a, 0x07, 0x48, 0x45, 0x41, 0x44, 0x45, 0x52, 0x53, 0x10, 0x05, 0x12, 0x09, 0x0a, 0x05, 0x50,
+	0x52, 0x4f, 0x4f, 0x46, 0x10, 0x06, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52,
+	0x10, 0x07, 0x42, 0x06, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x3b,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -578,7 +858,7 @@ func file_protos_response_proto_rawDescGZIP() []byte {
 }
 
 var file_protos_response_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_protos_response_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_protos_response_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_protos_response_proto_goTypes = []interface{}{
 	(ResponseResponsetype)(0), // 0: Response.responsetype
 	(*BlockResponse)(nil),     // 1: BlockResponse
@@ -586,30 +866,39 @@ var file_protos_response_proto_goTypes = []interface{}{
 	(*StateResponse)(nil),     // 3: StateResponse
 	(*InventoryResponse)(nil), // 4: InventoryResponse
 	(*StatusResponse)(nil),    // 5: StatusResponse
-	(*Response)(nil),          // 6: Response
-	(*Block)(nil),             // 7: Block
-	(*Txn)(nil),               // 8: Txn
-	(*MinerConfig)(nil),       // 9: MinerConfig
-	(Entitytype)(0),           // 10: entitytype
+	(*HeadersResponse)(nil),   // 6: HeadersResponse
+	(*ProofResponse)(nil),     // 7: ProofResponse
+	(*FilterResponse)(nil),    // 8: FilterResponse
+	(*Response)(nil),          // 9: Response
+	(*Block)(nil),             // 10: Block
+	(*Txn)(nil),               // 11: Txn
+	(*MinerConfig)(nil),       // 12: MinerConfig
+	(Entitytype)(0),           // 13: entitytype
+	(*Header)(nil),            // 14: Header
 }
 var file_protos_response_proto_depIdxs = []int32{
-	7,  // 0: BlockResponse.block:type_name -> Block
-	8,  // 1: TxnResponse.txn:type_name -> Txn
-	9,  // 2: StateResponse.minerconfig:type_name -> MinerConfig
-	7,  // 3: InventoryResponse.chainblocks:type_name -> Block
-	8,  // 4: InventoryResponse.pooltxns:type_name -> Txn
-	10, // 5: StatusResponse.type:type_name -> entitytype
-	0,  // 6: Response.type:type_name -> Response.responsetype
-	2,  // 7: Response.txnresponse:type_name -> TxnResponse
-	1,  // 8: Response.blockresponse:type_name -> BlockResponse
-	3,  // 9: Response.stateresponse:type_name -> StateResponse
-	5,  // 10: Response.statusresponse:type_name -> StatusResponse
-	4,  // 11: Response.inventoryresponse:type_name -> InventoryResponse
-	12, // [12:12] is the sub-list for method output_type
-	12, // [12:12] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	10, // 0: BlockResponse.block:type_name -> Block
+	11, // 1: TxnResponse.txn:type_name -> Txn
+	12, // 2: StateResponse.minerconfig:type_name -> MinerConfig
+	10, // 3: InventoryResponse.chainblocks:type_name -> Block
+	11, // 4: InventoryResponse.pooltxns:type_name -> Txn
+	13, // 5: StatusResponse.type:type_name -> entitytype
+	14, // 6: HeadersResponse.headers:type_name -> Header
+	11, // 7: ProofResponse.txn:type_name -> Txn
+	0,  // 8: Response.type:type_name -> Response.responsetype
+	2,  // 9: Response.txnresponse:type_name -> TxnResponse
+	1,  // 10: Response.blockresponse:type_name -> BlockResponse
+	3,  // 11: Response.stateresponse:type_name -> StateResponse
+	5,  // 12: Response.statusresponse:type_name -> StatusResponse
+	4,  // 13: Response.inventoryresponse:type_name -> InventoryResponse
+	6,  // 14: Response.headersresponse:type_name -> HeadersResponse
+	7,  // 15: Response.proofresponse:type_name -> ProofResponse
+	8,  // 16: Response.filterresponse:type_name -> FilterResponse
+	17, // [17:17] is the sub-list for method output_type
+	17, // [17:17] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_protos_response_proto_init() }
@@ -680,6 +969,42 @@ func file_protos_response_proto_init() {
 			}
 		}
 		file_protos_response_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HeadersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_response_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProofResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_response_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FilterResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_response_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Response); i {
 			case 0:
 				return &v.state
@@ -692,12 +1017,15 @@ func file_protos_response_proto_init() {
 			}
 		}
 	}
-	file_protos_response_proto_msgTypes[5].OneofWrappers = []interface{}{
+	file_protos_response_proto_msgTypes[8].OneofWrappers = []interface{}{
 		(*Response_Txnresponse)(nil),
 		(*Response_Blockresponse)(nil),
 		(*Response_Stateresponse)(nil),
 		(*Response_Statusresponse)(nil),
 		(*Response_Inventoryresponse)(nil),
+		(*Response_Headersresponse)(nil),
+		(*Response_Proofresponse)(nil),
+		(*Response_Filterresponse)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -705,7 +1033,7 @@ func file_protos_response_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_protos_response_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   6,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
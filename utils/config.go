@@ -20,6 +20,26 @@ var (
 	ChainHeadKey = []byte("chainhead")
 	// Represents the key used for storing the chain height
 	ChainHeightKey = []byte("chainheight")
+	// Represents the prefix key used for header history mmr node keys
+	MMRprefix = []byte("mmr-")
+	// Represents the key used for storing the number of header history mmr leaves
+	MMRLeavesKey = []byte("mmrleaves")
+	// Represents the prefix key used for block filter keys
+	Filterprefix = []byte("filter-")
+	// Represents the prefix key used for transaction index keys
+	Txnprefix = []byte("txn-")
+	// Represents the prefix key used for block header keys
+	Headerprefix = []byte("header-")
+	// Represents the prefix key used for block body keys
+	Bodyprefix = []byte("body-")
+	// Represents the key used for storing the number of blocks whose filters and transactions are indexed
+	IndexedHeightKey = []byte("indexedheight")
+	// Represents the key used for storing the height of the lowest unpruned block body
+	PrunedHeightKey = []byte("prunedheight")
+	// Represents the key used for storing the schema version of a database bucket
+	SchemaVersionKey = []byte("schemaversion")
+	// Represents the key that marks a utxo snapshot that is being loaded
+	SnapshotPendingKey = []byte("snapshotpending")
 )
 
 // A struct that represents the contents of the config file.
@@ -28,6 +48,14 @@ type Config struct {
 	JBOK jbokconfig `json:"jbok"`
 	// Represents the database configuration
 	DB dbconfig `json:"db"`
+	// Represents the transaction pool configuration
+	Pool poolconfig `json:"pool"`
+	// Represents the miner configuration
+	Miner minerconfig `json:"miner"`
+	// Represents the network configuration
+	Network networkconfig `json:"network"`
+	// Represents the light node configuration
+	Light lightconfig `json:"light"`
 }
 
 // A struct that represents a jbok configuration
@@ -46,6 +74,50 @@ type dbconfig struct {
 	State bucketconfig `json:"state"`
 	// Represents the configuration of the Blocks bucket
 	Blocks bucketconfig `json:"blocks"`
+	// Represents the configuration of the Headers bucket (light nodes)
+	Headers bucketconfig `json:"headers"`
+	// Represents the configuration of the Index bucket
+	Index bucketconfig `json:"index"`
+	// Represents the number of most recent block bodies kept when pruning (0 keeps all)
+	PruneDepth int `json:"prunedepth"`
+	// Represents the fraction of stale data in a value log file before it is garbage collected
+	GCRatio float64 `json:"gcratio"`
+	// Represents the interval between database bucket garbage collections in minutes
+	GCInterval int `json:"gcinterval"`
+}
+
+// A struct that represents a transaction pool configuration
+type poolconfig struct {
+	// Represents the path to the file that the transaction pool is saved to
+	File string `json:"file"`
+	// Represents the maximum number of transactions in the pool
+	Size uint `json:"size"`
+	// Represents the interval between saves of the transaction pool in minutes
+	SaveInterval int `json:"saveinterval"`
+}
+
+// A struct that represents a miner configuration
+type minerconfig struct {
+	// Represents the maximum number of pool transactions included in a block
+	PoolSize int `json:"poolsize"`
+	// Represents the maximum combined size of the transactions of a block in bytes
+	BlockSize int `json:"blocksize"`
+	// Represents the local address that work is served on to external miners (empty to disable)
+	WorkAddress string `json:"workaddress"`
+}
+
+// A struct that represents a network configuration
+type networkconfig struct {
+	// Represents the multiaddress that the network host listens on
+	ListenAddr string `json:"listenaddr"`
+}
+
+// A struct that represents a light node configuration
+type lightconfig struct {
+	// Represents the multiaddresses (with peer IDs) of the full nodes that headers are synced from
+	Peers []string `json:"peers"`
+	// Represents the interval between header syncs in seconds
+	SyncInterval int `json:"syncinterval"`
 }
 
 // A struct that represents a database bucket configuration
@@ -135,6 +207,33 @@ func GenerateConfigFile(write bool) *Config {
 				File:      filepath.Join(configdir, "db", "blocks", "MANIFEST"),
 				Directory: filepath.Join(configdir, "db", "blocks"),
 			},
+			Headers: bucketconfig{
+				File:      filepath.Join(configdir, "db", "headers", "MANIFEST"),
+				Directory: filepath.Join(configdir, "db", "headers"),
+			},
+			Index: bucketconfig{
+				File:      filepath.Join(configdir, "db", "index", "MANIFEST"),
+				Directory: filepath.Join(configdir, "db", "index"),
+			},
+			GCRatio:    0.5,
+			GCInterval: 10,
+		},
+		Pool: poolconfig{
+			File:         filepath.Join(configdir, "mempool.data"),
+			Size:         5000,
+			SaveInterval: 5,
+		},
+		Miner: minerconfig{
+			PoolSize:    1000,
+			BlockSize:   1 << 20,
+			WorkAddress: "127.0.0.1:7015",
+		},
+		Network: networkconfig{
+			ListenAddr: "/ip4/0.0.0.0/tcp/7016",
+		},
+		Light: lightconfig{
+			Peers:        []string{},
+			SyncInterval: 30,
 		},
 	}
 
@@ -203,10 +302,35 @@ func (config *Config) PrintConfigFile() {
 	fmt.Printf("DB State Directory: %v\n", config.DB.State.Directory)
 	fmt.Printf("DB Blocks File: %v\n", config.DB.Blocks.File)
 	fmt.Printf("DB Blocks Directory: %v\n", config.DB.Blocks.Directory)
+	fmt.Printf("DB Headers File: %v\n", config.DB.Headers.File)
+	fmt.Printf("DB Headers Directory: %v\n", config.DB.Headers.Directory)
+	fmt.Printf("DB Index File: %v\n", config.DB.Index.File)
+	fmt.Printf("DB Index Directory: %v\n", config.DB.Index.Directory)
+	fmt.Printf("DB Prune Depth: %v\n", config.DB.PruneDepth)
+	fmt.Printf("DB GC Ratio: %v\n", config.DB.GCRatio)
+	fmt.Printf("DB GC Interval: %v minutes\n", config.DB.GCInterval)
+	fmt.Println()
+
+	fmt.Println("----Pool-Configuration----")
+	fmt.Printf("Pool File: %v\n", config.Pool.File)
+	fmt.Printf("Pool Size: %v\n", config.Pool.Size)
+	fmt.Printf("Pool Save Interval: %v minutes\n", config.Pool.SaveInterval)
 	fmt.Println()
 
-	// fmt.Println("----Network-Configuration----")
-	// fmt.Println()
+	fmt.Println("----Miner-Configuration----")
+	fmt.Printf("Miner Pool Size: %v\n", config.Miner.PoolSize)
+	fmt.Printf("Miner Block Size: %v bytes\n", config.Miner.BlockSize)
+	fmt.Printf("Miner Work Address: %v\n", config.Miner.WorkAddress)
+	fmt.Println()
+
+	fmt.Println("----Network-Configuration----")
+	fmt.Printf("Network Listen Address: %v\n", config.Network.ListenAddr)
+	fmt.Println()
+
+	fmt.Println("----Light-Configuration----")
+	fmt.Printf("Light Peers: %v\n", config.Light.Peers)
+	fmt.Printf("Light Sync Interval: %v seconds\n", config.Light.SyncInterval)
+	fmt.Println()
 
 	fmt.Println("----end-of-file----")
 	fmt.Println()
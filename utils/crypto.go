@@ -116,8 +116,12 @@ func KeyGenECDSA() (ecdsa.PrivateKey, PublicKey) {
 		logrus.WithFields(logrus.Fields{"error": err}).Errorln("failed to generate an ECDSA key pair.")
 	}
 
-	// Construct the public key by appending the Y coordinate bytes into the X coordinate slice
-	public := append(key.PublicKey.X.Bytes(), key.PublicKey.Y.Bytes()...)
+	// Construct the public key from the X and Y coordinate bytes, each padded to the
+	// size of the curve so that the key can be split in half into its coordinates
+	size := (curve.Params().BitSize + 7) / 8
+	public := make(PublicKey, 2*size)
+	key.PublicKey.X.FillBytes(public[:size])
+	key.PublicKey.Y.FillBytes(public[size:])
 
 	// Return private and public keys
 	return *key, public
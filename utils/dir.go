@@ -17,7 +17,7 @@ func CreateDirectory(dirpath string) {
 		err = os.MkdirAll(dirpath, 0755)
 		if err != nil {
 			// Log a fatal error
-			logrus.WithFields(logrus.Fields{"error": err}).Fatalln("failed to create directory: %v.", dirpath)
+			logrus.WithFields(logrus.Fields{"error": err, "path": dirpath}).Fatalln("failed to create directory.")
 		}
 	}
 }
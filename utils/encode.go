@@ -7,8 +7,10 @@ package utils
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/gob"
 	"encoding/hex"
+	"fmt"
 	"strconv"
 
 	"github.com/mr-tron/base58"
@@ -81,7 +83,31 @@ func Base58Decode(encodeddata []byte) []byte {
 	return decode
 }
 
-// A function that encodes and returns an int as its hex/byte representation
+// A function that encodes and returns an int as a fixed width (8 byte) big-endian integer.
+// Numeric values are stored with this encoding so that they sort in numeric order as keys.
+func IntEncode(number int) []byte {
+	// Create an 8 byte slice
+	dst := make([]byte, 8)
+	// Encode the number into the slice
+	binary.BigEndian.PutUint64(dst, uint64(number))
+	// Return the encoded value
+	return dst
+}
+
+// A function that decodes and returns an int from its fixed width (8 byte) big-endian encoding.
+// Returns an error if the value is not 8 bytes long.
+func IntDecode(src []byte) (int, error) {
+	// Check that the value is well formed
+	if len(src) != 8 {
+		return 0, fmt.Errorf("malformed integer of %v bytes", len(src))
+	}
+
+	// Decode and return the number
+	return int(binary.BigEndian.Uint64(src)), nil
+}
+
+// A function that encodes and returns an int as its hex/byte representation.
+// This is the legacy encoding of numeric values. Use IntEncode instead.
 func HexEncode(number int) []byte {
 	// Format the integer into a string
 	strint := strconv.FormatInt(int64(number), 10)
@@ -97,28 +123,25 @@ func HexEncode(number int) []byte {
 	return dst
 }
 
-// A function that decodes and returns an int as from its hex/byte representation
-func HexDecode(src []byte) int {
+// A function that decodes and returns an int as from its hex/byte representation.
+// Returns an error if the value is not a valid encoding. This is the legacy
+// encoding of numeric values and is used to migrate them. Use IntDecode instead.
+func HexDecode(src []byte) (int, error) {
 	// Create a null destination object with
 	// capacity for the decoded object
 	dst := make([]byte, hex.DecodedLen(len(src)))
 
 	// Decode the number from a hex
-	_, err := hex.Decode(dst, src)
-	if err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Errorln("failed to decode from hexadecimal.")
+	if _, err := hex.Decode(dst, src); err != nil {
+		return 0, fmt.Errorf("failed to decode from hexadecimal! error - %v", err)
 	}
 
-	// Convert the decoded integer bytes into a string
-	strint := string(dst)
-	// Parse the string into an integer
-	number, err := strconv.ParseInt(strint, 10, 0)
+	// Parse the decoded string into an integer
+	number, err := strconv.ParseInt(string(dst), 10, 0)
 	if err != nil {
-		// Log a fatal error
-		logrus.WithFields(logrus.Fields{"error": err}).Errorln("failed to parse integer from hexadecimal.")
+		return 0, fmt.Errorf("failed to parse integer from hexadecimal! error - %v", err)
 	}
 
 	// Return the integer
-	return int(number)
+	return int(number), nil
 }
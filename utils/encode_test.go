@@ -79,10 +79,69 @@ func Test_HexDecode(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		decoded := HexDecode(tt.input)
+		decoded, err := HexDecode(tt.input)
 
-		if decoded != tt.output {
-			t.Fatalf("HexDecode(%v) failed! expected: %v, got: %v", tt.input, tt.output, decoded)
+		if err != nil || decoded != tt.output {
+			t.Fatalf("HexDecode(%v) failed! expected: %v, got: %v (%v)", tt.input, tt.output, decoded, err)
+		}
+	}
+
+	// Corrupt values return an error instead of 0
+	for _, input := range [][]byte{{0x00, 0x05}, {51}, []byte("zz")} {
+		if _, err := HexDecode(input); err == nil {
+			t.Fatalf("HexDecode(%v) failed! expected an error", input)
+		}
+	}
+}
+
+func Test_IntEncode(t *testing.T) {
+	tests := []struct {
+		input  int
+		output []byte
+	}{
+		{0, []byte{0, 0, 0, 0, 0, 0, 0, 0}},
+		{1, []byte{0, 0, 0, 0, 0, 0, 0, 1}},
+		{4000, []byte{0, 0, 0, 0, 0, 0, 15, 160}},
+		{52235, []byte{0, 0, 0, 0, 0, 0, 204, 11}},
+	}
+
+	for _, tt := range tests {
+		encoded := IntEncode(tt.input)
+
+		if !bytes.Equal(encoded, tt.output) {
+			t.Fatalf("IntEncode(%v) failed! expected: %v, got: %v", tt.input, tt.output, encoded)
+		}
+	}
+
+	// Encoded values sort in numeric order
+	if bytes.Compare(IntEncode(9), IntEncode(10)) >= 0 {
+		t.Fatalf("IntEncode() failed! expected encoded values to sort in numeric order")
+	}
+}
+
+func Test_IntDecode(t *testing.T) {
+	tests := []struct {
+		input  []byte
+		output int
+	}{
+		{[]byte{0, 0, 0, 0, 0, 0, 0, 0}, 0},
+		{[]byte{0, 0, 0, 0, 0, 0, 0, 1}, 1},
+		{[]byte{0, 0, 0, 0, 0, 0, 15, 160}, 4000},
+		{[]byte{0, 0, 0, 0, 0, 0, 204, 11}, 52235},
+	}
+
+	for _, tt := range tests {
+		decoded, err := IntDecode(tt.input)
+
+		if err != nil || decoded != tt.output {
+			t.Fatalf("IntDecode(%v) failed! expected: %v, got: %v (%v)", tt.input, tt.output, decoded, err)
+		}
+	}
+
+	// Values that are not 8 bytes long return an error
+	for _, input := range [][]byte{{}, {51, 48}, make([]byte, 9)} {
+		if _, err := IntDecode(input); err == nil {
+			t.Fatalf("IntDecode(%v) failed! expected an error", input)
 		}
 	}
 }
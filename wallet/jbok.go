@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/manishmeganathan/weave/filter"
 	"github.com/manishmeganathan/weave/utils"
 	"github.com/sirupsen/logrus"
 )
@@ -163,3 +164,28 @@ func (jbok *JBOK) RemoveWallet(address string) {
 	// Save the JBOK to the file
 	jbok.Save()
 }
+
+// A method of JBOK that retrieves the public key hashes of all wallets in the JBOK.
+func (jbok *JBOK) GetPublicKeyHashes() [][]byte {
+	// Declare a slice of public key hashes
+	var hashes [][]byte
+
+	// Iterate over the wallets in the JBOK
+	for _, wallet := range jbok.Wallets {
+		// Add the hash of the wallet public key to the slice
+		hashes = append(hashes, utils.Hash160(wallet.PublicKey))
+	}
+
+	// Return the slice of public key hashes
+	return hashes
+}
+
+// A method of JBOK that checks if the compact filter of a block matches any wallet in the JBOK.
+// The outpoints of any known wallet outputs can be provided to also detect when they are spent.
+// A match means the block must be fetched when rescanning, otherwise it can be safely skipped.
+func (jbok *JBOK) MatchFilter(blockfilter *filter.Filter, blockhash utils.Hash, outpoints ...[]byte) bool {
+	// Accumulate the public key hashes and outpoints as filter items
+	items := append(jbok.GetPublicKeyHashes(), outpoints...)
+	// Check if the filter matches any of the items
+	return blockfilter.MatchAny(blockhash, items)
+}
@@ -0,0 +1,92 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/manishmeganathan/weave/filter"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A function that returns an in-memory JBOK with a number of new wallets (the jbok file is not written)
+func testjbok(count int) *JBOK {
+	jbok := &JBOK{Wallets: make(map[string]*Wallet)}
+	for i := 0; i < count; i++ {
+		wallet := NewWallet()
+		jbok.Wallets[wallet.GenerateAddress(byte(0x00)).String] = wallet
+	}
+
+	return jbok
+}
+
+// A function that returns the public key hashes of a number of wallets that are not in a JBOK
+func testforeignhashes(count int) [][]byte {
+	var hashes [][]byte
+	for i := 0; i < count; i++ {
+		hashes = append(hashes, utils.Hash160(NewWallet().PublicKey))
+	}
+
+	return hashes
+}
+
+func Test_GetPublicKeyHashes(t *testing.T) {
+	jbok := testjbok(3)
+
+	// Every wallet of the JBOK has its public key hash returned
+	hashes := jbok.GetPublicKeyHashes()
+	if len(hashes) != len(jbok.Wallets) {
+		t.Fatalf("GetPublicKeyHashes() failed! expected: %v hashes, got: %v", len(jbok.Wallets), len(hashes))
+	}
+
+	for address, wallet := range jbok.Wallets {
+		found := false
+		for _, hash := range hashes {
+			found = found || bytes.Equal(hash, utils.Hash160(wallet.PublicKey))
+		}
+
+		if !found {
+			t.Fatalf("GetPublicKeyHashes() failed! expected the hash of wallet %v", address)
+		}
+	}
+
+	// An empty JBOK has no public key hashes
+	if hashes := testjbok(0).GetPublicKeyHashes(); len(hashes) != 0 {
+		t.Fatalf("GetPublicKeyHashes() failed! expected: 0 hashes, got: %v", len(hashes))
+	}
+}
+
+func Test_MatchFilter(t *testing.T) {
+	jbok := testjbok(2)
+	blockhash := utils.Hash256([]byte("block"))
+	foreign := testforeignhashes(50)
+
+	// A block filter with the public key hash of a wallet in the JBOK is matched
+	var wallethash []byte
+	for _, wallet := range jbok.Wallets {
+		wallethash = utils.Hash160(wallet.PublicKey)
+		break
+	}
+	matching := filter.NewFilter(blockhash, append(append([][]byte{}, foreign...), wallethash))
+	if !jbok.MatchFilter(matching, blockhash) {
+		t.Fatalf("MatchFilter() failed! expected the filter with a wallet key to match")
+	}
+
+	// A block filter with only the public key hashes of other wallets is not matched
+	nonmatching := filter.NewFilter(blockhash, foreign)
+	if jbok.MatchFilter(nonmatching, blockhash) {
+		t.Fatalf("MatchFilter() failed! expected the filter without a wallet key to not match")
+	}
+
+	// A block filter that spends a provided outpoint of a wallet is matched
+	outpoint := []byte(fmt.Sprintf("%x:%d", blockhash, 0))
+	spending := filter.NewFilter(blockhash, append(append([][]byte{}, foreign...), outpoint))
+	if !jbok.MatchFilter(spending, blockhash, outpoint) || jbok.MatchFilter(spending, blockhash) {
+		t.Fatalf("MatchFilter() failed! expected the filter to match only with the spent outpoint")
+	}
+
+	// A filter is not matched with the hash of another block as its key
+	if jbok.MatchFilter(matching, utils.Hash256([]byte("other"))) {
+		t.Fatalf("MatchFilter() failed! expected the filter to not match with another block key")
+	}
+}
@@ -0,0 +1,141 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/manishmeganathan/weave/consensus"
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/merkle"
+	"github.com/manishmeganathan/weave/protos"
+	"github.com/manishmeganathan/weave/utils"
+)
+
+// A function that returns the protos message of a block
+func EncodeBlock(block *core.Block) *protos.Block {
+	return &protos.Block{Blockhash: block.BlockHash, Blockdata: block.Serialize()}
+}
+
+// A function that returns the protos message of a transaction
+func EncodeTxn(txn *core.Transaction) *protos.Txn {
+	return &protos.Txn{Txnhash: txn.ID, Txndata: txn.Serialize()}
+}
+
+// A function that returns an entity message for a block
+func BlockEntity(block *core.Block) *protos.Entity {
+	return &protos.Entity{Type: protos.Entitytype_BLOCK, Entity: &protos.Entity_Block{Block: EncodeBlock(block)}}
+}
+
+// A function that returns an entity message for a transaction
+func TxnEntity(txn *core.Transaction) *protos.Entity {
+	return &protos.Entity{Type: protos.Entitytype_TXN, Entity: &protos.Entity_Txn{Txn: EncodeTxn(txn)}}
+}
+
+// A function that decodes a block from its protos message. The data is received from peers,
+// so decoding errors are returned instead of being fatal. Returns an error if the block does
+// not have a proof of work header or if its hash does not match the hash of the message.
+func DecodeBlock(msg *protos.Block) (*core.Block, error) {
+	// Check that the message has block data
+	if msg == nil || len(msg.GetBlockdata()) == 0 {
+		return nil, fmt.Errorf("message has no block data")
+	}
+
+	// Register the gob library with the Consensus Header type
+	gob.Register(consensus.NewPOW())
+
+	// Decode the block data
+	block := core.NullBlock()
+	if err := gob.NewDecoder(bytes.NewReader(msg.GetBlockdata())).Decode(block); err != nil {
+		return nil, fmt.Errorf("failed to decode block! error - %v", err)
+	}
+
+	// Check that the block has a proof of work with a target
+	if pow, ok := block.BlockHeader.ConsensusHeader.(*consensus.POW); !ok || pow.Target == nil {
+		return nil, fmt.Errorf("block has no proof of work")
+	}
+
+	// Check that the hash of the message matches the block
+	if !bytes.Equal(block.BlockHash, msg.GetBlockhash()) {
+		return nil, fmt.Errorf("block hash does not match message")
+	}
+
+	return block, nil
+}
+
+// A function that decodes a transaction from its protos message. The data is received from
+// peers, so decoding errors are returned instead of being fatal. Returns an error if the hash
+// of the transaction does not match its ID or the hash of the message.
+func DecodeTxn(msg *protos.Txn) (*core.Transaction, error) {
+	// Check that the message has transaction data
+	if msg == nil || len(msg.GetTxndata()) == 0 {
+		return nil, fmt.Errorf("message has no transaction data")
+	}
+
+	// Decode the transaction data
+	txn := &core.Transaction{}
+	if err := gob.NewDecoder(bytes.NewReader(msg.GetTxndata())).Decode(txn); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction! error - %v", err)
+	}
+
+	// Check that the ID of the transaction is its hash
+	if !bytes.Equal(txn.ID, txn.GenerateHash()) || !bytes.Equal(txn.ID, msg.GetTxnhash()) {
+		return nil, fmt.Errorf("transaction hash does not match")
+	}
+
+	return txn, nil
+}
+
+// A function that decodes a header entry from its protos message. The data is received from
+// peers, so decoding errors are returned instead of being fatal. Returns an error if the header
+// does not have a proof of work or if its hash does not match the hash of the message.
+func DecodeHeader(msg *protos.Header) (*core.HeaderEntry, error) {
+	// Check that the message has header data
+	if msg == nil || len(msg.GetHeaderdata()) == 0 {
+		return nil, fmt.Errorf("message has no header data")
+	}
+
+	// Register the gob library with the Consensus Header type
+	gob.Register(consensus.NewPOW())
+
+	// Decode the header data
+	entry := core.NullHeaderEntry()
+	if err := gob.NewDecoder(bytes.NewReader(msg.GetHeaderdata())).Decode(entry); err != nil {
+		return nil, fmt.Errorf("failed to decode header! error - %v", err)
+	}
+
+	// Check that the header has a proof of work with a target
+	if pow, ok := entry.BlockHeader.ConsensusHeader.(*consensus.POW); !ok || pow.Target == nil {
+		return nil, fmt.Errorf("header has no proof of work")
+	}
+
+	// Check that the hash of the message matches the header
+	if !bytes.Equal(entry.BlockHash, msg.GetBlockhash()) {
+		return nil, fmt.Errorf("header hash does not match message")
+	}
+
+	return entry, nil
+}
+
+// A function that decodes the transaction, block hash and merkle proof of a proof response.
+// The data is received from peers, so decoding errors are returned instead of being fatal.
+func DecodeProof(msg *protos.ProofResponse) (*core.Transaction, utils.Hash, *merkle.MerkleProof, error) {
+	// Check that the message has proof data
+	if msg == nil || len(msg.GetProofdata()) == 0 {
+		return nil, nil, nil, fmt.Errorf("message has no proof data")
+	}
+
+	// Decode the transaction
+	txn, err := DecodeTxn(msg.GetTxn())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Decode the merkle proof
+	proof := &merkle.MerkleProof{}
+	if err := gob.NewDecoder(bytes.NewReader(msg.GetProofdata())).Decode(proof); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to decode proof! error - %v", err)
+	}
+
+	return txn, msg.GetBlockhash(), proof, nil
+}
@@ -0,0 +1,147 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/protos"
+	"google.golang.org/protobuf/proto"
+)
+
+// A set of constants that represent the gossip topics of the weave network
+const (
+	// Represents the topic that new blocks are published on
+	BlockTopic = "/weave/blocks/1.0.0"
+	// Represents the topic that new transactions are published on
+	TxnTopic = "/weave/txns/1.0.0"
+)
+
+// Represents the maximum number of blocks above the chain head that a block whose
+// priori block is not known can be at to be relayed. Such blocks can only be checked
+// against their header, so they are not relayed at arbitrary heights.
+const MaxRelayDistance = 10
+
+// Represents the result of validating a gossip message before it is relayed
+type Validation int
+
+// A set of constants that represent the results of validating a gossip message
+const (
+	// Represents a valid message that is delivered and relayed to other peers
+	ValidationAccept Validation = iota
+	// Represents an invalid message that is dropped and penalizes the peer that sent it
+	ValidationReject
+	// Represents a message that is dropped without penalizing the peer (such as duplicates)
+	ValidationIgnore
+)
+
+// A function that returns the encoded data of an entity to publish on a gossip topic
+func EncodeEntity(entity *protos.Entity) ([]byte, error) {
+	return proto.Marshal(entity)
+}
+
+// A function that decodes an entity of a type from the data of a gossip message
+func decodeEntity(data []byte, entitytype protos.Entitytype) (*protos.Entity, bool) {
+	entity := &protos.Entity{}
+	if err := proto.Unmarshal(data, entity); err != nil || entity.GetType() != entitytype {
+		return nil, false
+	}
+
+	return entity, true
+}
+
+// A method of Handler that validates a block message received on the block topic. The block
+// is decoded and its proof of work is checked, so that blocks with a bad proof of work are
+// rejected before they are relayed. A block that extends the chain head is fully validated
+// against the chain, which includes the spends and signatures of its transactions. The state
+// of the chain is only known at its head, so blocks that extend any other block on the chain
+// can never be connected and are ignored. Blocks whose priori block is not known can only be
+// checked against their header and are relayed only if they are above the chain head by at
+// most MaxRelayDistance blocks. Blocks that are already known are ignored. The block of an
+// accepted message is returned and must be submitted with SubmitBlock after it is delivered.
+func (handler *Handler) ValidateBlockMessage(data []byte) (*core.Block, Validation) {
+	// Decode the block from the entity
+	entity, ok := decodeEntity(data, protos.Entitytype_BLOCK)
+	if !ok {
+		return nil, ValidationReject
+	}
+
+	block, err := DecodeBlock(entity.GetBlock())
+	if err != nil {
+		return nil, ValidationReject
+	}
+
+	// Acquire the lock on the chain
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	// Ignore blocks that are on the chain or held as orphans
+	if _, err := handler.chain.GetHeader(block.BlockHash); err == nil || handler.chain.Orphans.Contains(block.BlockHash) {
+		return nil, ValidationIgnore
+	}
+
+	// Check the header and its proof of work
+	if err := core.ValidateHeader(&block.BlockHeader, block.BlockHash, handler.chain.Params.Difficulty); err != nil {
+		return nil, ValidationReject
+	}
+
+	// Check the transactions and their signatures if the block extends the chain head
+	if bytes.Equal(block.Priori, handler.chain.ChainHead) {
+		if err := handler.chain.ValidateBlock(block); err != nil {
+			return nil, ValidationReject
+		}
+
+		return block, ValidationAccept
+	}
+
+	// Ignore blocks that extend a block on the chain other than the chain head
+	if _, err := handler.chain.GetHeader(block.Priori); err == nil {
+		return nil, ValidationIgnore
+	}
+
+	// Ignore blocks with an unknown priori block that are not close above the chain head
+	if block.BlockHeight <= handler.chain.ChainHeight || block.BlockHeight > handler.chain.ChainHeight+MaxRelayDistance {
+		return nil, ValidationIgnore
+	}
+
+	return block, ValidationAccept
+}
+
+// A method of Handler that validates a transaction message received on the transaction topic.
+// Validating a transaction is the same as admitting it to the pool, so the transaction is
+// submitted to the pool by the validator and only transactions that are added are relayed.
+// The pool checks the spends of the transaction and the signature of every input against the
+// key that locks the output it spends. Transactions that are already known or held as orphans
+// are ignored and transactions that fail the checks of the pool are rejected.
+func (handler *Handler) ValidateTxnMessage(data []byte, peer string) (*core.Transaction, Validation) {
+	// Decode the transaction from the entity
+	entity, ok := decodeEntity(data, protos.Entitytype_TXN)
+	if !ok {
+		return nil, ValidationReject
+	}
+
+	txn, err := DecodeTxn(entity.GetTxn())
+	if err != nil {
+		return nil, ValidationReject
+	}
+
+	// Ignore transactions if the node has no pool or they are already known
+	if handler.txpool == nil || handler.txpool.Orphans.Contains(txn.ID) {
+		return nil, ValidationIgnore
+	}
+	if _, ok := handler.txpool.Get(txn.ID); ok {
+		return nil, ValidationIgnore
+	}
+
+	// Submit the transaction to the pool
+	if _, err := handler.SubmitTxn(txn, peer); err != nil {
+		// Ignore transactions that are held as orphans until their parents are seen
+		if errors.Is(err, core.ErrOrphan) {
+			return nil, ValidationIgnore
+		}
+
+		return nil, ValidationReject
+	}
+
+	return txn, ValidationAccept
+}
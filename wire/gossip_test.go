@@ -0,0 +1,149 @@
+package wire
+
+import (
+	"testing"
+
+	"github.com/manishmeganathan/weave/consensus"
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+// A function that returns the encoded data of a gossip entity
+func testgossip(t *testing.T, entity interface{}) []byte {
+	var data []byte
+	var err error
+	switch entity := entity.(type) {
+	case *core.Block:
+		data, err = EncodeEntity(BlockEntity(entity))
+	case *core.Transaction:
+		data, err = EncodeEntity(TxnEntity(entity))
+	}
+
+	if err != nil {
+		t.Fatalf("EncodeEntity() failed! %v", err)
+	}
+
+	return data
+}
+
+func Test_ValidateBlockMessage(t *testing.T) {
+	chain, _ := testwalletchain(t)
+	handler := NewHandler(chain, nil, nil)
+
+	// A block with a valid proof of work is accepted
+	block := testblock(t, chain, nil)
+	if decoded, validation := handler.ValidateBlockMessage(testgossip(t, block)); validation != ValidationAccept || decoded == nil {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationAccept, validation)
+	}
+
+	// A block with a bad proof of work is rejected
+	bad := *block
+	pow := *block.BlockHeader.ConsensusHeader.(*consensus.POW)
+	bad.BlockHeader.ConsensusHeader = &pow
+	for pow.Nonce++; pow.Validate(&bad.BlockHeader); pow.Nonce++ {
+	}
+	bad.BlockHash = bad.BlockHeader.GenerateHash()
+	if _, validation := handler.ValidateBlockMessage(testgossip(t, &bad)); validation != ValidationReject {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationReject, validation)
+	}
+
+	// A block that extends the head with a valid proof of work but an invalid coinbase is rejected
+	chain.Params.Reward++
+	excessive := testblock(t, chain, nil)
+	chain.Params.Reward--
+	if _, validation := handler.ValidateBlockMessage(testgossip(t, excessive)); validation != ValidationReject {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationReject, validation)
+	}
+
+	// Corrupt data and entities of the wrong type are rejected
+	if _, validation := handler.ValidateBlockMessage([]byte{1, 2, 3}); validation != ValidationReject {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationReject, validation)
+	}
+	if _, validation := handler.ValidateBlockMessage(testgossip(t, block.TXList[0])); validation != ValidationReject {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationReject, validation)
+	}
+
+	// A block that is on the chain is ignored
+	sidebranch := testblock(t, chain, nil)
+	if _, err := handler.SubmitBlock(block, "peer"); err != nil {
+		t.Fatalf("SubmitBlock() failed! %v", err)
+	}
+	if _, validation := handler.ValidateBlockMessage(testgossip(t, block)); validation != ValidationIgnore {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationIgnore, validation)
+	}
+
+	// A block that extends a block on the chain other than the head is ignored
+	if _, validation := handler.ValidateBlockMessage(testgossip(t, sidebranch)); validation != ValidationIgnore {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationIgnore, validation)
+	}
+}
+
+func Test_ValidateOrphanBlockMessage(t *testing.T) {
+	chain, _ := testwalletchain(t)
+	handler := NewHandler(chain, nil, nil)
+
+	// Mine the blocks of another chain, whose priori blocks are not known to the chain
+	other, _ := testwalletchain(t)
+	address := *wallet.NewWallet().GenerateAddress(byte(0x00))
+	blocks := []*core.Block{}
+	for len(blocks) < chain.ChainHeight+MaxRelayDistance {
+		blocks = append(blocks, other.AddBlock([]*core.Transaction{core.NewCoinbaseTransaction(address, testparams.Reward)}, address))
+	}
+
+	// A block with an unknown priori block close above the chain head is accepted
+	nearest := blocks[chain.ChainHeight]
+	if _, validation := handler.ValidateBlockMessage(testgossip(t, nearest)); validation != ValidationAccept {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationAccept, validation)
+	}
+	farthest := blocks[len(blocks)-1]
+	if _, validation := handler.ValidateBlockMessage(testgossip(t, farthest)); validation != ValidationAccept || farthest.BlockHeight != chain.ChainHeight+MaxRelayDistance {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationAccept, validation)
+	}
+
+	// A block with an unknown priori block at the height of the chain head is ignored
+	competing := blocks[chain.ChainHeight-1]
+	if _, validation := handler.ValidateBlockMessage(testgossip(t, competing)); validation != ValidationIgnore {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationIgnore, validation)
+	}
+
+	// A block with an unknown priori block too far above the chain head is ignored
+	distant := other.AddBlock([]*core.Transaction{core.NewCoinbaseTransaction(address, testparams.Reward)}, address)
+	if _, validation := handler.ValidateBlockMessage(testgossip(t, distant)); validation != ValidationIgnore {
+		t.Fatalf("ValidateBlockMessage() failed! expected: %v, got: %v", ValidationIgnore, validation)
+	}
+}
+
+func Test_ValidateTxnMessage(t *testing.T) {
+	chain, w := testwalletchain(t)
+	txpool := core.NewTxPool(chain, core.DefaultTxPoolSize)
+	handler := NewHandler(chain, txpool, nil)
+	genesis, _ := chain.GetBlock(chain.ChainHead)
+
+	// A valid transaction is accepted and added to the pool
+	parent := testspend(w, genesis.TXList[0].ID, 20)
+	if _, validation := handler.ValidateTxnMessage(testgossip(t, parent), "peer"); validation != ValidationAccept || txpool.Count() != 1 {
+		t.Fatalf("ValidateTxnMessage() failed! expected: %v, got: %v", ValidationAccept, validation)
+	}
+
+	// A known transaction is ignored
+	if _, validation := handler.ValidateTxnMessage(testgossip(t, parent), "peer"); validation != ValidationIgnore {
+		t.Fatalf("ValidateTxnMessage() failed! expected: %v, got: %v", ValidationIgnore, validation)
+	}
+
+	// A transaction with missing parents is held as an orphan and ignored
+	orphan := testspend(w, []byte{1, 2, 3}, 10)
+	if _, validation := handler.ValidateTxnMessage(testgossip(t, orphan), "peer"); validation != ValidationIgnore || !txpool.Orphans.Contains(orphan.ID) {
+		t.Fatalf("ValidateTxnMessage() failed! expected: %v, got: %v", ValidationIgnore, validation)
+	}
+
+	// A transaction with the wrong key for its input is rejected
+	wrongkey := testspend(wallet.NewWallet(), parent.ID, 10)
+	if _, validation := handler.ValidateTxnMessage(testgossip(t, wrongkey), "peer"); validation != ValidationReject {
+		t.Fatalf("ValidateTxnMessage() failed! expected: %v, got: %v", ValidationReject, validation)
+	}
+
+	// Corrupt data is rejected
+	if _, validation := handler.ValidateTxnMessage([]byte{1, 2, 3}, "peer"); validation != ValidationReject {
+		t.Fatalf("ValidateTxnMessage() failed! expected: %v, got: %v", ValidationReject, validation)
+	}
+}
@@ -0,0 +1,459 @@
+package wire
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/protos"
+	"github.com/manishmeganathan/weave/utils"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// A set of constants that represent the limits of the responses of a Handler
+const (
+	// Represents the maximum number of blocks in an inventory response
+	MaxInventoryBlocks = 500
+	// Represents the maximum number of headers in a headers response
+	MaxHeaders = 2000
+	// Represents the maximum encoded size of the entities of an inventory response in bytes.
+	// Space is left within MaxMessageSize for the fields of the response and its message.
+	MaxInventorySize = MaxMessageSize - 64<<10
+)
+
+// A set of constants that represent the statuses of entities in a status response
+const (
+	// Represents an entity that is not known to the node
+	StatusUnknown = "unknown"
+	// Represents a transaction in the pool that has not been confirmed
+	StatusPending = "pending"
+	// Represents a transaction or block that is held as an orphan
+	StatusOrphan = "orphan"
+	// Represents a transaction or block on the chain
+	StatusConfirmed = "confirmed"
+)
+
+// A structure that represents the handler of the messages of the weave protocol.
+// Queries are answered from the chain and the pool and entities are submitted to them.
+// The chain is accessed while holding a lock that must be shared with every other user
+// of the chain in the process (such as a Miner), so that blocks are connected safely.
+type Handler struct {
+	// Represents the number of pool transactions included in a block (reported in the miner config)
+	PoolSize int
+
+	// Represents the chain that queries are answered from
+	chain *core.BlockChain
+	// Represents the pool that transactions are submitted to (can be nil)
+	txpool *core.TxPool
+	// Represents the synchronization lock for the chain
+	lock sync.Locker
+	// Represents the maximum encoded size of the entities of an inventory response
+	inventorysize int
+}
+
+// A constructor function that generates and returns a Handler for a chain and pool with
+// the lock of the chain. A nil lock creates a lock that is only used by the handler.
+func NewHandler(chain *core.BlockChain, txpool *core.TxPool, lock sync.Locker) *Handler {
+	// Create a lock if none is given
+	if lock == nil {
+		lock = &sync.Mutex{}
+	}
+
+	return &Handler{PoolSize: core.DefaultMaxBlockTxns, chain: chain, txpool: txpool, lock: lock, inventorysize: MaxInventorySize}
+}
+
+// A method of Handler that handles a message received from a peer. A query is answered with
+// a response message and an entity is submitted to the chain or pool without a response.
+// Returns an error for invalid messages, for unexpected responses and for failed queries.
+func (handler *Handler) HandleMessage(msg *protos.Message, peer string) (*protos.Message, error) {
+	switch msg.GetType() {
+	case protos.Message_QUERY:
+		// Answer the query
+		response, err := handler.HandleQuery(msg.GetQuery())
+		if err != nil {
+			return nil, err
+		}
+
+		return &protos.Message{Type: protos.Message_RESPONSE, Message: &protos.Message_Response{Response: response}}, nil
+
+	case protos.Message_ENTITY:
+		// Submit the entity
+		return nil, handler.HandleEntity(msg.GetEntity(), peer)
+
+	default:
+		return nil, fmt.Errorf("unexpected message of type %v", msg.GetType())
+	}
+}
+
+// A method of Handler that submits an entity received from a peer to the chain or pool
+func (handler *Handler) HandleEntity(entity *protos.Entity, peer string) error {
+	// Check that the entity has a body
+	if entity == nil {
+		return fmt.Errorf("message has no entity")
+	}
+
+	switch entity.GetType() {
+	case protos.Entitytype_BLOCK:
+		// Decode and submit the block
+		block, err := DecodeBlock(entity.GetBlock())
+		if err != nil {
+			return err
+		}
+
+		_, err = handler.SubmitBlock(block, peer)
+		return err
+
+	case protos.Entitytype_TXN:
+		// Decode and submit the transaction
+		txn, err := DecodeTxn(entity.GetTxn())
+		if err != nil {
+			return err
+		}
+
+		_, err = handler.SubmitTxn(txn, peer)
+		return err
+
+	case protos.Entitytype_MINERCONFIG:
+		// Miner configurations of peers are not used
+		return nil
+
+	default:
+		return fmt.Errorf("unknown entity of type %v", entity.GetType())
+	}
+}
+
+// A method of Handler that submits a block received from a peer to the chain. The pool is
+// revalidated after blocks are connected. Returns the blocks that were connected.
+func (handler *Handler) SubmitBlock(block *core.Block, peer string) ([]*core.Block, error) {
+	// Acquire the lock on the chain
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	// Submit the block to the chain
+	connected, err := handler.chain.SubmitBlock(block, peer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the transactions that were confirmed or invalidated by the blocks
+	if handler.txpool != nil {
+		handler.txpool.Revalidate()
+	}
+
+	return connected, nil
+}
+
+// A method of Handler that submits a transaction received from a peer to the pool.
+// Returns the transactions that were added to the pool.
+func (handler *Handler) SubmitTxn(txn *core.Transaction, peer string) ([]*core.Transaction, error) {
+	// Check that the node has a pool
+	if handler.txpool == nil {
+		return nil, fmt.Errorf("node has no transaction pool")
+	}
+
+	// Acquire the lock on the chain
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	return handler.txpool.Submit(txn, peer)
+}
+
+// A method of Handler that answers a query from the chain and the pool
+func (handler *Handler) HandleQuery(query *protos.Query) (*protos.Response, error) {
+	// Check that the query has a body
+	if query == nil || query.GetBody() == nil {
+		return nil, fmt.Errorf("message has no query")
+	}
+
+	// Acquire the lock on the chain
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	switch query.GetType() {
+	case protos.Query_TXN:
+		return handler.txnquery(query.GetTxn())
+	case protos.Query_BLOCK:
+		return handler.blockquery(query.GetBlock())
+	case protos.Query_STATE:
+		return handler.statequery(query.GetState())
+	case protos.Query_STATUS:
+		return handler.statusquery(query.GetStatus())
+	case protos.Query_INVENTORY:
+		return handler.inventoryquery(query.GetInventory())
+	case protos.Query_HEADERS:
+		return handler.headersquery(query.GetHeaders())
+	case protos.Query_PROOF:
+		return handler.proofquery(query.GetProof())
+	case protos.Query_FILTER:
+		return handler.filterquery(query.GetFilter())
+	default:
+		return nil, fmt.Errorf("unknown query of type %v", query.GetType())
+	}
+}
+
+// A method of Handler that answers a transaction query from the pool or the chain.
+// Transactions on the chain are looked up from its transaction index.
+func (handler *Handler) txnquery(query *protos.TxnQuery) (*protos.Response, error) {
+	// Check that the query body matches its type
+	if query == nil {
+		return nil, fmt.Errorf("query body does not match its type")
+	}
+
+	// Look for the transaction in the pool
+	var txn *core.Transaction
+	if entry, ok := handler.pooled(query.GetTxnhash()); ok {
+		txn = entry.Txn
+	} else {
+		// Look for the transaction on the chain
+		found, err := handler.chain.FindTransaction(query.GetTxnhash())
+		if err != nil {
+			return nil, err
+		}
+
+		txn = &found
+	}
+
+	return &protos.Response{
+		Type: protos.Response_TXN,
+		Body: &protos.Response_Txnresponse{Txnresponse: &protos.TxnResponse{Txn: EncodeTxn(txn)}},
+	}, nil
+}
+
+// A method of Handler that answers a block query from the chain
+func (handler *Handler) blockquery(query *protos.BlockQuery) (*protos.Response, error) {
+	// Check that the query body matches its type
+	if query == nil {
+		return nil, fmt.Errorf("query body does not match its type")
+	}
+
+	// Get the block from the chain
+	block, err := handler.chain.GetBlock(query.GetBlockhash())
+	if err != nil {
+		return nil, err
+	}
+
+	return &protos.Response{
+		Type: protos.Response_BLOCK,
+		Body: &protos.Response_Blockresponse{Blockresponse: &protos.BlockResponse{Block: EncodeBlock(block)}},
+	}, nil
+}
+
+// A method of Handler that answers a state query with the height and pruning of the chain
+func (handler *Handler) statequery(query *protos.StateQuery) (*protos.Response, error) {
+	// Check that the query body matches its type
+	if query == nil {
+		return nil, fmt.Errorf("query body does not match its type")
+	}
+
+	// Create the state of the chain
+	state := &protos.StateResponse{
+		Chainheight:  uint32(handler.chain.ChainHeight),
+		Pruned:       handler.chain.IsPruned(),
+		Prunedheight: uint32(handler.chain.PrunedHeight),
+	}
+
+	// Add the miner configuration if it was requested
+	if query.GetMinerconfig() {
+		state.Minerconfig = &protos.MinerConfig{
+			Poolsize:   uint32(handler.PoolSize),
+			Difficulty: uint32(handler.chain.Params.Difficulty),
+			Reward:     uint32(handler.chain.Params.Reward),
+		}
+	}
+
+	return &protos.Response{
+		Type: protos.Response_STATE,
+		Body: &protos.Response_Stateresponse{Stateresponse: state},
+	}, nil
+}
+
+// A method of Handler that answers a status query for a transaction or a block
+func (handler *Handler) statusquery(query *protos.StatusQuery) (*protos.Response, error) {
+	// Check that the query body matches its type
+	if query == nil {
+		return nil, fmt.Errorf("query body does not match its type")
+	}
+
+	hash := query.GetEntityhash()
+	status := StatusUnknown
+
+	switch query.GetType() {
+	case protos.Entitytype_TXN:
+		// Check the pool, the orphans of the pool and the transaction index of the chain
+		if _, ok := handler.pooled(hash); ok {
+			status = StatusPending
+		} else if handler.txpool != nil && handler.txpool.Orphans.Contains(hash) {
+			status = StatusOrphan
+		} else if _, err := handler.chain.GetTxnBlock(hash); err == nil {
+			status = StatusConfirmed
+		}
+
+	case protos.Entitytype_BLOCK:
+		// Check the chain and the orphan blocks for the block
+		if _, err := handler.chain.GetHeader(hash); err == nil {
+			status = StatusConfirmed
+		} else if handler.chain.Orphans.Contains(hash) {
+			status = StatusOrphan
+		}
+
+	default:
+		return nil, fmt.Errorf("no status for entity of type %v", query.GetType())
+	}
+
+	return &protos.Response{
+		Type: protos.Response_STATUS,
+		Body: &protos.Response_Statusresponse{Statusresponse: &protos.StatusResponse{Type: query.GetType(), Entityhash: hash, Status: status}},
+	}, nil
+}
+
+// A method of Handler that answers an inventory query with the blocks above the last block
+// of the requesting peer and the transactions of the pool if they were requested. A peer
+// that has no blocks receives the blocks from the genesis block. Upto MaxInventoryBlocks
+// blocks are included from the height after the last block, ordered from the highest block,
+// so that a peer that is far behind can request the inventory again. The lowest blocks and
+// then the pool transactions are included only while their encoded size fits within
+// MaxInventorySize, so that the response can be read by the peer.
+func (handler *Handler) inventoryquery(query *protos.InventoryQuery) (*protos.Response, error) {
+	// Check that the query body matches its type
+	if query == nil {
+		return nil, fmt.Errorf("query body does not match its type")
+	}
+
+	inventory := &protos.InventoryResponse{}
+	size := 0
+
+	// Determine the range of heights of the included blocks
+	start := int(query.GetLastblock()) + 1
+	if query.GetNoblocks() {
+		start = 0
+	}
+	end := min(start+MaxInventoryBlocks, handler.chain.ChainHeight)
+
+	if start < end {
+		// Check that the bodies of the blocks have not been pruned
+		if start < handler.chain.PrunedHeight {
+			if err := handler.chain.RequireFullChain("collect inventory"); err != nil {
+				return nil, err
+			}
+		}
+
+		// Iterate over the blocks from the chain head down to the start height
+		var blocks []*protos.Block
+		iter := core.NewIterator(handler.chain)
+		for height := handler.chain.ChainHeight - 1; height >= start; height-- {
+			if height >= end {
+				iter.NextHeader()
+				continue
+			}
+
+			blocks = append(blocks, EncodeBlock(iter.Next()))
+		}
+
+		// Include the lowest blocks that fit within the size limit
+		lowest := len(blocks)
+		for lowest > 0 && size+entrysize(blocks[lowest-1]) <= handler.inventorysize {
+			size += entrysize(blocks[lowest-1])
+			lowest--
+		}
+
+		inventory.Chainblocks = blocks[lowest:]
+	}
+
+	// Add the transactions of the pool that fit within the size limit if they were requested
+	if query.GetPooltxns() && handler.txpool != nil {
+		for _, entry := range handler.txpool.Transactions() {
+			txn := EncodeTxn(entry.Txn)
+			if size+entrysize(txn) > handler.inventorysize {
+				break
+			}
+
+			size += entrysize(txn)
+			inventory.Pooltxns = append(inventory.Pooltxns, txn)
+		}
+	}
+
+	return &protos.Response{
+		Type: protos.Response_INVENTORY,
+		Body: &protos.Response_Inventoryresponse{Inventoryresponse: inventory},
+	}, nil
+}
+
+// A function that returns the encoded size of a message as a repeated field of a response
+func entrysize(msg proto.Message) int {
+	return 1 + protowire.SizeBytes(proto.Size(msg))
+}
+
+// A method of Handler that answers a headers query with upto MaxHeaders headers of the chain
+func (handler *Handler) headersquery(query *protos.HeadersQuery) (*protos.Response, error) {
+	// Check that the query body matches its type
+	if query == nil {
+		return nil, fmt.Errorf("query body does not match its type")
+	}
+
+	// Collect the headers from the start height
+	count := min(int(query.GetCount()), MaxHeaders)
+	headers := &protos.HeadersResponse{}
+	for _, entry := range handler.chain.CollectHeaders(int(query.GetStartheight()), count) {
+		headers.Headers = append(headers.Headers, &protos.Header{Blockhash: entry.BlockHash, Headerdata: entry.Serialize()})
+	}
+
+	return &protos.Response{
+		Type: protos.Response_HEADERS,
+		Body: &protos.Response_Headersresponse{Headersresponse: headers},
+	}, nil
+}
+
+// A method of Handler that answers a proof query with the inclusion proof of a transaction
+func (handler *Handler) proofquery(query *protos.ProofQuery) (*protos.Response, error) {
+	// Check that the query body matches its type
+	if query == nil {
+		return nil, fmt.Errorf("query body does not match its type")
+	}
+
+	// Generate the inclusion proof of the transaction
+	txn, blockhash, proof, err := handler.chain.ProveTransaction(query.GetTxnhash())
+	if err != nil {
+		return nil, err
+	}
+
+	return &protos.Response{
+		Type: protos.Response_PROOF,
+		Body: &protos.Response_Proofresponse{Proofresponse: &protos.ProofResponse{
+			Txn:       EncodeTxn(txn),
+			Blockhash: blockhash,
+			Proofdata: utils.GobEncode(proof),
+		}},
+	}, nil
+}
+
+// A method of Handler that answers a filter query with the compact filter of a block
+func (handler *Handler) filterquery(query *protos.FilterQuery) (*protos.Response, error) {
+	// Check that the query body matches its type
+	if query == nil {
+		return nil, fmt.Errorf("query body does not match its type")
+	}
+
+	// Get the filter of the block
+	blockfilter, err := handler.chain.GetBlockFilter(query.GetBlockhash())
+	if err != nil {
+		return nil, err
+	}
+
+	return &protos.Response{
+		Type: protos.Response_FILTER,
+		Body: &protos.Response_Filterresponse{Filterresponse: &protos.FilterResponse{
+			Blockhash:  query.GetBlockhash(),
+			Filterdata: blockfilter.Serialize(),
+		}},
+	}, nil
+}
+
+// A method of Handler that returns a transaction from the pool if the node has a pool
+func (handler *Handler) pooled(txid utils.Hash) (*core.PoolTxn, bool) {
+	if handler.txpool == nil {
+		return nil, false
+	}
+
+	return handler.txpool.Get(txid)
+}
@@ -0,0 +1,220 @@
+package wire
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/manishmeganathan/weave/core"
+	"github.com/manishmeganathan/weave/protos"
+	"github.com/manishmeganathan/weave/wallet"
+)
+
+// Represents the chain params used for tests (low difficulty for fast mining)
+var testparams = core.ChainParams{Difficulty: 8, Reward: 25}
+
+// A function that returns a new in-memory chain with a genesis reward for a wallet
+func testwalletchain(t *testing.T) (*core.BlockChain, *wallet.Wallet) {
+	w := wallet.NewWallet()
+	chain, err := core.NewBlockChainWithOptions(core.ChainOptions{InMemory: true, Params: testparams, Coinbase: *w.GenerateAddress(byte(0x00))})
+	if err != nil {
+		t.Fatalf("NewBlockChainWithOptions() failed! %v", err)
+	}
+
+	t.Cleanup(chain.CloseBuckets)
+	return chain, w
+}
+
+// A function that returns a transaction signed by a wallet that spends the first output of a transaction
+func testspend(w *wallet.Wallet, txid []byte, value int) *core.Transaction {
+	address := *w.GenerateAddress(byte(0x00))
+	txn := &core.Transaction{Inputs: core.TXIList{{ID: txid, OutIndex: 0, PublicKey: w.PublicKey}}}
+	txn.Outputs = append(txn.Outputs, *core.NewTXO(value, address))
+
+	// Sign the input for the output of the wallet that it spends
+	if err := txn.Sign(w.PrivateKey, core.TXOList{*core.NewTXO(0, address)}); err != nil {
+		panic(err)
+	}
+
+	return txn
+}
+
+// A function that returns a mined block that extends the chain head
+func testblock(t *testing.T, chain *core.BlockChain, txpool *core.TxPool) *core.Block {
+	template, err := chain.NewBlockTemplate(txpool, *wallet.NewWallet().GenerateAddress(byte(0x00)), core.DefaultTemplateOptions())
+	if err != nil {
+		t.Fatalf("NewBlockTemplate() failed! %v", err)
+	}
+
+	block, ok := template.Mine(0, 1<<20)
+	if !ok {
+		t.Fatalf("Mine() failed! no nonce found")
+	}
+
+	return block
+}
+
+// A function that returns a query message
+func testquery(query *protos.Query) *protos.Message {
+	return &protos.Message{Type: protos.Message_QUERY, Message: &protos.Message_Query{Query: query}}
+}
+
+func Test_HandlerEntities(t *testing.T) {
+	chain, w := testwalletchain(t)
+	txpool := core.NewTxPool(chain, core.DefaultTxPoolSize)
+	handler := NewHandler(chain, txpool, nil)
+
+	// A transaction entity is added to the pool
+	genesis, _ := chain.GetBlock(chain.ChainHead)
+	coinbase := genesis.TXList[0]
+	txn := testspend(w, coinbase.ID, 20)
+
+	msg := &protos.Message{Type: protos.Message_ENTITY, Message: &protos.Message_Entity{Entity: TxnEntity(txn)}}
+	if response, err := handler.HandleMessage(msg, "peer"); err != nil || response != nil {
+		t.Fatalf("HandleMessage() failed! expected no response, got: %v %v", response, err)
+	}
+	if txpool.Count() != 1 {
+		t.Fatalf("HandleMessage() failed! expected the transaction in the pool")
+	}
+
+	// A block entity is connected to the chain and confirms the transaction
+	block := testblock(t, chain, txpool)
+	msg = &protos.Message{Type: protos.Message_ENTITY, Message: &protos.Message_Entity{Entity: BlockEntity(block)}}
+	if _, err := handler.HandleMessage(msg, "peer"); err != nil {
+		t.Fatalf("HandleMessage() failed! %v", err)
+	}
+	if !bytes.Equal(chain.ChainHead, block.BlockHash) || txpool.Count() != 0 {
+		t.Fatalf("HandleMessage() failed! expected the block to be connected and the pool to be empty")
+	}
+
+	// Entities with corrupt data or mismatched hashes are rejected
+	invalid := map[string]*protos.Entity{
+		"corrupt block": {Type: protos.Entitytype_BLOCK, Entity: &protos.Entity_Block{Block: &protos.Block{Blockhash: block.BlockHash, Blockdata: []byte{1, 2, 3}}}},
+		"block hash":    {Type: protos.Entitytype_BLOCK, Entity: &protos.Entity_Block{Block: &protos.Block{Blockhash: []byte{1}, Blockdata: block.Serialize()}}},
+		"corrupt txn":   {Type: protos.Entitytype_TXN, Entity: &protos.Entity_Txn{Txn: &protos.Txn{Txnhash: txn.ID, Txndata: []byte{1, 2, 3}}}},
+		"txn hash":      {Type: protos.Entitytype_TXN, Entity: &protos.Entity_Txn{Txn: &protos.Txn{Txnhash: []byte{1}, Txndata: txn.Serialize()}}},
+		"missing body":  {Type: protos.Entitytype_BLOCK},
+	}
+	for name, entity := range invalid {
+		if err := handler.HandleEntity(entity, "peer"); err == nil {
+			t.Fatalf("HandleEntity() failed! expected an error for a %v", name)
+		}
+	}
+
+	// Responses are not expected by the handler
+	if _, err := handler.HandleMessage(&protos.Message{Type: protos.Message_RESPONSE}, "peer"); err == nil {
+		t.Fatalf("HandleMessage() failed! expected an error for a response")
+	}
+}
+
+func Test_HandlerQueries(t *testing.T) {
+	chain, w := testwalletchain(t)
+	txpool := core.NewTxPool(chain, core.DefaultTxPoolSize)
+	handler := NewHandler(chain, txpool, nil)
+
+	// Extend the chain and pool a transaction
+	genesis, _ := chain.GetBlock(chain.ChainHead)
+	for i := 0; i < 3; i++ {
+		if _, err := handler.SubmitBlock(testblock(t, chain, nil), "peer"); err != nil {
+			t.Fatalf("SubmitBlock() failed! %v", err)
+		}
+	}
+
+	txn := testspend(w, genesis.TXList[0].ID, 20)
+	if _, err := handler.SubmitTxn(txn, "peer"); err != nil {
+		t.Fatalf("SubmitTxn() failed! %v", err)
+	}
+
+	// The state reports the height of the chain and the miner config
+	response, err := handler.HandleMessage(testquery(&protos.Query{Type: protos.Query_STATE, Body: &protos.Query_State{State: &protos.StateQuery{Minerconfig: true}}}), "peer")
+	if err != nil {
+		t.Fatalf("HandleMessage() failed! %v", err)
+	}
+	if state := response.GetResponse().GetStateresponse(); state.GetChainheight() != 4 || state.GetMinerconfig().GetDifficulty() != 8 {
+		t.Fatalf("HandleMessage() failed! unexpected state: %v", state)
+	}
+
+	// A block is returned by its hash
+	result, err := handler.HandleQuery(&protos.Query{Type: protos.Query_BLOCK, Body: &protos.Query_Block{Block: &protos.BlockQuery{Blockhash: chain.ChainHead}}})
+	if err != nil {
+		t.Fatalf("HandleQuery() failed! %v", err)
+	}
+	if block, err := DecodeBlock(result.GetBlockresponse().GetBlock()); err != nil || block.BlockHeight != 3 {
+		t.Fatalf("HandleQuery() failed! expected the chain head, got: %v", err)
+	}
+
+	// The inventory holds the blocks above the last block from the highest and the pool
+	result, err = handler.HandleQuery(&protos.Query{Type: protos.Query_INVENTORY, Body: &protos.Query_Inventory{Inventory: &protos.InventoryQuery{Lastblock: 1, Pooltxns: true}}})
+	if err != nil {
+		t.Fatalf("HandleQuery() failed! %v", err)
+	}
+	inventory := result.GetInventoryresponse()
+	if len(inventory.GetChainblocks()) != 2 || !bytes.Equal(inventory.GetChainblocks()[0].GetBlockhash(), chain.ChainHead) || len(inventory.GetPooltxns()) != 1 {
+		t.Fatalf("HandleQuery() failed! expected 2 blocks and 1 transaction, got: %v and %v", len(inventory.GetChainblocks()), len(inventory.GetPooltxns()))
+	}
+
+	// A peer with no blocks receives the inventory from the genesis block
+	result, err = handler.HandleQuery(&protos.Query{Type: protos.Query_INVENTORY, Body: &protos.Query_Inventory{Inventory: &protos.InventoryQuery{Noblocks: true}}})
+	if err != nil {
+		t.Fatalf("HandleQuery() failed! %v", err)
+	}
+	inventory = result.GetInventoryresponse()
+	blocks := inventory.GetChainblocks()
+	if lowest, err := DecodeBlock(blocks[len(blocks)-1]); len(blocks) != chain.ChainHeight || err != nil || lowest.BlockHeight != 0 {
+		t.Fatalf("HandleQuery() failed! expected %v blocks from the genesis block, got: %v", chain.ChainHeight, len(blocks))
+	}
+
+	// The inventory is limited by its encoded size and keeps the lowest blocks
+	genesisblock := blocks[len(blocks)-1]
+	handler.inventorysize = entrysize(genesisblock)
+	result, err = handler.HandleQuery(&protos.Query{Type: protos.Query_INVENTORY, Body: &protos.Query_Inventory{Inventory: &protos.InventoryQuery{Noblocks: true, Pooltxns: true}}})
+	if err != nil {
+		t.Fatalf("HandleQuery() failed! %v", err)
+	}
+	inventory = result.GetInventoryresponse()
+	if len(inventory.GetChainblocks()) != 1 || !bytes.Equal(inventory.GetChainblocks()[0].GetBlockhash(), genesisblock.GetBlockhash()) || len(inventory.GetPooltxns()) != 0 {
+		t.Fatalf("HandleQuery() failed! expected only the genesis block, got: %v blocks and %v transactions", len(inventory.GetChainblocks()), len(inventory.GetPooltxns()))
+	}
+	handler.inventorysize = MaxInventorySize
+
+	// Headers are returned in order of height
+	result, err = handler.HandleQuery(&protos.Query{Type: protos.Query_HEADERS, Body: &protos.Query_Headers{Headers: &protos.HeadersQuery{Startheight: 1, Count: 10}}})
+	if err != nil || len(result.GetHeadersresponse().GetHeaders()) != 3 {
+		t.Fatalf("HandleQuery() failed! expected 3 headers, got: %v", err)
+	}
+
+	// Transactions are found in the pool and the chain with their status
+	statuses := map[string]*protos.StatusQuery{
+		StatusPending:   {Type: protos.Entitytype_TXN, Entityhash: txn.ID},
+		StatusConfirmed: {Type: protos.Entitytype_TXN, Entityhash: genesis.TXList[0].ID},
+		StatusUnknown:   {Type: protos.Entitytype_BLOCK, Entityhash: []byte{1}},
+	}
+	for expected, query := range statuses {
+		result, err := handler.HandleQuery(&protos.Query{Type: protos.Query_STATUS, Body: &protos.Query_Status{Status: query}})
+		if err != nil || result.GetStatusresponse().GetStatus() != expected {
+			t.Fatalf("HandleQuery() failed! expected status: %v, got: %v %v", expected, result.GetStatusresponse().GetStatus(), err)
+		}
+	}
+
+	result, err = handler.HandleQuery(&protos.Query{Type: protos.Query_TXN, Body: &protos.Query_Txn{Txn: &protos.TxnQuery{Txnhash: txn.ID}}})
+	if err != nil || !bytes.Equal(result.GetTxnresponse().GetTxn().GetTxnhash(), txn.ID) {
+		t.Fatalf("HandleQuery() failed! expected the pool transaction, got: %v", err)
+	}
+
+	// Transactions on the chain can be proven
+	result, err = handler.HandleQuery(&protos.Query{Type: protos.Query_PROOF, Body: &protos.Query_Proof{Proof: &protos.ProofQuery{Txnhash: genesis.TXList[0].ID}}})
+	if err != nil || !bytes.Equal(result.GetProofresponse().GetBlockhash(), genesis.BlockHash) {
+		t.Fatalf("HandleQuery() failed! expected a proof against the genesis block, got: %v", err)
+	}
+
+	// Queries without a body or with a mismatched body are rejected
+	invalid := map[string]*protos.Query{
+		"missing body":    {Type: protos.Query_BLOCK},
+		"mismatched body": {Type: protos.Query_BLOCK, Body: &protos.Query_Txn{Txn: &protos.TxnQuery{}}},
+		"unknown block":   {Type: protos.Query_BLOCK, Body: &protos.Query_Block{Block: &protos.BlockQuery{Blockhash: []byte{1}}}},
+	}
+	for name, query := range invalid {
+		if _, err := handler.HandleQuery(query); err == nil {
+			t.Fatalf("HandleQuery() failed! expected an error for a %v", name)
+		}
+	}
+}
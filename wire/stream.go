@@ -0,0 +1,33 @@
+package wire
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/manishmeganathan/weave/protos"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// Represents the ID of the stream protocol that weave nodes exchange messages on
+const ProtocolID = "/weave/1.0.0"
+
+// Represents the maximum size of a framed message in bytes
+const MaxMessageSize = 32 << 20
+
+// A function that writes a message to a writer, framed with its varint encoded length
+func WriteMessage(w io.Writer, msg *protos.Message) error {
+	_, err := protodelim.MarshalTo(w, msg)
+	return err
+}
+
+// A function that reads a message that is framed with its varint encoded length from a
+// reader. Returns io.EOF if the reader ends before a message and an error for messages
+// that are larger than MaxMessageSize.
+func ReadMessage(r *bufio.Reader) (*protos.Message, error) {
+	msg := &protos.Message{}
+	if err := (protodelim.UnmarshalOptions{MaxSize: MaxMessageSize}).UnmarshalFrom(r, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
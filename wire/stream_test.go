@@ -0,0 +1,50 @@
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/manishmeganathan/weave/protos"
+	"google.golang.org/protobuf/proto"
+)
+
+func Test_MessageFraming(t *testing.T) {
+	// Write two messages to a buffer
+	messages := []*protos.Message{
+		{Type: protos.Message_QUERY, Peerid: "peer", Message: &protos.Message_Query{Query: &protos.Query{Type: protos.Query_STATE, Body: &protos.Query_State{State: &protos.StateQuery{Minerconfig: true}}}}},
+		{Type: protos.Message_ENTITY, Peerid: "peer", Message: &protos.Message_Entity{Entity: &protos.Entity{Type: protos.Entitytype_TXN, Entity: &protos.Entity_Txn{Txn: &protos.Txn{Txnhash: []byte{1}}}}}},
+	}
+
+	var buffer bytes.Buffer
+	for _, msg := range messages {
+		if err := WriteMessage(&buffer, msg); err != nil {
+			t.Fatalf("WriteMessage() failed! %v", err)
+		}
+	}
+
+	// Read the messages back in order
+	reader := bufio.NewReader(&buffer)
+	for _, expected := range messages {
+		msg, err := ReadMessage(reader)
+		if err != nil {
+			t.Fatalf("ReadMessage() failed! %v", err)
+		}
+		if !proto.Equal(msg, expected) {
+			t.Fatalf("ReadMessage() failed! expected: %v, got: %v", expected, msg)
+		}
+	}
+
+	// The end of the stream is reported as io.EOF
+	if _, err := ReadMessage(reader); !errors.Is(err, io.EOF) {
+		t.Fatalf("ReadMessage() failed! expected: %v, got: %v", io.EOF, err)
+	}
+
+	// A message larger than the maximum size is rejected
+	oversized := bufio.NewReader(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x0f}))
+	if _, err := ReadMessage(oversized); err == nil {
+		t.Fatalf("ReadMessage() failed! expected an error for an oversized message")
+	}
+}